@@ -1,6 +1,7 @@
 package asjard
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -22,6 +23,7 @@ import (
 	"github.com/asjard/asjard/core/server"
 	"github.com/asjard/asjard/core/server/handlers"
 	"github.com/asjard/asjard/core/trace"
+	"github.com/asjard/asjard/pkg/config/cli"
 	"github.com/asjard/asjard/utils"
 )
 
@@ -43,6 +45,10 @@ const (
  `
 )
 
+// ErrConfigPrinted is returned by Init and Start once the effective configuration
+// is printed with the --print-config command-line flag, the application should exit.
+var ErrConfigPrinted = errors.New("config printed")
+
 // Asjard manages the registered servers and their respective handlers.
 // It acts as the central controller for the framework's startup and shutdown phases.
 type Asjard struct {
@@ -176,6 +182,14 @@ func (asd *Asjard) Init() error {
 		return err
 	}
 
+	// Dump the effective configuration and stop if requested from the command line.
+	if cli.PrintConfigEnabled() {
+		if err := cli.PrintConfig(os.Stdout); err != nil {
+			return err
+		}
+		return ErrConfigPrinted
+	}
+
 	// Initialize observability (metrics and tracing).
 	if err := metrics.Init(); err != nil {
 		return err
@@ -60,6 +60,21 @@ const (
 var statusProtoDesc = (&status_pb.Status{}).ProtoReflect().Descriptor()
 var anyProtoDesc = (&any_pb.Any{}).ProtoReflect().Descriptor()
 
+// requestMediaTypes are the request bodies read by the rest server besides application/json.
+var requestMediaTypes = []string{
+	"application/x-protobuf",
+	"application/xml",
+	"application/msgpack",
+	"application/x-www-form-urlencoded",
+}
+
+// responseMediaTypes are the responses negotiated by the default writer of the rest server besides application/json.
+var responseMediaTypes = []string{
+	"application/x-protobuf",
+	"application/xml",
+	"application/msgpack",
+}
+
 // OpenAPIv3Generator holds internal state needed to generate an OpenAPIv3 document for a transcoded Protocol Buffer service.
 type OpenAPIv3Generator struct {
 	conf   Configuration
@@ -723,7 +738,7 @@ func (g *OpenAPIv3Generator) addPathsToDocumentV3(d *v3.Document, services []*pr
 			extHTTPs, ok := proto.GetExtension(method.Desc.Options(), httppb.E_Http).([]*httppb.Http)
 			if ok {
 				for index, rule := range extHTTPs {
-					httpOption := utils.ParseMethodHttpOption(service, rule)
+					httpOption := utils.ParseStreamHttpOption(service, method, rule)
 					summary := rule.Desc
 					if summary == "" {
 						summary = g.getCommentTitle(method.Comments)
@@ -747,6 +762,11 @@ func (g *OpenAPIv3Generator) addPathsToDocumentV3(d *v3.Document, services []*pr
 						if extOperation != nil {
 							proto.Merge(op, extOperation.(*v3.Operation))
 						}
+						if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
+							g.adaptStreamOperationV3(op, method)
+						} else {
+							g.addMediaTypesV3(op, httpOption.WriterName)
+						}
 
 						g.addOperationToDocumentV3(d, op, path2, httpOption.Method)
 					}
@@ -761,6 +781,73 @@ func (g *OpenAPIv3Generator) addPathsToDocumentV3(d *v3.Document, services []*pr
 	}
 }
 
+// addMediaTypesV3 lists the media types of the request body and the response
+// supported besides application/json, with the same schema.
+// The response media types are only negotiated by the default writer.
+func (g *OpenAPIv3Generator) addMediaTypesV3(op *v3.Operation, writerName string) {
+	if body := op.GetRequestBody().GetRequestBody(); body != nil {
+		body.Content.AdditionalProperties = appendMediaTypes(body.Content.AdditionalProperties, requestMediaTypes)
+	}
+	if writerName != "" && writerName != "default" {
+		return
+	}
+	for _, namedResponse := range op.GetResponses().GetResponseOrReference() {
+		if namedResponse.Name == "default" {
+			continue
+		}
+		if response := namedResponse.GetValue().GetResponse(); response.GetContent() != nil {
+			response.Content.AdditionalProperties = appendMediaTypes(response.Content.AdditionalProperties, responseMediaTypes)
+		}
+	}
+}
+
+// appendMediaTypes adds the media types with the schema of application/json.
+func appendMediaTypes(mediaTypes []*v3.NamedMediaType, names []string) []*v3.NamedMediaType {
+	for _, mediaType := range mediaTypes {
+		if mediaType.Name != "application/json" {
+			continue
+		}
+		for _, name := range names {
+			mediaTypes = append(mediaTypes, &v3.NamedMediaType{Name: name, Value: mediaType.Value})
+		}
+		return mediaTypes
+	}
+	return mediaTypes
+}
+
+// adaptStreamOperationV3 describes the Server-Sent Events or WebSocket endpoint of a streaming method.
+func (g *OpenAPIv3Generator) adaptStreamOperationV3(op *v3.Operation, method *protogen.Method) {
+	if len(op.GetResponses().GetResponseOrReference()) == 0 {
+		return
+	}
+	namedResponse := op.Responses.ResponseOrReference[0]
+	response := namedResponse.GetValue().GetResponse()
+	if response == nil {
+		return
+	}
+	if method.Desc.IsStreamingClient() {
+		// The messages of the client are WebSocket frames, only the path parameters are read.
+		parameters := make([]*v3.ParameterOrReference, 0, len(op.Parameters))
+		for _, parameter := range op.Parameters {
+			if parameter.GetParameter().GetIn() == "path" {
+				parameters = append(parameters, parameter)
+			}
+		}
+		op.Parameters = parameters
+		op.RequestBody = nil
+		namedResponse.Name = "101"
+		response.Description = fmt.Sprintf("Switching Protocols, %s messages are received and %s messages are sent as JSON text frames over WebSocket",
+			method.Input.Desc.FullName(), method.Output.Desc.FullName())
+		return
+	}
+	response.Description = fmt.Sprintf("Server-Sent Events, each data event is a JSON %s message", method.Output.Desc.FullName())
+	for _, mediaType := range response.GetContent().GetAdditionalProperties() {
+		if mediaType.Name == "application/json" {
+			mediaType.Name = "text/event-stream"
+		}
+	}
+}
+
 func (g *OpenAPIv3Generator) getCommentTitle(commentSet protogen.CommentSet) string {
 	if commentSet.Leading != "" {
 		lines := strings.Split(strings.TrimSuffix(strings.TrimSpace(commentSet.Leading.String()), "\n"), "\n")
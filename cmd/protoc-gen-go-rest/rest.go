@@ -25,6 +25,7 @@ import (
 
 	"github.com/asjard/asjard/cmd/protoc-gen-go-rest/openapi"
 	"github.com/asjard/asjard/cmd/protoc-gen-go-rest/utils"
+	"github.com/asjard/asjard/pkg/protobuf/authzpb"
 	"github.com/asjard/asjard/pkg/protobuf/httppb"
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/proto"
@@ -35,6 +36,7 @@ const (
 	contextPackage = protogen.GoImportPath("context")
 	restPackage    = protogen.GoImportPath("github.com/asjard/asjard/pkg/server/rest")
 	serverPackage  = protogen.GoImportPath("github.com/asjard/asjard/core/server")
+	authzPackage   = protogen.GoImportPath("github.com/asjard/asjard/pkg/protobuf/authzpb")
 	// FileDescriptorProto.package field number
 	fileDescriptorProtoPackageFieldNumber = 2
 	// FileDescriptorProto.syntax field number
@@ -122,7 +124,7 @@ func (g *RestGenerator) genService(service *protogen.Service) {
 	for _, method := range service.Methods {
 		hname := g.genServiceMethod(service, serverType, method)
 		handlerNames = append(handlerNames, hname)
-		if hname != "" {
+		if hname != "" || g.hasStreamHttpOption(method) {
 			genopenapi = true
 		}
 	}
@@ -182,11 +184,16 @@ func (g *RestGenerator) genServiceDesc(service *protogen.Service, serverType str
 				if option.WriterName != "" {
 					g.gen.P("WriterName:", strconv.Quote(option.WriterName), ",")
 				}
+				if option.MaxUploadSize != 0 {
+					g.gen.P("MaxUploadSize:", option.MaxUploadSize, ",")
+				}
+				g.genMethodAuthz(method)
 				g.gen.P("},")
 			}
 		}
 	}
 	g.gen.P("},")
+	fullPaths = append(fullPaths, g.genServiceStreams(service)...)
 	g.gen.P("}")
 	g.gen.P()
 
@@ -200,7 +207,90 @@ func (g *RestGenerator) genServiceDesc(service *protogen.Service, serverType str
 	g.gen.P()
 }
 
+// genServiceStreams generates the stream descriptors of the streaming methods,
+// served with the handlers generated by protoc-gen-go-grpc.
+func (g *RestGenerator) genServiceStreams(service *protogen.Service) [][2]string {
+	var fullPaths [][2]string
+	for _, method := range service.Methods {
+		if !g.hasStreamHttpOption(method) {
+			continue
+		}
+		if len(fullPaths) == 0 {
+			g.gen.P("Streams: []", restPackage.Ident("StreamDesc"), "{")
+		}
+		httpOptions := proto.GetExtension(method.Desc.Options(), httppb.E_Http).([]*httppb.Http)
+		for index, httpOption := range httpOptions {
+			g.gen.P("{")
+			g.gen.P("MethodName: ", strconv.Quote(string(method.Desc.Name())), ",")
+			methodName := g.getCommentTitle(method.Comments)
+			if methodName == "" {
+				methodName = string(method.Desc.Name())
+			}
+			g.gen.P("Name: ", strconv.Quote(methodName), ",")
+			g.gen.P("Desc: ", strconv.Quote(g.getCommentOneLine(method.Comments)), ",")
+			option := utils.ParseStreamHttpOption(service, method, httpOption)
+			fullPathName := fmt.Sprintf("%s_%s_RestPath", service.GoName, method.GoName)
+			if index != 0 {
+				fullPathName += fmt.Sprintf("_%d", index)
+			}
+			fullPaths = append(fullPaths, [2]string{fullPathName, option.GetPath()})
+			g.gen.P("Method:", strconv.Quote(option.Method), ",")
+			g.gen.P("Path:", fullPathName, ",")
+			g.gen.P("Handler: ", fmt.Sprintf("_%s_%s_Handler", service.GoName, method.GoName), ",")
+			if method.Desc.IsStreamingServer() {
+				g.gen.P("ServerStreams: true,")
+			}
+			if method.Desc.IsStreamingClient() {
+				g.gen.P("ClientStreams: true,")
+			}
+			g.genMethodAuthz(method)
+			g.gen.P("},")
+		}
+	}
+	if len(fullPaths) != 0 {
+		g.gen.P("},")
+	}
+	return fullPaths
+}
+
+// hasStreamHttpOption reports whether the method is a streaming method with http options.
+func (g *RestGenerator) hasStreamHttpOption(method *protogen.Method) bool {
+	if !method.Desc.IsStreamingClient() && !method.Desc.IsStreamingServer() {
+		return false
+	}
+	httpOptions, ok := proto.GetExtension(method.Desc.Options(), httppb.E_Http).([]*httppb.Http)
+	return ok && len(httpOptions) != 0
+}
+
+// genMethodAuthz generates the authorization option of a method.
+func (g *RestGenerator) genMethodAuthz(method *protogen.Method) {
+	authz, ok := proto.GetExtension(method.Desc.Options(), authzpb.E_Authz).(*authzpb.Authz)
+	if !ok || authz == nil || (len(authz.Permissions) == 0 && len(authz.Roles) == 0) {
+		return
+	}
+	g.gen.P("Authz: &", authzPackage.Ident("Authz"), "{")
+	if len(authz.Permissions) != 0 {
+		g.gen.P("Permissions: []string{", quoteStrings(authz.Permissions), "},")
+	}
+	if len(authz.Roles) != 0 {
+		g.gen.P("Roles: []string{", quoteStrings(authz.Roles), "},")
+	}
+	g.gen.P("},")
+}
+
+func quoteStrings(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, strconv.Quote(value))
+	}
+	return strings.Join(quoted, ", ")
+}
+
 func (g *RestGenerator) genServiceMethod(service *protogen.Service, serverType string, method *protogen.Method) string {
+	// The streaming methods are served with the handlers generated by protoc-gen-go-grpc.
+	if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
+		return ""
+	}
 	if httpOptions, ok := proto.GetExtension(method.Desc.Options(), httppb.E_Http).([]*httppb.Http); !ok || len(httpOptions) == 0 {
 		return ""
 	}
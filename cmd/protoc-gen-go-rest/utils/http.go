@@ -26,6 +26,8 @@ type HttpOption struct {
 	Group      string
 	Classify   string
 	WriterName string
+	// MaxUploadSize multipart/form-data上传文件大小限制
+	MaxUploadSize int64
 }
 
 // Path 请求路径
@@ -59,16 +61,18 @@ func parseServiceHttpOption(service *protogen.Service) *HttpOption {
 		option.Api = serviceHttpOption.Api
 		option.Version = serviceHttpOption.Version
 		option.WriterName = serviceHttpOption.WriterName
+		option.MaxUploadSize = serviceHttpOption.MaxUploadSize
 	}
 	return option
 }
 
 func parseMethodHttpOption(h *httppb.Http, serviceOption *HttpOption) *HttpOption {
 	option := &HttpOption{
-		Api:        h.Api,
-		Version:    h.Version,
-		Group:      h.Group,
-		WriterName: h.WriterName,
+		Api:           h.Api,
+		Version:       h.Version,
+		Group:         h.Group,
+		WriterName:    h.WriterName,
+		MaxUploadSize: h.MaxUploadSize,
 	}
 	if option.Api == "" {
 		option.Api = serviceOption.Api
@@ -82,6 +86,9 @@ func parseMethodHttpOption(h *httppb.Http, serviceOption *HttpOption) *HttpOptio
 	if option.WriterName == "" {
 		option.WriterName = serviceOption.WriterName
 	}
+	if option.MaxUploadSize == 0 {
+		option.MaxUploadSize = serviceOption.MaxUploadSize
+	}
 	switch h.GetPattern().(type) {
 	case *httppb.Http_Get:
 		option.Method = http.MethodGet
@@ -142,3 +149,14 @@ func ParseMethodHttpOption(service *protogen.Service, h *httppb.Http) *HttpOptio
 	}
 	return methodOption
 }
+
+// ParseStreamHttpOption 解析流式method http option
+// 客户端流和双向流通过WebSocket提供, 握手只支持GET请求, 消息通过WebSocket帧传递没有请求体
+func ParseStreamHttpOption(service *protogen.Service, method *protogen.Method, h *httppb.Http) *HttpOption {
+	option := ParseMethodHttpOption(service, h)
+	if method.Desc.IsStreamingClient() {
+		option.Method = http.MethodGet
+		option.Body = ""
+	}
+	return option
+}
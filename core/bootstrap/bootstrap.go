@@ -15,6 +15,8 @@ import (
 	_ "github.com/asjard/asjard/pkg/config/mem"
 	// init env configuration source
 	_ "github.com/asjard/asjard/pkg/config/env"
+	// init cli configuration source
+	_ "github.com/asjard/asjard/pkg/config/cli"
 )
 
 // Initiator defines the lifecycle contract for components within the framework.
@@ -40,6 +40,12 @@ func AddBalancer(name string, newPicker NewBalancerPicker) {
 	balancers[name] = newPicker
 }
 
+// serviceBalanceBuilder implements the gRPC balancer.Builder interface.
+// The strategy of every connection is selected from the configuration of its target service.
+type serviceBalanceBuilder struct {
+	name string
+}
+
 // BalanceBuilder implements the gRPC base.PickerBuilder interface.
 // It acts as a bridge between gRPC's balancer framework and the custom pickers.
 type BalanceBuilder struct {
@@ -48,33 +54,94 @@ type BalanceBuilder struct {
 
 var _ base.PickerBuilder = &BalanceBuilder{}
 
-// NewBalanceBuilder creates a gRPC balancer.Builder for the specified strategy name.
-// If the requested strategy is not found, it falls back to the default Round Robin strategy.
+// NewBalanceBuilder creates a gRPC balancer.Builder registered as balanceName.
+// The connections built by it use the strategy configured for their target service
+// and method, see GetServiceBalancer.
 func NewBalanceBuilder(balanceName string) balancer.Builder {
 	if balanceName == "" {
 		logger.Warn("loadbalance name is empty, set to default",
 			"default", DefaultBalanceRoundRobin)
 		balanceName = DefaultBalanceRoundRobin
 	}
+	return &serviceBalanceBuilder{name: balanceName}
+}
+
+// Build creates the balancer of a connection with the strategy of its target service.
+// The target format is asjard://{protocol}/{serviceName}.
+func (b *serviceBalanceBuilder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	balanceBuilder := &BalanceBuilder{
+		newPicker: GetServiceBalancer(opts.Target.URL.Host, opts.Target.Endpoint()),
+	}
+	return base.NewBalancerBuilder(b.name, balanceBuilder, base.Config{HealthCheck: true}).Build(cc, opts)
+}
+
+// Name returns the name the builder is registered as.
+func (b *serviceBalanceBuilder) Name() string {
+	return b.name
+}
+
+// GetServiceBalancer returns the picker factory of a service, read from
+// asjard.clients.{protocol}.{serviceName}.loadbalance, which falls back to the
+// protocol and the global configuration.
+// If methods of the service have their own strategy, the picker selects the
+// strategy by the full method of the request.
+// Requests matching a route of the service are sent to the instances of the route,
+// the outlier instances are ejected before routing.
+func GetServiceBalancer(protocol, serviceName string) NewBalancerPicker {
+	conf := serverConfig(protocol, serviceName)
+	newPicker := GetBalancer(conf.Loadbalance)
+	if len(conf.Methods) != 0 {
+		newPicker = newMethodPicker(conf, newPicker)
+	}
+	return newOutlierPicker(protocol, serviceName, newRoutePicker(protocol, serviceName, newPicker))
+}
 
-	balanceBuilder := &BalanceBuilder{}
-	exist := false
-	for pickerName, newPicker := range balancers {
-		if pickerName == balanceName {
-			balanceBuilder.newPicker = newPicker
-			exist = true
-			break
+// newMethodPicker returns the picker factory dispatching the requests to the strategy of their method.
+func newMethodPicker(conf Config, newPicker NewBalancerPicker) NewBalancerPicker {
+	newMethodPickers := make(map[string]NewBalancerPicker, len(conf.Methods))
+	for _, method := range conf.Methods {
+		if method.Name != "" && method.Loadbalance != "" {
+			newMethodPickers[method.Name] = GetBalancer(method.Loadbalance)
+		}
+	}
+	return func(scs map[balancer.SubConn]base.SubConnInfo) Picker {
+		picker := &methodPicker{
+			Picker:  newPicker(scs),
+			methods: make(map[string]Picker, len(newMethodPickers)),
 		}
+		for method, newMethodPicker := range newMethodPickers {
+			picker.methods[method] = newMethodPicker(scs)
+		}
+		return picker
 	}
+}
 
-	if !exist {
-		logger.Warn("loadbalance not found, set to default",
-			"loadbalance", balanceName,
-			"default", DefaultBalanceRoundRobin)
-		balanceBuilder.newPicker = NewRoundRobinPicker
+// methodPicker dispatches the requests to the picker of their method.
+type methodPicker struct {
+	// Picker is the picker of the methods without their own strategy.
+	Picker
+	methods map[string]Picker
+}
+
+// Pick selects a connection with the picker of the method.
+func (p *methodPicker) Pick(info balancer.PickInfo) (*PickResult, error) {
+	if picker, ok := p.methods[info.FullMethodName]; ok {
+		return picker.Pick(info)
 	}
+	return p.Picker.Pick(info)
+}
 
-	return base.NewBalancerBuilder(balanceName, balanceBuilder, base.Config{HealthCheck: true})
+// GetBalancer returns the picker factory registered with balanceName.
+// It is used by protocols which do not rely on gRPC's balancer framework (e.g., rest).
+// If the requested strategy is not found, it falls back to the default Round Robin strategy.
+func GetBalancer(balanceName string) NewBalancerPicker {
+	if newPicker, ok := balancers[balanceName]; ok {
+		return newPicker
+	}
+	logger.Warn("loadbalance not found, set to default",
+		"loadbalance", balanceName,
+		"default", DefaultBalanceRoundRobin)
+	return NewRoundRobinPicker
 }
 
 // Build is called by gRPC when the connectivity state changes.
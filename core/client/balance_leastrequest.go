@@ -0,0 +1,155 @@
+package client
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/asjard/asjard/core/runtime"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+const (
+	// LeastRequestName is the unique identifier for this balancer strategy.
+	LeastRequestName = "leastRequest"
+
+	// leastRequestDecay is the time constant of the latency moving average,
+	// a sample is worth 1/e of its weight after this duration.
+	leastRequestDecay = 10 * time.Second
+)
+
+// LeastRequestPicker sends requests to the instance with the lowest load.
+//
+// The load of an instance is its peak EWMA latency multiplied by its in-flight requests plus one.
+// Instances are compared on their in-flight requests only until the first request of both finishes.
+// The peak EWMA reacts immediately to a slower request and decays slowly to the faster ones,
+// slow instances are avoided as soon as they slow down.
+//
+// Two instances are chosen at random and the less loaded one is picked (power of two choices),
+// which avoids sending every request to the same instance between two updates of the statistics.
+type LeastRequestPicker struct {
+	*PickerBase
+	scs []*leastRequestSubConn
+}
+
+// leastRequestSubConn holds the statistics of a sub-connection, updated by the Done callback.
+type leastRequestSubConn struct {
+	*SubConn
+
+	mu       sync.Mutex
+	inflight int64
+	// latency is the peak EWMA of the request latency in nanoseconds.
+	latency float64
+	// lastUpdate is the time of the last latency sample.
+	lastUpdate time.Time
+}
+
+func init() {
+	AddBalancer(LeastRequestName, NewLeastRequestPicker)
+}
+
+// NewLeastRequestPicker creates a new instance of the LeastRequestPicker
+// with the reachable sub-connections.
+func NewLeastRequestPicker(scs map[balancer.SubConn]base.SubConnInfo) Picker {
+	picker := &LeastRequestPicker{
+		PickerBase: NewPickerBase(runtime.GetAPP()),
+		scs:        make([]*leastRequestSubConn, 0, len(scs)),
+	}
+	for conn, info := range scs {
+		sc := &SubConn{
+			Address: info.Address,
+			Conn:    conn,
+		}
+		if picker.CanReachable(sc) {
+			picker.scs = append(picker.scs, &leastRequestSubConn{SubConn: sc})
+		}
+	}
+	return picker
+}
+
+// Pick selects the less loaded of two random sub-connections,
+// the returned Done callback updates its statistics, Release only ends the in-flight request.
+func (l *LeastRequestPicker) Pick(info balancer.PickInfo) (*PickResult, error) {
+	var sc *leastRequestSubConn
+	switch n := len(l.scs); n {
+	case 0:
+		return nil, balancer.ErrNoSubConnAvailable
+	case 1:
+		sc = l.scs[0]
+	default:
+		i := rand.IntN(n)
+		j := rand.IntN(n - 1)
+		if j >= i {
+			j++
+		}
+		sc = l.scs[i]
+		if other := l.scs[j]; other.lessLoaded(sc) {
+			sc = other
+		}
+	}
+
+	start := sc.start()
+	return &PickResult{
+		SubConn: sc.SubConn,
+		Done: func(info balancer.DoneInfo) {
+			sc.done(start)
+		},
+		Release: sc.release,
+	}, nil
+}
+
+// Name returns the identifier of the balancer.
+func (l *LeastRequestPicker) Name() string {
+	return LeastRequestName
+}
+
+// lessLoaded reports whether a new request costs less on sc than on other.
+func (sc *leastRequestSubConn) lessLoaded(other *leastRequestSubConn) bool {
+	inflight, latency := sc.stats()
+	otherInflight, otherLatency := other.stats()
+	if latency == 0 || otherLatency == 0 {
+		return inflight < otherInflight
+	}
+	return latency*float64(inflight+1) < otherLatency*float64(otherInflight+1)
+}
+
+func (sc *leastRequestSubConn) stats() (int64, float64) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.inflight, sc.latency
+}
+
+// start records a new in-flight request.
+func (sc *leastRequestSubConn) start() time.Time {
+	sc.mu.Lock()
+	sc.inflight++
+	sc.mu.Unlock()
+	return time.Now()
+}
+
+// release ends an in-flight request which was never sent, its latency is not recorded.
+func (sc *leastRequestSubConn) release() {
+	sc.mu.Lock()
+	sc.inflight--
+	sc.mu.Unlock()
+}
+
+// done records the end of a request and its latency.
+func (sc *leastRequestSubConn) done(start time.Time) {
+	now := time.Now()
+	latency := float64(now.Sub(start))
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.inflight--
+	switch {
+	case sc.lastUpdate.IsZero(), latency > sc.latency:
+		// Peak: a slower request replaces the average immediately.
+		sc.latency = latency
+	default:
+		w := math.Exp(-float64(now.Sub(sc.lastUpdate)) / float64(leastRequestDecay))
+		sc.latency = sc.latency*w + latency*(1-w)
+	}
+	sc.lastUpdate = now
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/balancer"
+)
+
+func TestLeastRequestPicker(t *testing.T) {
+	picker := NewLeastRequestPicker(newWeightedSubConns("1", "1")).(*LeastRequestPicker)
+	info := balancer.PickInfo{Ctx: context.Background()}
+
+	// The in-flight request makes the first instance busier.
+	busy, err := picker.Pick(info)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		result, err := picker.Pick(info)
+		require.NoError(t, err)
+		require.NotEqual(t, busy.SubConn.Address.Addr, result.SubConn.Address.Addr)
+		result.Done(balancer.DoneInfo{})
+	}
+	busy.Done(balancer.DoneInfo{})
+	for _, sc := range picker.scs {
+		require.Zero(t, sc.inflight)
+	}
+
+	t.Run("latency", func(t *testing.T) {
+		slow, fast := picker.scs[0], picker.scs[1]
+		slow.done(slow.start().Add(-100 * time.Millisecond))
+		fast.done(fast.start())
+		for i := 0; i < 10; i++ {
+			result, err := picker.Pick(info)
+			require.NoError(t, err)
+			require.Equal(t, fast.Address.Addr, result.SubConn.Address.Addr)
+			result.Done(balancer.DoneInfo{})
+		}
+	})
+
+	t.Run("peakEWMA", func(t *testing.T) {
+		sc := &leastRequestSubConn{}
+		sc.done(sc.start().Add(-10 * time.Millisecond))
+		require.InDelta(t, float64(10*time.Millisecond), sc.latency, float64(time.Millisecond))
+		// A slower request replaces the average.
+		sc.done(sc.start().Add(-50 * time.Millisecond))
+		require.InDelta(t, float64(50*time.Millisecond), sc.latency, float64(time.Millisecond))
+		// A faster request only moves the average a little.
+		sc.lastUpdate = time.Now().Add(-time.Second)
+		sc.done(sc.start())
+		require.Greater(t, sc.latency, float64(40*time.Millisecond))
+	})
+
+	_, err = NewLeastRequestPicker(newWeightedSubConns()).Pick(info)
+	require.ErrorIs(t, err, balancer.ErrNoSubConnAvailable)
+}
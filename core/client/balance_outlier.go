@@ -0,0 +1,399 @@
+package client
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/metrics"
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+const (
+	// OutlierEjectionsMetricName is the counter of the ejections of the instances.
+	OutlierEjectionsMetricName = "client_outlier_ejections_total"
+
+	// outlierMinLatencyInstances is the number of instances required to compare their latencies.
+	outlierMinLatencyInstances = 3
+)
+
+// OutlierDetectionConfig is the configuration of the passive outlier detection.
+// Instances failing or much slower than the others are ejected from the
+// load balancing for a while, the ejection time doubles for every ejection.
+type OutlierDetectionConfig struct {
+	Enabled bool `json:"enabled"`
+	// ConsecutiveErrors ejects an instance after this number of consecutive server errors, 0 disables it.
+	ConsecutiveErrors int `json:"consecutiveErrors"`
+	// LatencyFactor ejects an instance whose average latency over an interval exceeds
+	// LatencyFactor times the median of the instances, 0 disables it.
+	LatencyFactor float64 `json:"latencyFactor"`
+	// MinRequests is the number of requests of an instance in an interval required to compare its latency.
+	MinRequests int `json:"minRequests"`
+	// Interval is the duration the latencies are compared over.
+	Interval utils.JSONDuration `json:"interval"`
+	// BaseEjectionTime is the duration of the first ejection of an instance.
+	BaseEjectionTime utils.JSONDuration `json:"baseEjectionTime"`
+	// MaxEjectionTime bounds the ejection time, an instance not ejected during
+	// this duration starts again from BaseEjectionTime.
+	MaxEjectionTime utils.JSONDuration `json:"maxEjectionTime"`
+	// MaxEjectionPercent is the maximum percentage of ejected instances,
+	// one instance can always be ejected unless it is the only one.
+	MaxEjectionPercent float64 `json:"maxEjectionPercent"`
+}
+
+var defaultOutlierDetectionConfig = OutlierDetectionConfig{
+	ConsecutiveErrors:  5,
+	LatencyFactor:      3,
+	MinRequests:        10,
+	Interval:           utils.JSONDuration{Duration: 10 * time.Second},
+	BaseEjectionTime:   utils.JSONDuration{Duration: 30 * time.Second},
+	MaxEjectionTime:    utils.JSONDuration{Duration: 300 * time.Second},
+	MaxEjectionPercent: 10,
+}
+
+// outlierConfig is the outlier detection configuration of a service,
+// shared by the detectors of all the connections to the service.
+type outlierConfig struct {
+	protocol    string
+	serviceName string
+	conf        atomic.Pointer[OutlierDetectionConfig]
+}
+
+// outlierDetector collects the statistics of the instances of a connection and ejects the outliers.
+type outlierDetector struct {
+	*outlierConfig
+	// version changes every time an instance is ejected.
+	version atomic.Uint64
+
+	mu            sync.Mutex
+	instances     map[string]*outlierInstance
+	intervalStart time.Time
+	ejections     *prometheus.CounterVec
+}
+
+// outlierInstance holds the statistics of an instance by address.
+type outlierInstance struct {
+	consecutiveErrors int
+	// requests and latency are counted over the current interval.
+	requests     int
+	latency      time.Duration
+	ejections    int
+	ejectedUntil time.Time
+}
+
+var (
+	outlierConfigs = make(map[string]*outlierConfig)
+	ocm            sync.Mutex
+)
+
+// getOutlierConfig returns the outlier detection configuration of a service,
+// read from asjard.clients.{protocol}.{serviceName}.outlierDetection, which falls back to the
+// protocol and the global configuration.
+func getOutlierConfig(protocol, serviceName string) *outlierConfig {
+	key := protocol + "://" + serviceName
+	ocm.Lock()
+	defer ocm.Unlock()
+	if c, ok := outlierConfigs[key]; ok {
+		return c
+	}
+	c := &outlierConfig{
+		protocol:    protocol,
+		serviceName: serviceName,
+	}
+	c.loadAndWatch()
+	outlierConfigs[key] = c
+	return c
+}
+
+func (c *outlierConfig) loadAndWatch() {
+	c.load()
+	config.AddPrefixListener(constant.ConfigClientPrefix, c.watch)
+}
+
+func (c *outlierConfig) load() {
+	conf := serverConfig(c.protocol, c.serviceName).OutlierDetection
+	c.conf.Store(&conf)
+}
+
+func (c *outlierConfig) watch(event *config.Event) {
+	c.load()
+}
+
+// newOutlierDetector returns the outlier detector of a connection to a service.
+// The statistics are kept by connection, so connections with different
+// options to the same service do not eject instances of each other.
+func newOutlierDetector(protocol, serviceName string) *outlierDetector {
+	return &outlierDetector{
+		outlierConfig: getOutlierConfig(protocol, serviceName),
+		instances:     make(map[string]*outlierInstance),
+		intervalStart: time.Now(),
+		ejections: metrics.RegisterCounter(OutlierEjectionsMetricName,
+			"The total number of ejections of outlier instances",
+			[]string{"protocol", "service", "instance", "reason"}),
+	}
+}
+
+// setInstances removes the statistics of the instances no longer resolved.
+func (d *outlierDetector) setInstances(addrs []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for addr := range d.instances {
+		if !slices.Contains(addrs, addr) {
+			delete(d.instances, addr)
+		}
+	}
+	for _, addr := range addrs {
+		if _, ok := d.instances[addr]; !ok {
+			d.instances[addr] = &outlierInstance{}
+		}
+	}
+}
+
+// ejected returns the addresses ejected at now, and the time the first of them returns.
+func (d *outlierDetector) ejected(now time.Time) (map[string]bool, time.Time) {
+	ejected := make(map[string]bool)
+	if !d.conf.Load().Enabled {
+		return ejected, time.Time{}
+	}
+	var expiry time.Time
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for addr, instance := range d.instances {
+		if instance.ejectedUntil.After(now) {
+			ejected[addr] = true
+			if expiry.IsZero() || instance.ejectedUntil.Before(expiry) {
+				expiry = instance.ejectedUntil
+			}
+		}
+	}
+	return ejected, expiry
+}
+
+// report records the result of a request sent to an instance.
+func (d *outlierDetector) report(addr string, err error, latency time.Duration) {
+	conf := d.conf.Load()
+	if !conf.Enabled {
+		return
+	}
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	instance, ok := d.instances[addr]
+	if !ok {
+		return
+	}
+	instance.requests++
+	instance.latency += latency
+	if isServerError(err) {
+		instance.consecutiveErrors++
+		if conf.ConsecutiveErrors > 0 && instance.consecutiveErrors >= conf.ConsecutiveErrors {
+			d.eject(conf, now, addr, instance, "consecutiveErrors")
+		}
+	} else {
+		instance.consecutiveErrors = 0
+	}
+
+	if now.Sub(d.intervalStart) >= conf.Interval.Duration {
+		d.ejectSlow(conf, now)
+		for _, instance := range d.instances {
+			instance.requests = 0
+			instance.latency = 0
+		}
+		d.intervalStart = now
+	}
+}
+
+// ejectSlow ejects the instances much slower than the median of the instances.
+func (d *outlierDetector) ejectSlow(conf *OutlierDetectionConfig, now time.Time) {
+	if conf.LatencyFactor <= 0 {
+		return
+	}
+	latencies := make(map[string]time.Duration)
+	for addr, instance := range d.instances {
+		if instance.requests >= max(conf.MinRequests, 1) && !instance.ejectedUntil.After(now) {
+			latencies[addr] = instance.latency / time.Duration(instance.requests)
+		}
+	}
+	if len(latencies) < outlierMinLatencyInstances {
+		return
+	}
+	sorted := make([]time.Duration, 0, len(latencies))
+	for _, latency := range latencies {
+		sorted = append(sorted, latency)
+	}
+	slices.Sort(sorted)
+	median := sorted[len(sorted)/2]
+	for addr, latency := range latencies {
+		if float64(latency) > conf.LatencyFactor*float64(median) {
+			d.eject(conf, now, addr, d.instances[addr], "latency")
+		}
+	}
+}
+
+// eject ejects an instance unless the maximum of ejected instances is reached.
+func (d *outlierDetector) eject(conf *OutlierDetectionConfig, now time.Time, addr string, instance *outlierInstance, reason string) {
+	if instance.ejectedUntil.After(now) {
+		return
+	}
+	ejected := 0
+	for _, other := range d.instances {
+		if other.ejectedUntil.After(now) {
+			ejected++
+		}
+	}
+	maxEjected := min(max(int(float64(len(d.instances))*conf.MaxEjectionPercent/100), 1), len(d.instances)-1)
+	if ejected >= maxEjected {
+		logger.Warn("outlier instance not ejected, max ejection reached",
+			"protocol", d.protocol, "service", d.serviceName, "instance", addr, "reason", reason)
+		return
+	}
+
+	// The ejection time starts over if the instance was healthy for long enough.
+	if !instance.ejectedUntil.IsZero() && now.Sub(instance.ejectedUntil) > conf.MaxEjectionTime.Duration {
+		instance.ejections = 0
+	}
+	instance.ejections++
+	ejectionTime := time.Duration(float64(conf.BaseEjectionTime.Duration) * math.Pow(2, float64(instance.ejections-1)))
+	if conf.MaxEjectionTime.Duration > 0 && ejectionTime > conf.MaxEjectionTime.Duration {
+		ejectionTime = conf.MaxEjectionTime.Duration
+	}
+	instance.ejectedUntil = now.Add(ejectionTime)
+	instance.consecutiveErrors = 0
+	d.version.Add(1)
+
+	logger.Warn("outlier instance ejected",
+		"protocol", d.protocol, "service", d.serviceName, "instance", addr,
+		"reason", reason, "ejections", instance.ejections, "duration", ejectionTime.String())
+	if d.ejections != nil {
+		d.ejections.With(map[string]string{
+			"protocol": d.protocol,
+			"service":  d.serviceName,
+			"instance": addr,
+			"reason":   reason,
+		}).Inc()
+	}
+}
+
+// isServerError reports whether err is a failure of the instance, such as a 5xx status.
+// Business errors keep the instance healthy.
+func isServerError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if _, ok := grpcstatus.FromError(err); !ok {
+		return true
+	}
+	return status.FromError(err).Status >= 500
+}
+
+// outlierPicker sends the requests to the instances not ejected by the outlier detector.
+// The picker of all the instances is built once and keeps its state, e.g. the
+// in-flight requests, the picks landing on an ejected instance are picked again.
+type outlierPicker struct {
+	// Picker is the picker of all the instances.
+	Picker
+	detector  *outlierDetector
+	newPicker NewBalancerPicker
+	scs       map[balancer.SubConn]base.SubConnInfo
+
+	// version is the version of the detector ejected is loaded for.
+	version uint64
+	// ejected is the addresses of the ejected instances.
+	ejected map[string]bool
+	// expiry is the time the first ejected instance returns.
+	expiry time.Time
+	// healthy is the picker of the instances not ejected, built when Picker
+	// keeps picking ejected instances, e.g. the ring hash picker for the keys of an ejected instance.
+	healthy Picker
+}
+
+// newOutlierPicker returns the picker factory ejecting the outliers of a service,
+// it is called once by connection and the pickers it builds share the detector of the connection.
+func newOutlierPicker(protocol, serviceName string, newPicker NewBalancerPicker) NewBalancerPicker {
+	d := newOutlierDetector(protocol, serviceName)
+	return func(scs map[balancer.SubConn]base.SubConnInfo) Picker {
+		addrs := make([]string, 0, len(scs))
+		for _, info := range scs {
+			addrs = append(addrs, info.Address.Addr)
+		}
+		d.setInstances(addrs)
+		p := &outlierPicker{
+			Picker:    newPicker(scs),
+			detector:  d,
+			newPicker: newPicker,
+			scs:       scs,
+		}
+		p.loadEjected()
+		return p
+	}
+}
+
+// Pick selects a connection among the instances not ejected,
+// the Done callback reports the result to the detector, Release reports nothing.
+// The picker is called under the lock of WrapPicker.
+func (p *outlierPicker) Pick(info balancer.PickInfo) (*PickResult, error) {
+	if p.detector.version.Load() != p.version || (!p.expiry.IsZero() && !time.Now().Before(p.expiry)) {
+		p.loadEjected()
+	}
+	result, err := p.pick(info)
+	if err != nil || !p.detector.conf.Load().Enabled {
+		return result, err
+	}
+	addr := result.SubConn.Address.Addr
+	done := result.Done
+	start := time.Now()
+	result.Done = func(info balancer.DoneInfo) {
+		p.detector.report(addr, info.Err, time.Since(start))
+		if done != nil {
+			done(info)
+		}
+	}
+	return result, nil
+}
+
+// pick picks again the picks of ejected instances, at most once per instance,
+// then falls back to the picker of the instances not ejected.
+func (p *outlierPicker) pick(info balancer.PickInfo) (*PickResult, error) {
+	if len(p.ejected) == 0 {
+		return p.Picker.Pick(info)
+	}
+	for range len(p.scs) {
+		result, err := p.Picker.Pick(info)
+		if err != nil || !p.ejected[result.SubConn.Address.Addr] {
+			return result, err
+		}
+		if result.Release != nil {
+			result.Release()
+		}
+	}
+	if p.healthy == nil {
+		scs := make(map[balancer.SubConn]base.SubConnInfo, len(p.scs))
+		for conn, info := range p.scs {
+			if !p.ejected[info.Address.Addr] {
+				scs[conn] = info
+			}
+		}
+		if len(scs) == 0 {
+			return p.Picker.Pick(info)
+		}
+		p.healthy = p.newPicker(scs)
+	}
+	return p.healthy.Pick(info)
+}
+
+// loadEjected loads the ejected instances from the detector.
+func (p *outlierPicker) loadEjected() {
+	p.version = p.detector.version.Load()
+	p.ejected, p.expiry = p.detector.ejected(time.Now())
+	p.healthy = nil
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/status"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// setOutlierDetection enables the outlier detection of a new protocol.
+func setOutlierDetection(t *testing.T, values map[string]any) string {
+	protocol := "outlier-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	prefix := "asjard.clients." + protocol + ".outlierDetection."
+	require.NoError(t, config.Set(prefix+"enabled", true))
+	for key, value := range values {
+		require.NoError(t, config.Set(prefix+key, value))
+	}
+	require.Eventually(t, func() bool {
+		return serverConfig(protocol, "svc").OutlierDetection.Enabled
+	}, 3*time.Second, 20*time.Millisecond)
+	return protocol
+}
+
+func TestOutlierPickerConsecutiveErrors(t *testing.T) {
+	protocol := setOutlierDetection(t, map[string]any{
+		"consecutiveErrors":  3,
+		"baseEjectionTime":   "200ms",
+		"maxEjectionPercent": 50,
+	})
+	picker := newOutlierPicker(protocol, "svc", NewRoundRobinPicker)(newWeightedSubConns("1", "1", "1"))
+	inner := picker.(*outlierPicker).Picker
+	pick := func(err error) string {
+		result, pickErr := picker.Pick(balancer.PickInfo{Ctx: context.Background()})
+		require.NoError(t, pickErr)
+		addr := result.SubConn.Address.Addr
+		if addr != "127.0.0.1" {
+			err = nil
+		}
+		result.Done(balancer.DoneInfo{Err: err})
+		return addr
+	}
+
+	// Business errors keep the instance healthy.
+	for i := 0; i < 30; i++ {
+		pick(status.Error(codes.InvalidArgument, "invalid"))
+	}
+	// The failing instance is ejected after three consecutive errors.
+	for i := 0; i < 9; i++ {
+		pick(status.Error(codes.Unavailable, "unavailable"))
+	}
+	for i := 0; i < 30; i++ {
+		require.NotEqual(t, "127.0.0.1", pick(nil))
+	}
+	// The picker of all the instances is kept.
+	require.Same(t, inner, picker.(*outlierPicker).Picker)
+
+	// It returns once the ejection expires.
+	time.Sleep(250 * time.Millisecond)
+	counts := make(map[string]int)
+	for i := 0; i < 30; i++ {
+		counts[pick(nil)]++
+	}
+	require.Len(t, counts, 3)
+
+	// The ejection time doubles.
+	for i := 0; i < 9; i++ {
+		pick(status.Error(codes.Unavailable, "unavailable"))
+	}
+	d := picker.(*outlierPicker).detector
+	d.mu.Lock()
+	instance := d.instances["127.0.0.1"]
+	require.Equal(t, 2, instance.ejections)
+	require.WithinDuration(t, time.Now().Add(400*time.Millisecond), instance.ejectedUntil, 100*time.Millisecond)
+	d.mu.Unlock()
+}
+
+func TestOutlierPickerRingHash(t *testing.T) {
+	protocol := setOutlierDetection(t, map[string]any{
+		"consecutiveErrors":  1,
+		"baseEjectionTime":   "1h",
+		"maxEjectionPercent": 50,
+	})
+	picker := newOutlierPicker(protocol, "svc", NewRingHashPicker)(newWeightedSubConns("1", "1", "1"))
+	key := GetRingHashConfig().Key
+	pick := func(hashKey string) *PickResult {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), key, hashKey)
+		result, err := picker.Pick(balancer.PickInfo{Ctx: ctx})
+		require.NoError(t, err)
+		return result
+	}
+	owners := make(map[string]string)
+	for i := 0; i < 300; i++ {
+		hashKey := strconv.Itoa(i)
+		result := pick(hashKey)
+		owners[hashKey] = result.SubConn.Address.Addr
+		result.Done(balancer.DoneInfo{})
+	}
+
+	// Eject the owner of the first key.
+	result := pick("0")
+	ejected := result.SubConn.Address.Addr
+	result.Done(balancer.DoneInfo{Err: status.Error(codes.Internal, "internal")})
+
+	// Only the keys of the ejected instance move.
+	for hashKey, owner := range owners {
+		result := pick(hashKey)
+		if owner == ejected {
+			require.NotEqual(t, ejected, result.SubConn.Address.Addr)
+		} else {
+			require.Equal(t, owner, result.SubConn.Address.Addr)
+		}
+		result.Done(balancer.DoneInfo{})
+	}
+}
+
+func TestOutlierPickerMaxEjection(t *testing.T) {
+	protocol := setOutlierDetection(t, map[string]any{
+		"consecutiveErrors":  1,
+		"maxEjectionPercent": 50,
+	})
+	picker := newOutlierPicker(protocol, "svc", NewRoundRobinPicker)(newWeightedSubConns("1", "1", "1"))
+	for i := 0; i < 30; i++ {
+		result, err := picker.Pick(balancer.PickInfo{Ctx: context.Background()})
+		require.NoError(t, err)
+		result.Done(balancer.DoneInfo{Err: status.Error(codes.Internal, "internal")})
+	}
+	ejected, _ := picker.(*outlierPicker).detector.ejected(time.Now())
+	require.Len(t, ejected, 1)
+
+	// The instances ejected by a connection stay available to the other connections.
+	other := newOutlierPicker(protocol, "svc", NewRoundRobinPicker)(newWeightedSubConns("1", "1", "1"))
+	ejected, _ = other.(*outlierPicker).detector.ejected(time.Now())
+	require.Empty(t, ejected)
+}
+
+func TestOutlierDetectorLatency(t *testing.T) {
+	protocol := setOutlierDetection(t, map[string]any{
+		"consecutiveErrors": 0,
+		"minRequests":       2,
+		"interval":          "1h",
+	})
+	d := newOutlierDetector(protocol, "svc")
+	d.setInstances([]string{"a", "b", "c", "d"})
+	for i := 0; i < 2; i++ {
+		d.report("a", nil, 10*time.Millisecond)
+		d.report("b", nil, 12*time.Millisecond)
+		d.report("c", nil, 11*time.Millisecond)
+		d.report("d", nil, 100*time.Millisecond)
+	}
+	ejected, _ := d.ejected(time.Now())
+	require.Empty(t, ejected)
+
+	// The latencies are compared at the end of the interval.
+	d.mu.Lock()
+	d.intervalStart = time.Now().Add(-2 * time.Hour)
+	d.mu.Unlock()
+	d.report("a", nil, 10*time.Millisecond)
+	ejected, _ = d.ejected(time.Now())
+	require.Equal(t, map[string]bool{"d": true}, ejected)
+}
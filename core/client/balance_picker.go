@@ -1,6 +1,8 @@
 package client
 
 import (
+	"context"
+	"strconv"
 	"sync"
 
 	"github.com/asjard/asjard/core/registry"
@@ -14,6 +16,14 @@ const (
 	// HeaderLoadBalancer records which load balancing strategy was used for this request.
 	// Useful for observability, logging, and debugging across services.
 	HeaderLoadBalancer = "x-request-balancer"
+
+	// MetaDataWeightKey is the instance metadata key of the instance weight.
+	MetaDataWeightKey = "weight"
+	// DefaultWeight is the weight of an instance without a valid weight.
+	DefaultWeight = 1
+
+	// maxRepicks is the number of picks made to avoid an instance already picked for a call.
+	maxRepicks = 3
 )
 
 // Picker defines the interface that all custom load balancers must implement.
@@ -32,6 +42,16 @@ type PickResult struct {
 	SubConn       *SubConn
 	RequestRegion string // Desired region for routing (may come from context or default)
 	RequestAz     string // Desired availability zone (may come from context or default)
+	// Done is called when the request finishes, pickers use it to collect
+	// per-connection statistics such as in-flight requests and latency.
+	// It may be nil.
+	Done func(info balancer.DoneInfo)
+	// Release is called instead of Done when the pick is abandoned before
+	// a request is sent, it only undoes the accounting of the pick, e.g. the
+	// in-flight requests, without reporting a result. It may be nil.
+	Release func()
+	// Route is the name of the route the request is sent on, see RouteConfig.
+	Route string
 }
 
 // WrapPicker wraps every custom Picker to provide:
@@ -81,6 +101,21 @@ func (p PickerBase) CanReachable(sc *SubConn) bool {
 	return false
 }
 
+// Weight returns the weight of the instance of the SubConn, read from the
+// "weight" key of the instance metadata.
+// It returns DefaultWeight if the weight is missing or invalid.
+func (p PickerBase) Weight(sc *SubConn) int {
+	instance, ok := sc.Address.Attributes.Value(AddressAttrKey{}).(*registry.Instance)
+	if !ok {
+		return DefaultWeight
+	}
+	weight, err := strconv.Atoi(instance.Service.Instance.MetaData[MetaDataWeightKey])
+	if err != nil || weight < 0 {
+		return DefaultWeight
+	}
+	return weight
+}
+
 // Shareable checks whether the instance allows traffic from other regions/clusters.
 // Used to enforce sharing policies in multi-tenant or staged rollout scenarios.
 func (p PickerBase) Shareable(sc *SubConn) bool {
@@ -107,8 +142,15 @@ func NewPicker(newBalancerPicker NewBalancerPicker, scs map[balancer.SubConn]bas
 // 2. Fills in default region/AZ if not specified
 // 3. Injects observability headers for full request context
 func (p *WrapPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	var picked *pickedInstances
+	if info.Ctx != nil {
+		picked, _ = info.Ctx.Value(pickedInstancesKey{}).(*pickedInstances)
+	}
 	p.mu.Lock()
 	result, err := p.picker.Pick(info)
+	if err == nil && picked != nil {
+		result = p.avoidPicked(info, picked, result)
+	}
 	p.mu.Unlock()
 	if err != nil {
 		return balancer.PickResult{}, err
@@ -132,24 +174,82 @@ func (p *WrapPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
 		requestAz = p.app.AZ
 	}
 
+	done := result.Done
+	if done == nil {
+		done = func(info balancer.DoneInfo) {}
+	}
+
+	md := metadata.New(map[string]string{
+		HeaderRequestApp:    p.app.App,
+		HeaderRequestSource: p.app.Instance.Name,
+		HeaderRequestDest:   destServiceName,
+		HeaderRequestRegion: requestRegion,
+		HeaderRequestAz:     requestAz,
+		HeaderLoadBalancer:  p.picker.Name(),
+	})
+	if result.Route != "" {
+		md.Set(HeaderRequestRoute, result.Route)
+	}
+
 	return balancer.PickResult{
 		SubConn: result.SubConn.Conn,
 
-		// Done callback is currently a no-op.
-		// Consider implementing it later for stats, health tracking, or dynamic weighting.
-		Done: func(info balancer.DoneInfo) {},
+		// Done reports the result of the request to the picker.
+		Done: done,
 
 		// Attach rich context to outgoing metadata for:
 		// - distributed tracing
 		// - logging & metrics
 		// - server-side routing decisions
-		Metadata: metadata.New(map[string]string{
-			HeaderRequestApp:    p.app.App,
-			HeaderRequestSource: p.app.Instance.Name,
-			HeaderRequestDest:   destServiceName,
-			HeaderRequestRegion: requestRegion,
-			HeaderRequestAz:     requestAz,
-			HeaderLoadBalancer:  p.picker.Name(),
-		}),
+		Metadata: md,
 	}, nil
 }
+
+// avoidPicked picks again while the picked instance was already picked for the call.
+// The last result is kept if every instance was already picked.
+func (p *WrapPicker) avoidPicked(info balancer.PickInfo, picked *pickedInstances, result *PickResult) *PickResult {
+	for i := 0; i < maxRepicks && picked.contains(result.SubConn.Address.Addr); i++ {
+		other, err := p.picker.Pick(info)
+		if err != nil {
+			break
+		}
+		// The abandoned pick never sends a request, it must not be reported as a success.
+		if result.Release != nil {
+			result.Release()
+		}
+		result = other
+	}
+	picked.add(result.SubConn.Address.Addr)
+	return result
+}
+
+type pickedInstancesKey struct{}
+
+// pickedInstances records the addresses picked for the attempts of a call.
+type pickedInstances struct {
+	mu    sync.Mutex
+	addrs map[string]struct{}
+}
+
+// WithPickedInstances returns a context recording the instances picked for the requests sent with it.
+// The requests avoid the instances already picked when another instance is available,
+// so that retried and hedged requests are sent to another instance.
+func WithPickedInstances(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(pickedInstancesKey{}).(*pickedInstances); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, pickedInstancesKey{}, &pickedInstances{addrs: make(map[string]struct{})})
+}
+
+func (p *pickedInstances) contains(addr string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.addrs[addr]
+	return ok
+}
+
+func (p *pickedInstances) add(addr string) {
+	p.mu.Lock()
+	p.addrs[addr] = struct{}{}
+	p.mu.Unlock()
+}
@@ -0,0 +1,206 @@
+package client
+
+import (
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/registry"
+	"github.com/asjard/asjard/core/runtime"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+const (
+	// RingHashName is the unique identifier for this balancer strategy.
+	RingHashName = "ringHash"
+	// HeaderRequestHashKey is the default metadata key the ring hash picker routes on.
+	HeaderRequestHashKey = "x-request-hash-key"
+)
+
+// RingHashConfig is the configuration of the ring hash picker.
+type RingHashConfig struct {
+	// Key is the request metadata key the requests are routed on, e.g., "x-user-id".
+	Key string `json:"key"`
+	// VirtualNodes is the number of points of the instances of the lowest weight on the ring,
+	// more points spread the keys more evenly.
+	VirtualNodes int `json:"virtualNodes"`
+	// MaxRingSize caps the number of points on the ring, the points
+	// of every instance are scaled down when the ring would be larger.
+	MaxRingSize int `json:"maxRingSize"`
+}
+
+var defaultRingHashConfig = RingHashConfig{
+	Key:          HeaderRequestHashKey,
+	VirtualNodes: 100,
+	MaxRingSize:  10000,
+}
+
+// RingHashPicker implements consistent hashing on a request metadata key.
+// Requests with the same key are sent to the same instance, so the caches
+// of the instances stay warm, and only the keys of an instance joining or
+// leaving move to another instance.
+//
+// Every instance is placed on the ring VirtualNodes times its weight
+// relative to the lowest weight, at most MaxRingSize points in total.
+// Requests without the key are sent in round-robin.
+type RingHashPicker struct {
+	*PickerBase
+	conf RingHashConfig
+	ring []ringHashNode
+	scs  []*SubConn
+	next uint32
+}
+
+type ringHashNode struct {
+	hash uint64
+	sc   *SubConn
+}
+
+func init() {
+	AddBalancer(RingHashName, NewRingHashPicker)
+}
+
+// GetRingHashConfig returns the configuration of the ring hash picker,
+// read from asjard.clients.balancers.ringHash.
+func GetRingHashConfig() RingHashConfig {
+	conf := defaultRingHashConfig
+	config.GetWithUnmarshal(constant.ConfigClientPrefix+".balancers."+RingHashName, &conf)
+	if conf.Key == "" {
+		conf.Key = HeaderRequestHashKey
+	}
+	if conf.VirtualNodes <= 0 {
+		conf.VirtualNodes = defaultRingHashConfig.VirtualNodes
+	}
+	if conf.MaxRingSize <= 0 {
+		conf.MaxRingSize = defaultRingHashConfig.MaxRingSize
+	}
+	return conf
+}
+
+// NewRingHashPicker creates a new instance of the RingHashPicker and builds
+// the ring of the reachable sub-connections.
+func NewRingHashPicker(scs map[balancer.SubConn]base.SubConnInfo) Picker {
+	picker := &RingHashPicker{
+		PickerBase: NewPickerBase(runtime.GetAPP()),
+		conf:       GetRingHashConfig(),
+		scs:        make([]*SubConn, 0, len(scs)),
+	}
+	weights := make([]int, 0, len(scs))
+	minWeight := 0
+	for conn, info := range scs {
+		sc := &SubConn{
+			Address: info.Address,
+			Conn:    conn,
+		}
+		if !picker.CanReachable(sc) {
+			continue
+		}
+		weight := picker.Weight(sc)
+		if weight <= 0 {
+			continue
+		}
+		picker.scs = append(picker.scs, sc)
+		weights = append(weights, weight)
+		if minWeight == 0 || weight < minWeight {
+			minWeight = weight
+		}
+	}
+	// The weights are normalized to the lowest weight, so large weights,
+	// e.g., 100 and 200, do not blow up the ring.
+	points := make([]int, len(weights))
+	total := 0
+	for i, weight := range weights {
+		points[i] = picker.conf.VirtualNodes * weight / minWeight
+		total += points[i]
+	}
+	if total > picker.conf.MaxRingSize {
+		for i := range points {
+			points[i] = max(1, points[i]*picker.conf.MaxRingSize/total)
+		}
+	}
+	picker.ring = make([]ringHashNode, 0, min(total, picker.conf.MaxRingSize)+len(points))
+	for i, sc := range picker.scs {
+		// The points only depend on the instance, the ring is the same in every process.
+		id := picker.nodeId(sc)
+		for j := 0; j < points[i]; j++ {
+			picker.ring = append(picker.ring, ringHashNode{
+				hash: ringHash(id + "#" + strconv.Itoa(j)),
+				sc:   sc,
+			})
+		}
+	}
+	sort.Slice(picker.ring, func(i, j int) bool {
+		return picker.ring[i].hash < picker.ring[j].hash
+	})
+	return picker
+}
+
+// Pick selects the first point of the ring after the hash of the request key.
+func (r *RingHashPicker) Pick(info balancer.PickInfo) (*PickResult, error) {
+	if len(r.ring) == 0 {
+		return nil, balancer.ErrNoSubConnAvailable
+	}
+	key, ok := r.requestKey(info)
+	if !ok {
+		next := atomic.AddUint32(&r.next, 1) - 1
+		return &PickResult{
+			SubConn: r.scs[next%uint32(len(r.scs))],
+		}, nil
+	}
+	hash := ringHash(key)
+	i, _ := slices.BinarySearchFunc(r.ring, hash, func(node ringHashNode, hash uint64) int {
+		switch {
+		case node.hash < hash:
+			return -1
+		case node.hash > hash:
+			return 1
+		}
+		return 0
+	})
+	if i == len(r.ring) {
+		i = 0
+	}
+	return &PickResult{
+		SubConn: r.ring[i].sc,
+	}, nil
+}
+
+// Name returns the identifier of the balancer.
+func (r *RingHashPicker) Name() string {
+	return RingHashName
+}
+
+// requestKey returns the value of the configured key in the request metadata.
+func (r *RingHashPicker) requestKey(info balancer.PickInfo) (string, bool) {
+	if info.Ctx == nil {
+		return "", false
+	}
+	return requestMetadata(info.Ctx, r.conf.Key)
+}
+
+// nodeId identifies the instance and address of a sub-connection.
+func (r *RingHashPicker) nodeId(sc *SubConn) string {
+	if instance, ok := sc.Address.Attributes.Value(AddressAttrKey{}).(*registry.Instance); ok {
+		return instance.Service.Instance.ID + "@" + sc.Address.Addr
+	}
+	return sc.Address.Addr
+}
+
+// ringHash hashes a key with FNV-1a, followed by the splitmix64 finalizer
+// to spread keys differing in their last bytes over the whole ring.
+func ringHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
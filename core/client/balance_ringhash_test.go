@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRingHashPicker(t *testing.T) {
+	require.NoError(t, config.Set("asjard.clients.balancers.ringHash.key", "x-user-id"))
+	require.Eventually(t, func() bool { return GetRingHashConfig().Key == "x-user-id" }, 3*time.Second, 20*time.Millisecond)
+
+	scs := newWeightedSubConns("1", "1", "1")
+	picker := NewRingHashPicker(scs)
+	pick := func(picker Picker, userId string) string {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-user-id", userId)
+		result, err := picker.Pick(balancer.PickInfo{Ctx: ctx})
+		require.NoError(t, err)
+		return result.SubConn.Address.Addr
+	}
+
+	owners := make(map[string]string)
+	counts := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		userId := strconv.Itoa(i)
+		owners[userId] = pick(picker, userId)
+		counts[owners[userId]]++
+		// Same key, same instance.
+		require.Equal(t, owners[userId], pick(picker, userId))
+	}
+	require.Len(t, counts, 3)
+	for addr, count := range counts {
+		require.InDelta(t, 1000, count, 300, addr)
+	}
+
+	// Removing an instance only moves its own keys.
+	remaining := make(map[balancer.SubConn]base.SubConnInfo)
+	var removed string
+	for conn, info := range scs {
+		if removed == "" {
+			removed = info.Address.Addr
+			continue
+		}
+		remaining[conn] = info
+	}
+	picker = NewRingHashPicker(remaining)
+	for userId, owner := range owners {
+		if owner != removed {
+			require.Equal(t, owner, pick(picker, userId))
+		}
+	}
+
+	// Requests without the key are still served.
+	result, err := picker.Pick(balancer.PickInfo{Ctx: context.Background()})
+	require.NoError(t, err)
+	require.NotEqual(t, removed, result.SubConn.Address.Addr)
+
+	// The weights are normalized to the lowest weight.
+	picker = NewRingHashPicker(newWeightedSubConns("100", "200"))
+	require.Len(t, picker.(*RingHashPicker).ring, 300)
+
+	// The ring is capped to maxRingSize.
+	picker = NewRingHashPicker(newWeightedSubConns("1", "1000"))
+	ring := picker.(*RingHashPicker).ring
+	require.LessOrEqual(t, len(ring), defaultRingHashConfig.MaxRingSize)
+	counts = make(map[string]int)
+	for _, node := range ring {
+		counts[node.sc.Address.Addr]++
+	}
+	require.Len(t, counts, 2)
+	require.Greater(t, counts["127.0.0.2"], 500*counts["127.0.0.1"])
+}
@@ -84,3 +84,75 @@ func TestRoundRobinPicker_NoAvailable(t *testing.T) {
 		t.Errorf("Expected ErrNoSubConnAvailable, got %v", err)
 	}
 }
+
+func TestWrapPickerDone(t *testing.T) {
+	picker := NewPicker(NewLeastRequestPicker, newWeightedSubConns("1"))
+	result, err := picker.Pick(balancer.PickInfo{Ctx: context.Background()})
+	if err != nil {
+		t.Fatalf("Pick failed: %v", err)
+	}
+	sc := picker.(*WrapPicker).picker.(*LeastRequestPicker).scs[0]
+	if sc.inflight != 1 {
+		t.Errorf("Expected 1 in-flight request, got %d", sc.inflight)
+	}
+	result.Done(balancer.DoneInfo{})
+	if sc.inflight != 0 {
+		t.Errorf("Expected no in-flight request, got %d", sc.inflight)
+	}
+
+	// Pickers without statistics get a no-op callback.
+	result, err = NewPicker(NewRoundRobinPicker, newWeightedSubConns("1")).Pick(balancer.PickInfo{Ctx: context.Background()})
+	if err != nil || result.Done == nil {
+		t.Fatalf("Expected a Done callback, err: %v", err)
+	}
+	result.Done(balancer.DoneInfo{})
+}
+
+func TestWrapPickerAvoidPicked(t *testing.T) {
+	picker := NewPicker(NewRoundRobinPicker, newWeightedSubConns("1", "1", "1"))
+	ctx := WithPickedInstances(context.Background())
+	if WithPickedInstances(ctx) != ctx {
+		t.Fatal("Expected the picked instances to be shared by the attempts")
+	}
+	picked := make(map[balancer.SubConn]bool)
+	for i := 0; i < 3; i++ {
+		result, err := picker.Pick(balancer.PickInfo{Ctx: ctx})
+		if err != nil {
+			t.Fatalf("Pick failed: %v", err)
+		}
+		if picked[result.SubConn] {
+			t.Fatalf("Expected attempt %d to avoid the instances already picked", i)
+		}
+		picked[result.SubConn] = true
+	}
+	// Every instance was picked, the request is still sent.
+	if _, err := picker.Pick(balancer.PickInfo{Ctx: ctx}); err != nil {
+		t.Fatalf("Pick failed: %v", err)
+	}
+}
+
+func TestWrapPickerReleaseAbandoned(t *testing.T) {
+	picker := NewPicker(NewLeastRequestPicker, newWeightedSubConns("1", "1")).(*WrapPicker)
+	scs := picker.picker.(*LeastRequestPicker).scs
+	idle, busy := scs[0], scs[1]
+	busy.start()
+	busy.start()
+	// The idle instance was already picked, the picker keeps picking it
+	// as the less loaded one, every abandoned pick is released.
+	ctx := WithPickedInstances(context.Background())
+	ctx.Value(pickedInstancesKey{}).(*pickedInstances).add(idle.Address.Addr)
+	result, err := picker.Pick(balancer.PickInfo{Ctx: ctx})
+	if err != nil {
+		t.Fatalf("Pick failed: %v", err)
+	}
+	if idle.inflight != 1 {
+		t.Errorf("Expected only the kept pick in flight, got %d", idle.inflight)
+	}
+	if !idle.lastUpdate.IsZero() {
+		t.Error("Expected the abandoned picks not to be recorded as requests")
+	}
+	result.Done(balancer.DoneInfo{})
+	if idle.inflight != 0 || idle.lastUpdate.IsZero() {
+		t.Errorf("Expected the request sent to be recorded, got %d in flight", idle.inflight)
+	}
+}
@@ -0,0 +1,290 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/registry"
+	"github.com/asjard/asjard/core/runtime"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// HeaderRequestRoute is the metadata key of the route a request was sent on.
+	// Services forward it so that the whole request chain stays on the same route,
+	// it is only trusted on requests sent by the instances of the same app.
+	HeaderRequestRoute = "x-request-route"
+)
+
+// RouteConfig routes the matching requests to a subset of the instances of a service,
+// e.g., canary or version routing.
+type RouteConfig struct {
+	// Name identifies the route, it is sent in the x-request-route metadata.
+	Name string `json:"name"`
+	// Headers is the request metadata the requests must have,
+	// the value "*" matches any value.
+	Headers map[string]string `json:"headers"`
+	// Percent is the percentage of the matching requests sent on the route,
+	// nil means 100 and 0 sends only the requests already on the route.
+	Percent *float64 `json:"percent"`
+	// Version is the version of the instances, "x" or "*" match any segment, e.g., "1.3.x".
+	Version string `json:"version"`
+	// MetaData is the metadata the instances must have.
+	MetaData map[string]string `json:"metadata"`
+
+	// percent is Percent bounded to [0, 100].
+	percent float64
+}
+
+// router holds the routes of a service, reloaded when the configuration changes.
+type router struct {
+	protocol    string
+	serviceName string
+	routes      atomic.Pointer[[]*RouteConfig]
+}
+
+var (
+	routers = make(map[string]*router)
+	rm      sync.Mutex
+)
+
+// getRouter returns the router of a service, read from asjard.clients.{protocol}.{serviceName}.routes,
+// which falls back to the protocol and the global routes.
+func getRouter(protocol, serviceName string) *router {
+	key := protocol + "://" + serviceName
+	rm.Lock()
+	defer rm.Unlock()
+	if r, ok := routers[key]; ok {
+		return r
+	}
+	r := &router{
+		protocol:    protocol,
+		serviceName: serviceName,
+	}
+	r.loadAndWatch()
+	routers[key] = r
+	return r
+}
+
+func (r *router) loadAndWatch() {
+	r.load()
+	config.AddPrefixListener(constant.ConfigClientPrefix, r.watch)
+}
+
+func (r *router) load() {
+	conf := serverConfig(r.protocol, r.serviceName)
+	routes := make([]*RouteConfig, 0, len(conf.Routes))
+	for _, route := range conf.Routes {
+		if route == nil || route.Name == "" {
+			continue
+		}
+		route.percent = 100
+		if route.Percent != nil {
+			route.percent = min(max(*route.Percent, 0), 100)
+		}
+		routes = append(routes, route)
+	}
+	r.routes.Store(&routes)
+}
+
+func (r *router) watch(event *config.Event) {
+	r.load()
+}
+
+// match returns the first route matching the request.
+// A request already sent on a route upstream stays on it.
+func (r *router) match(ctx context.Context, routes []*RouteConfig) *RouteConfig {
+	if len(routes) == 0 || ctx == nil {
+		return nil
+	}
+	if current, ok := upstreamRoute(ctx); ok {
+		for _, route := range routes {
+			if route.Name == current {
+				return route
+			}
+		}
+	}
+	for _, route := range routes {
+		if !matchHeaders(ctx, route.Headers) {
+			continue
+		}
+		if route.percent < 100 && rand.Float64()*100 >= route.percent {
+			continue
+		}
+		return route
+	}
+	return nil
+}
+
+// upstreamRoute returns the route a request was sent on upstream.
+// The route of the incoming metadata is only trusted if the request was sent by
+// an instance of the same app, so that external clients can not choose their route.
+func upstreamRoute(ctx context.Context) (string, bool) {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		if values := md.Get(HeaderRequestRoute); len(values) > 0 && values[0] != "" {
+			return values[0], true
+		}
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	if apps := md.Get(HeaderRequestApp); len(apps) == 0 || !TrustedRouteApp(apps[0]) {
+		return "", false
+	}
+	if values := md.Get(HeaderRequestRoute); len(values) > 0 && values[0] != "" {
+		return values[0], true
+	}
+	return "", false
+}
+
+// TrustedRouteApp reports whether the route of a request sent by app is trusted,
+// only the instances of the same app can choose the route of a request.
+func TrustedRouteApp(app string) bool {
+	return app == runtime.GetAPP().App
+}
+
+// matchHeaders reports whether the request metadata has all headers.
+func matchHeaders(ctx context.Context, headers map[string]string) bool {
+	for key, value := range headers {
+		v, ok := requestMetadata(ctx, strings.ToLower(key))
+		if !ok || (value != "*" && v != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchInstance reports whether the instance of a sub-connection is selected by the route.
+func (route *RouteConfig) matchInstance(sc *SubConn) bool {
+	instance, ok := sc.Address.Attributes.Value(AddressAttrKey{}).(*registry.Instance)
+	if !ok {
+		return false
+	}
+	if !matchVersion(route.Version, instance.Service.Instance.Version) {
+		return false
+	}
+	for key, value := range route.MetaData {
+		if v, ok := instance.Service.Instance.MetaData[key]; !ok || (value != "*" && v != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchVersion matches a version with a pattern such as "1.3.x",
+// segments missing in the pattern match any version.
+func matchVersion(pattern, version string) bool {
+	if pattern == "" {
+		return true
+	}
+	patterns := strings.Split(pattern, ".")
+	versions := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	for i, p := range patterns {
+		if p == "x" || p == "X" || p == "*" {
+			continue
+		}
+		if i >= len(versions) || strings.TrimPrefix(p, "v") != versions[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// routePicker sends the requests matching a route to the instances of the route,
+// and the other requests to every instance.
+// Requests are sent to every instance if no instance of their route is available.
+type routePicker struct {
+	// Picker is the picker of every instance.
+	Picker
+	router    *router
+	newPicker NewBalancerPicker
+	scs       map[balancer.SubConn]base.SubConnInfo
+
+	// routes is the version of the routes the pickers are built for.
+	routes *[]*RouteConfig
+	// pickers stores the picker of the instances of a route by route name,
+	// nil if the route has no instance.
+	pickers map[string]Picker
+}
+
+// newRoutePicker returns the picker factory routing the requests of a service.
+func newRoutePicker(protocol, serviceName string, newPicker NewBalancerPicker) NewBalancerPicker {
+	r := getRouter(protocol, serviceName)
+	return func(scs map[balancer.SubConn]base.SubConnInfo) Picker {
+		return &routePicker{
+			Picker:    newPicker(scs),
+			router:    r,
+			newPicker: newPicker,
+			scs:       scs,
+		}
+	}
+}
+
+// Pick selects a connection among the instances of the route of the request.
+// The picker is called under the lock of WrapPicker.
+func (p *routePicker) Pick(info balancer.PickInfo) (*PickResult, error) {
+	routes := p.router.routes.Load()
+	route := p.router.match(info.Ctx, *routes)
+	if route == nil {
+		return p.Picker.Pick(info)
+	}
+	if p.routes != routes {
+		p.routes = routes
+		p.pickers = make(map[string]Picker)
+	}
+	picker, ok := p.pickers[route.Name]
+	if !ok {
+		picker = p.newRoutePicker(route)
+		p.pickers[route.Name] = picker
+	}
+	if picker == nil {
+		logger.Debug("no instance of route, fall back to every instance",
+			"service", p.router.serviceName, "route", route.Name)
+		return p.Picker.Pick(info)
+	}
+	result, err := picker.Pick(info)
+	if err != nil {
+		return nil, err
+	}
+	result.Route = route.Name
+	return result, nil
+}
+
+// newRoutePicker creates the picker of the instances of a route.
+func (p *routePicker) newRoutePicker(route *RouteConfig) Picker {
+	scs := make(map[balancer.SubConn]base.SubConnInfo)
+	for conn, info := range p.scs {
+		if route.matchInstance(&SubConn{Address: info.Address, Conn: conn}) {
+			scs[conn] = info
+		}
+	}
+	if len(scs) == 0 {
+		return nil
+	}
+	return p.newPicker(scs)
+}
+
+// requestMetadata returns the first value of key in the outgoing metadata,
+// or in the incoming metadata if the request is forwarded.
+func requestMetadata(ctx context.Context, key string) (string, bool) {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 && values[0] != "" {
+			return values[0], true
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 && values[0] != "" {
+			return values[0], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/registry"
+	"github.com/asjard/asjard/core/runtime"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestMatchVersion(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		version string
+		match   bool
+	}{
+		{"", "1.0.0", true},
+		{"1.3.x", "1.3.5", true},
+		{"1.3.*", "v1.3.0", true},
+		{"1.3", "1.3.2", true},
+		{"1.3.x", "1.4.0", false},
+		{"1.3.2", "1.3.2", true},
+		{"1.3.2", "1.3", false},
+	} {
+		require.Equal(t, tc.match, matchVersion(tc.pattern, tc.version), tc)
+	}
+}
+
+func TestRoutePicker(t *testing.T) {
+	protocol := "route-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	prefix := "asjard.clients." + protocol + ".routes"
+	require.NoError(t, config.Set(prefix+"[0].name", "canary"))
+	require.NoError(t, config.Set(prefix+"[0].headers.x-canary", "true"))
+	require.NoError(t, config.Set(prefix+"[0].metadata.track", "canary"))
+	require.NoError(t, config.Set(prefix+"[1].name", "v13"))
+	require.NoError(t, config.Set(prefix+"[1].headers.x-user", "*"))
+	require.NoError(t, config.Set(prefix+"[1].percent", 50))
+	require.NoError(t, config.Set(prefix+"[1].version", "1.3.x"))
+	require.NoError(t, config.Set(prefix+"[2].name", "off"))
+	require.NoError(t, config.Set(prefix+"[2].headers.x-off", "*"))
+	require.NoError(t, config.Set(prefix+"[2].percent", 0))
+	require.NoError(t, config.Set(prefix+"[2].metadata.track", "canary"))
+	require.Eventually(t, func() bool {
+		return len(serverConfig(protocol, "svc").Routes) == 3
+	}, 3*time.Second, 20*time.Millisecond)
+
+	scs := newWeightedSubConns("1", "1", "1")
+	for _, info := range scs {
+		instance := info.Address.Attributes.Value(AddressAttrKey{}).(*registry.Instance)
+		switch info.Address.Addr {
+		case "127.0.0.1":
+			instance.Service.Instance.MetaData["track"] = "canary"
+		case "127.0.0.2":
+			instance.Service.Instance.Version = "1.3.1"
+		}
+	}
+	picker := newRoutePicker(protocol, "svc", NewRoundRobinPicker)(scs)
+	pick := func(ctx context.Context) *PickResult {
+		result, err := picker.Pick(balancer.PickInfo{Ctx: ctx})
+		require.NoError(t, err)
+		return result
+	}
+
+	// Canary requests go to the canary instance only.
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-canary", "true")
+	for i := 0; i < 10; i++ {
+		result := pick(ctx)
+		require.Equal(t, "127.0.0.1", result.SubConn.Address.Addr)
+		require.Equal(t, "canary", result.Route)
+	}
+
+	// A request routed upstream by the same app stays on its route.
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		HeaderRequestRoute, "canary",
+		HeaderRequestApp, runtime.GetAPP().App))
+	require.Equal(t, "127.0.0.1", pick(ctx).SubConn.Address.Addr)
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		HeaderRequestRoute, "off",
+		HeaderRequestApp, runtime.GetAPP().App))
+	require.Equal(t, "off", pick(ctx).Route)
+
+	// The route of external requests is ignored.
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(HeaderRequestRoute, "canary"))
+	for i := 0; i < 10; i++ {
+		require.Empty(t, pick(ctx).Route)
+	}
+
+	// A route with a zero percent only keeps the requests already on it.
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-off", "1")
+	for i := 0; i < 10; i++ {
+		require.Empty(t, pick(ctx).Route)
+	}
+
+	// Half of the user requests go to version 1.3.x.
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-user", "u1")
+	routed := 0
+	for i := 0; i < 1000; i++ {
+		if result := pick(ctx); result.Route == "v13" {
+			require.Equal(t, "127.0.0.2", result.SubConn.Address.Addr)
+			routed++
+		}
+	}
+	require.InDelta(t, 500, routed, 100)
+
+	// Other requests go to every instance.
+	counts := make(map[string]int)
+	for i := 0; i < 30; i++ {
+		result := pick(context.Background())
+		require.Empty(t, result.Route)
+		counts[result.SubConn.Address.Addr]++
+	}
+	require.Len(t, counts, 3)
+
+	// Routes are reloaded, requests fall back to every instance without a matching instance.
+	require.NoError(t, config.Set(prefix+"[0].metadata.track", "none"))
+	require.Eventually(t, func() bool {
+		return (*getRouter(protocol, "svc").routes.Load())[0].MetaData["track"] == "none"
+	}, 3*time.Second, 20*time.Millisecond)
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-canary", "true")
+	counts = make(map[string]int)
+	for i := 0; i < 30; i++ {
+		result := pick(ctx)
+		require.Empty(t, result.Route)
+		counts[result.SubConn.Address.Addr]++
+	}
+	require.Len(t, counts, 3)
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"github.com/asjard/asjard/core/runtime"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+const (
+	// WeightedRoundRobinName is the unique identifier for this balancer strategy.
+	WeightedRoundRobinName = "weightedRoundRobin"
+)
+
+// WeightedRoundRobinPicker implements the smooth weighted round-robin algorithm.
+// Instances receive traffic in proportion to the "weight" key of their metadata,
+// picks of the same instance are spread evenly instead of being sent in bursts.
+// Instances with a weight of 0 receive no traffic.
+type WeightedRoundRobinPicker struct {
+	*PickerBase
+	scs []*weightedSubConn
+	// total is the sum of the weights of scs.
+	total int
+}
+
+type weightedSubConn struct {
+	*SubConn
+	weight  int
+	current int
+}
+
+func init() {
+	AddBalancer(WeightedRoundRobinName, NewWeightedRoundRobinPicker)
+}
+
+// NewWeightedRoundRobinPicker creates a new instance of the WeightedRoundRobinPicker
+// with the reachable sub-connections.
+func NewWeightedRoundRobinPicker(scs map[balancer.SubConn]base.SubConnInfo) Picker {
+	picker := &WeightedRoundRobinPicker{
+		PickerBase: NewPickerBase(runtime.GetAPP()),
+		scs:        make([]*weightedSubConn, 0, len(scs)),
+	}
+	for conn, info := range scs {
+		sc := &SubConn{
+			Address: info.Address,
+			Conn:    conn,
+		}
+		if !picker.CanReachable(sc) {
+			continue
+		}
+		weight := picker.Weight(sc)
+		if weight == 0 {
+			continue
+		}
+		picker.scs = append(picker.scs, &weightedSubConn{SubConn: sc, weight: weight})
+		picker.total += weight
+	}
+	return picker
+}
+
+// Pick selects the sub-connection with the highest current weight.
+// The picker is called under the lock of WrapPicker.
+func (w *WeightedRoundRobinPicker) Pick(info balancer.PickInfo) (*PickResult, error) {
+	if len(w.scs) == 0 {
+		return nil, balancer.ErrNoSubConnAvailable
+	}
+	var best *weightedSubConn
+	for _, sc := range w.scs {
+		sc.current += sc.weight
+		if best == nil || sc.current > best.current {
+			best = sc
+		}
+	}
+	best.current -= w.total
+	return &PickResult{
+		SubConn: best.SubConn,
+	}, nil
+}
+
+// Name returns the identifier of the balancer.
+func (w *WeightedRoundRobinPicker) Name() string {
+	return WeightedRoundRobinName
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/asjard/asjard/core/registry"
+	"github.com/asjard/asjard/core/runtime"
+	"github.com/asjard/asjard/core/server"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+// newWeightedSubConns creates reachable sub-connections,
+// the instance of the i-th address 127.0.0.{i+1} has the weight weights[i].
+func newWeightedSubConns(weights ...string) map[balancer.SubConn]base.SubConnInfo {
+	app := runtime.GetAPP()
+	scs := make(map[balancer.SubConn]base.SubConnInfo, len(weights))
+	for i, weight := range weights {
+		addr := "127.0.0." + strconv.Itoa(i+1)
+		instance := &registry.Instance{Service: &server.Service{APP: runtime.APP{
+			Region: app.Region,
+			AZ:     app.AZ,
+			Instance: runtime.Instance{
+				ID:       addr,
+				MetaData: map[string]string{MetaDataWeightKey: weight},
+			},
+		}}}
+		scs[&mockSubConn{}] = base.SubConnInfo{Address: resolver.Address{
+			Addr:       addr,
+			Attributes: attributes.New(AddressAttrKey{}, instance).WithValue(ListenAddressKey{}, true),
+		}}
+	}
+	return scs
+}
+
+func TestWeightedRoundRobinPicker(t *testing.T) {
+	picker := NewWeightedRoundRobinPicker(newWeightedSubConns("3", "1", "0", "invalid"))
+
+	counts := make(map[string]int)
+	var picks []string
+	for i := 0; i < 10; i++ {
+		result, err := picker.Pick(balancer.PickInfo{Ctx: context.Background()})
+		require.NoError(t, err)
+		counts[result.SubConn.Address.Addr]++
+		picks = append(picks, result.SubConn.Address.Addr)
+	}
+	// weights 3:1:0:1(default)
+	require.Equal(t, map[string]int{"127.0.0.1": 6, "127.0.0.2": 2, "127.0.0.4": 2}, counts)
+	// Smooth: the heaviest instance is never picked three times in a row.
+	for i := 2; i < len(picks); i++ {
+		require.False(t, picks[i] == picks[i-1] && picks[i] == picks[i-2], picks)
+	}
+
+	_, err := NewWeightedRoundRobinPicker(newWeightedSubConns("0")).Pick(balancer.PickInfo{Ctx: context.Background()})
+	require.ErrorIs(t, err, balancer.ErrNoSubConnAvailable)
+}
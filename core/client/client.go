@@ -37,6 +37,9 @@ type ConnOptions struct {
 	// that wraps the RPC call to provide cross-cutting concerns like logging,
 	// tracing, and security.
 	Interceptor UnaryClientInterceptor
+	// StreamInterceptor is appended to the protocol's stream interceptor chain
+	// for streaming RPCs made through this connection.
+	StreamInterceptor StreamClientInterceptor
 }
 
 // ConnOption is a functional option pattern for configuring ConnOptions.
@@ -50,6 +53,8 @@ var (
 	newClients = make(map[string]NewClientFunc)
 	// clients stores initialized client instances.
 	clients = make(map[string]ClientInterface)
+	// clientInterceptors stores the interceptors of the clients, closed when the clients are initialized again.
+	clientInterceptors = make(map[string][]ClientInterceptor)
 )
 
 // AddClient registers a new protocol client implementation to the global registry.
@@ -62,15 +67,18 @@ func AddClient(protocol string, newClient NewClientFunc) {
 func Init() error {
 	for protocol, newClient := range newClients {
 		conf := GetConfigWithProtocol(protocol)
-		interceptor, err := getChainUnaryInterceptors(protocol, conf)
+		interceptor, streamInterceptor, interceptors, err := getChainInterceptors(protocol, conf)
 		if err != nil {
 			return err
 		}
+		closeInterceptors(clientInterceptors[protocol])
+		clientInterceptors[protocol] = interceptors
 		// Each protocol client is initialized with its own resolver and balancer builders.
 		clients[protocol] = newClient(&ClientOptions{
-			Resolver:    &ClientBuilder{},
-			Balancer:    NewBalanceBuilder(conf.Loadbalance),
-			Interceptor: interceptor,
+			Resolver:          &ClientBuilder{},
+			Balancer:          NewBalanceBuilder(conf.Loadbalance),
+			Interceptor:       interceptor,
+			StreamInterceptor: streamInterceptor,
 		})
 	}
 	return nil
@@ -119,7 +127,9 @@ func (c Client) connOptions(ops ...ConnOption) *ConnOptions {
 func (o ConnOptions) queryString() string {
 	v := make(url.Values)
 	v.Set("instanceID", o.InstanceID)
-	// Note: RegistryName is handled by the resolver but can be added here if needed.
+	if o.RegistryName != "" {
+		v.Set("registryName", o.RegistryName)
+	}
 	return v.Encode()
 }
 
@@ -131,6 +141,7 @@ func WithInstanceID(instanceID string) func(opts *ConnOptions) {
 }
 
 // WithRegistryName specifies a custom registry for service discovery.
+// It overrides asjard.clients.{protocol}.{serviceName}.registryName.
 func WithRegistryName(registryName string) func(opts *ConnOptions) {
 	return func(opts *ConnOptions) {
 		opts.RegistryName = registryName
@@ -142,3 +153,10 @@ func WithUnaryClientInterceptor(interceptor UnaryClientInterceptor) func(opts *C
 		opts.Interceptor = interceptor
 	}
 }
+
+// WithStreamClientInterceptor appends a stream interceptor to the connection's streaming RPCs.
+func WithStreamClientInterceptor(interceptor StreamClientInterceptor) func(opts *ConnOptions) {
+	return func(opts *ConnOptions) {
+		opts.StreamInterceptor = interceptor
+	}
+}
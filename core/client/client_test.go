@@ -49,6 +49,13 @@ func TestClient_Conn(t *testing.T) {
 			},
 			wantTarget: "asjard://mock-proto/test-service?instanceID=inst-123",
 		},
+		{
+			name: "Connection With Registry Name",
+			ops: []ConnOption{
+				WithRegistryName("etcd"),
+			},
+			wantTarget: "asjard://mock-proto/test-service?instanceID=&registryName=etcd",
+		},
 	}
 
 	for _, tt := range tests {
@@ -13,20 +13,41 @@ import (
 type Config struct {
 	// Loadbalance specifies the load balancing strategy name (e.g., "roundRobin", "localityRoundRobin").
 	Loadbalance string `json:"loadbalance"`
+	// RegistryName specifies the service discovery registry the services are discovered from,
+	// empty means every registry.
+	RegistryName string `json:"registryName"`
+	// Methods overrides the load balancing strategy and timeout of specific methods.
+	Methods []*MethodConfig `json:"methods"`
+	// Routes sends the matching requests to a subset of the instances, in order.
+	Routes []*RouteConfig `json:"routes"`
+	// OutlierDetection ejects the failing instances from the load balancing.
+	OutlierDetection OutlierDetectionConfig `json:"outlierDetection"`
 	// Interceptors defines custom user-defined interceptors for the client.
 	Interceptors utils.JSONStrings `json:"interceptors"`
 	// BuiltInInterceptors defines the framework-provided interceptors that run by default.
 	BuiltInInterceptors utils.JSONStrings `json:"builtInInterceptors"`
 	// CertFile specifies the path to the client-side TLS certificate.
-	CertFile string             `json:"ccertFile"`
-	Timeout  utils.JSONDuration `json:"timeout"`
+	CertFile string `json:"ccertFile"`
+	// Timeout is the timeout of the calls, applied by the timeout interceptor.
+	Timeout utils.JSONDuration `json:"timeout"`
+}
+
+// MethodConfig specifies the load balancing strategy of a method.
+type MethodConfig struct {
+	// Name is the full method, e.g., "/api.v1.user.User/Get".
+	Name string `json:"name"`
+	// Loadbalance is the load balancing strategy name of the method.
+	Loadbalance string `json:"loadbalance"`
+	// Timeout is the timeout of the calls of the method.
+	Timeout utils.JSONDuration `json:"timeout"`
 }
 
 // DefaultConfig provides the baseline settings for all clients if no specific configuration is found.
 var DefaultConfig = Config{
 	Timeout:             utils.JSONDuration{Duration: 60 * time.Second},
 	Loadbalance:         "localityRoundRobin",
-	BuiltInInterceptors: utils.JSONStrings{"panic", "rest2RpcContext", "errLog", "slowLog", "validate", "cycleChainInterceptor", "circuitBreaker"},
+	OutlierDetection:    defaultOutlierDetectionConfig,
+	BuiltInInterceptors: utils.JSONStrings{"panic", "rest2RpcContext", "auth", "timeout", "errLog", "slowLog", "validate", "cycleChainInterceptor", "rateLimiter", "retry", "circuitBreaker"},
 }
 
 // GetConfigWithProtocol retrieves the configuration for a specific protocol.
@@ -52,6 +73,21 @@ func serverConfig(protocol, serviceName string) Config {
 	return conf.complete()
 }
 
+// GetConfigWithService retrieves the configuration of a target service under a given protocol.
+func GetConfigWithService(protocol, serviceName string) Config {
+	return serverConfig(protocol, serviceName)
+}
+
+// MethodTimeout returns the timeout of a method, which falls back to the timeout of the service.
+func (c Config) MethodTimeout(method string) time.Duration {
+	for _, m := range c.Methods {
+		if m.Name == method && m.Timeout.Duration > 0 {
+			return m.Timeout.Duration
+		}
+	}
+	return c.Timeout.Duration
+}
+
 // complete finalizes the configuration by merging built-in and custom interceptors.
 // This ensures that framework essentials (like logging and validation) are always present.
 func (c Config) complete() Config {
@@ -74,3 +74,34 @@ func TestConfigComplete(t *testing.T) {
 		t.Error("Custom interceptor lost during merge")
 	}
 }
+
+// TestServiceBalancer verifies that the strategy of a service and of its methods
+// is selected from the service configuration.
+func TestServiceBalancer(t *testing.T) {
+	protocol := "grpc-balancer-test"
+	serviceName := "user-service"
+	prefix := "asjard.clients." + protocol + "." + serviceName
+	require.NoError(t, config.Set("asjard.clients."+protocol+".loadbalance", DefaultBalanceRoundRobin))
+	require.NoError(t, config.Set(prefix+".loadbalance", WeightedRoundRobinName))
+	require.NoError(t, config.Set(prefix+".registryName", "etcd"))
+	require.NoError(t, config.Set(prefix+".methods[0].name", "/api.v1.User/Get"))
+	require.NoError(t, config.Set(prefix+".methods[0].loadbalance", RingHashName))
+	require.Eventually(t, func() bool {
+		return len(serverConfig(protocol, serviceName).Methods) == 1
+	}, 3*time.Second, 20*time.Millisecond)
+	require.Equal(t, "etcd", serverConfig(protocol, serviceName).RegistryName)
+
+	picker := GetServiceBalancer(protocol, serviceName)(newWeightedSubConns("1"))
+	require.Equal(t, WeightedRoundRobinName, picker.Name())
+	op, ok := picker.(*outlierPicker)
+	require.True(t, ok)
+	rp, ok := op.Picker.(*routePicker)
+	require.True(t, ok)
+	mp, ok := rp.Picker.(*methodPicker)
+	require.True(t, ok)
+	require.IsType(t, &RingHashPicker{}, mp.methods["/api.v1.User/Get"])
+	require.IsType(t, &WeightedRoundRobinPicker{}, mp.Picker)
+
+	// Other services use the protocol strategy.
+	require.Equal(t, DefaultBalanceRoundRobin, GetServiceBalancer(protocol, "other-service")(newWeightedSubConns("1")).Name())
+}
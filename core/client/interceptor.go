@@ -2,10 +2,12 @@ package client
 
 import (
 	"context"
+	"io"
 	"maps"
 	"sync"
 
 	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
 )
 
 // ClientInterceptor defines the interface for a client-side interceptor.
@@ -54,19 +56,43 @@ type UnaryInvoker func(ctx context.Context, method string, req, reply any, cc Cl
 // tracing, or modifying request metadata.
 type UnaryClientInterceptor func(ctx context.Context, method string, req, reply any, cc ClientConnInterface, invoker UnaryInvoker) error
 
-// getChainUnaryInterceptors retrieves and chains interceptors based on protocol and configuration.
-func getChainUnaryInterceptors(protocol string, conf Config) (UnaryClientInterceptor, error) {
+// getChainInterceptors retrieves and chains interceptors based on protocol and configuration.
+// Every configured interceptor takes part in the unary chain, only those implementing
+// ClientStreamInterceptor take part in the stream chain.
+// The interceptors are returned to be closed once the chain is replaced, see closeInterceptors.
+func getChainInterceptors(protocol string, conf Config) (UnaryClientInterceptor, StreamClientInterceptor, []ClientInterceptor, error) {
 	interceptors, err := getClientInterceptors(protocol, conf)
 	if err != nil {
-		return nil, err
+		closeInterceptors(interceptors)
+		return nil, nil, nil, err
+	}
+	unaryInterceptors := make([]UnaryClientInterceptor, 0, len(interceptors))
+	streamInterceptors := make([]StreamClientInterceptor, 0, len(interceptors))
+	for _, interceptor := range interceptors {
+		unaryInterceptors = append(unaryInterceptors, interceptor.Interceptor())
+		if si, ok := interceptor.(ClientStreamInterceptor); ok {
+			streamInterceptors = append(streamInterceptors, si.StreamInterceptor())
+		}
+	}
+	// Create single functional chains from the slices of interceptors.
+	return ChainUnaryInterceptors(unaryInterceptors...), ChainStreamInterceptors(streamInterceptors...), interceptors, nil
+}
+
+// closeInterceptors releases the interceptors implementing io.Closer,
+// e.g. their configuration listeners.
+func closeInterceptors(interceptors []ClientInterceptor) {
+	for _, interceptor := range interceptors {
+		if closer, ok := interceptor.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logger.Error("close client interceptor fail", "interceptor", interceptor.Name(), "err", err)
+			}
+		}
 	}
-	// Create a single functional chain from the slice of interceptors.
-	return ChainUnaryInterceptors(interceptors...), nil
 }
 
 // getClientInterceptors builds an ordered slice of interceptors based on the Config.Interceptors list.
-func getClientInterceptors(protocol string, conf Config) ([]UnaryClientInterceptor, error) {
-	var interceptors []UnaryClientInterceptor
+func getClientInterceptors(protocol string, conf Config) ([]ClientInterceptor, error) {
+	var interceptors []ClientInterceptor
 	ncm.RLock()
 	defer ncm.RUnlock()
 
@@ -82,7 +108,7 @@ func getClientInterceptors(protocol string, conf Config) ([]UnaryClientIntercept
 			if err != nil {
 				return interceptors, err
 			}
-			interceptors = append(interceptors, interceptor.Interceptor())
+			interceptors = append(interceptors, interceptor)
 		}
 	}
 	return interceptors, nil
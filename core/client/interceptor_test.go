@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 )
 
 func TestChainUnaryClientInterceptorsAppendsOptionInterceptor(t *testing.T) {
@@ -55,3 +56,22 @@ func TestChainUnaryClientInterceptorsUsesOptionInterceptorWhenGlobalNil(t *testi
 	require.NoError(t, err)
 	require.True(t, called)
 }
+
+func TestChainStreamClientInterceptors(t *testing.T) {
+	require.Nil(t, ChainStreamInterceptors(nil, nil))
+
+	var order []string
+	makeInterceptor := func(name string) StreamClientInterceptor {
+		return func(ctx context.Context, desc *grpc.StreamDesc, cc ClientConnInterface, method string, streamer Streamer) (grpc.ClientStream, error) {
+			order = append(order, name)
+			return streamer(ctx, desc, cc, method)
+		}
+	}
+	interceptor := ChainStreamInterceptors(makeInterceptor("global"), nil, makeInterceptor("option"))
+	_, err := interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/test.Service/Watch", func(context.Context, *grpc.StreamDesc, ClientConnInterface, string) (grpc.ClientStream, error) {
+		order = append(order, "stream")
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"global", "option", "stream"}, order)
+}
@@ -22,4 +22,8 @@ type ClientOptions struct {
 	// that wraps the RPC call to provide cross-cutting concerns like logging,
 	// tracing, and security.
 	Interceptor UnaryClientInterceptor
+
+	// StreamInterceptor is the chained interceptor wrapping the creation
+	// of client streams. It only contains interceptors implementing ClientStreamInterceptor.
+	StreamInterceptor StreamClientInterceptor
 }
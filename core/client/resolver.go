@@ -38,12 +38,17 @@ func (c *ClientBuilder) Build(target resolver.Target, cc resolver.ClientConn, op
 	serviceName := target.Endpoint()
 	instanceID := query.Get("instanceID")
 	registryName := query.Get("registryName")
+	if registryName == "" {
+		registryName = serverConfig(protocol, serviceName).RegistryName
+	}
 
 	app := runtime.GetAPP()
-	listenerName := fmt.Sprintf("%s_clientResolver_%s_%s",
+	listenerName := fmt.Sprintf("%s_clientResolver_%s_%s_%s_%s",
 		constant.Framework,
 		protocol,
-		serviceName)
+		serviceName,
+		registryName,
+		instanceID)
 	options := []registry.Option{
 		registry.WithServiceName(serviceName),
 		registry.WithProtocol(protocol),
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// Streamer is the completion function called by stream interceptors to create the client stream.
+type Streamer func(ctx context.Context, desc *grpc.StreamDesc, cc ClientConnInterface, method string) (grpc.ClientStream, error)
+
+// StreamClientInterceptor is a function that intercepts the creation of a client stream.
+// It can wrap the returned grpc.ClientStream to observe every message sent or received.
+type StreamClientInterceptor func(ctx context.Context, desc *grpc.StreamDesc, cc ClientConnInterface, method string, streamer Streamer) (grpc.ClientStream, error)
+
+// ClientStreamInterceptor is an optional interface a ClientInterceptor can implement
+// to also take part in streaming RPCs.
+// Interceptors that do not implement it are skipped for streaming methods.
+type ClientStreamInterceptor interface {
+	// StreamInterceptor returns the function that wraps streaming RPCs.
+	StreamInterceptor() StreamClientInterceptor
+}
+
+// ChainStreamInterceptors creates a single stream interceptor out of a chain of many interceptors.
+// Nil interceptors are ignored, and nil is returned if none is left.
+func ChainStreamInterceptors(interceptors ...StreamClientInterceptor) StreamClientInterceptor {
+	chains := make([]StreamClientInterceptor, 0, len(interceptors))
+	for _, item := range interceptors {
+		if item != nil {
+			chains = append(chains, item)
+		}
+	}
+	if len(chains) == 0 {
+		return nil
+	}
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc ClientConnInterface, method string, streamer Streamer) (grpc.ClientStream, error) {
+		return chains[0](ctx, desc, cc, method, getChainStreamer(chains, 0, streamer))
+	}
+}
+
+// getChainStreamer recursively wraps the final streamer with the next interceptor in the chain.
+func getChainStreamer(interceptors []StreamClientInterceptor, curr int, finalStreamer Streamer) Streamer {
+	if curr == len(interceptors)-1 {
+		return finalStreamer
+	}
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc ClientConnInterface, method string) (grpc.ClientStream, error) {
+		return interceptors[curr+1](ctx, desc, cc, method, getChainStreamer(interceptors, curr+1, finalStreamer))
+	}
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/asjard/asjard/core/logger"
+	"github.com/go-playground/validator/v10"
+)
+
+// StructValidator validates a struct with its struct tags,
+// e.g., the validator of github.com/go-playground/validator.
+type StructValidator interface {
+	Struct(s any) error
+}
+
+var (
+	// defaultValidator validates the validate struct tags of the bound structs
+	// without the custom validations registered by the validatepb package.
+	defaultValidator StructValidator = validator.New()
+	dvm              sync.RWMutex
+)
+
+// SetDefaultValidator sets the validator of the structs bound by Bind,
+// it is replaced by the validatepb package with its DefaultValidator.
+func SetDefaultValidator(v StructValidator) {
+	dvm.Lock()
+	defaultValidator = v
+	dvm.Unlock()
+}
+
+func getDefaultValidator() StructValidator {
+	dvm.RLock()
+	defer dvm.RUnlock()
+	return defaultValidator
+}
+
+// Binding is a configuration prefix bound to a struct, reloaded when the configuration changes.
+// Invalid configurations are rejected, the last valid one is kept.
+type Binding[T any] struct {
+	prefix string
+	// defaults is the JSON encoding of the defaults, every load decodes it
+	// into a new value, so the maps, slices and pointers are never shared.
+	defaults []byte
+	opts     []Option
+	options  *Options
+	value    atomic.Pointer[T]
+
+	// mu serializes the reloads and the change callbacks.
+	mu       sync.Mutex
+	onChange []func(old, new *T)
+	removes  []func()
+	closed   bool
+}
+
+// Bind unmarshals the configurations with prefix into a copy of defaults,
+// validates it and reloads it every time a configuration with prefix,
+// or with one of the WithChain keys, changes.
+// defaults is copied through its JSON encoding, the fields ignored by JSON are not copied.
+// The returned cancel function stops the reloads and the change callbacks.
+//
+//	type Config struct {
+//		Timeout utils.JSONDuration `json:"timeout"`
+//		Size    int                `json:"size" validate:"gte=1"`
+//	}
+//
+//	conf, cancel, err := config.Bind("asjard.example", Config{Size: 1})
+//	defer cancel()
+//	conf.OnChange(func(old, new *Config) {})
+//	size := conf.Load().Size
+func Bind[T any](prefix string, defaults T, opts ...Option) (*Binding[T], func(), error) {
+	defaultsBytes, err := json.Marshal(defaults)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal config '%s' defaults fail: %w", prefix, err)
+	}
+	b := &Binding[T]{
+		prefix:   prefix,
+		defaults: defaultsBytes,
+		opts:     opts,
+		options:  GetOptions(opts...),
+	}
+	if err := b.loadAndWatch(); err != nil {
+		return nil, nil, err
+	}
+	return b, b.cancel, nil
+}
+
+// Load returns the current configuration, it must not be modified.
+func (b *Binding[T]) Load() *T {
+	return b.value.Load()
+}
+
+// OnChange adds a callback called with the previous and the new configuration
+// every time the configuration changes.
+// The callbacks are called in order and must not call OnChange.
+func (b *Binding[T]) OnChange(callback func(old, new *T)) *Binding[T] {
+	b.mu.Lock()
+	b.onChange = append(b.onChange, callback)
+	b.mu.Unlock()
+	return b
+}
+
+func (b *Binding[T]) loadAndWatch() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	conf, err := b.load()
+	if err != nil {
+		return err
+	}
+	b.value.Store(conf)
+	b.removes = append(b.removes, AddPrefixListener(b.prefix, b.watch))
+	for _, key := range b.options.keys {
+		b.removes = append(b.removes, AddPrefixListener(key, b.watch))
+	}
+	return nil
+}
+
+// cancel removes the listeners, the current configuration is kept.
+func (b *Binding[T]) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, remove := range b.removes {
+		remove()
+	}
+	b.removes = nil
+	b.closed = true
+}
+
+// load unmarshals and validates the configuration.
+func (b *Binding[T]) load() (*T, error) {
+	var conf T
+	if err := json.Unmarshal(b.defaults, &conf); err != nil {
+		return nil, fmt.Errorf("unmarshal config '%s' defaults fail: %w", b.prefix, err)
+	}
+	if err := GetWithUnmarshal(b.prefix, &conf, b.opts...); err != nil {
+		return nil, fmt.Errorf("unmarshal config '%s' fail: %w", b.prefix, err)
+	}
+	v := b.options.validator
+	if v == nil {
+		v = getDefaultValidator()
+	}
+	if v != nil && reflect.TypeFor[T]().Kind() == reflect.Struct {
+		if err := v.Struct(conf); err != nil {
+			return nil, fmt.Errorf("invalid config '%s': %w", b.prefix, err)
+		}
+	}
+	return &conf, nil
+}
+
+func (b *Binding[T]) watch(_ *Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// An event may be delivered while the binding is canceled.
+	if b.closed {
+		return
+	}
+	conf, err := b.load()
+	if err != nil {
+		logger.Error("config update rejected, keep the last valid config", "prefix", b.prefix, "err", err)
+		return
+	}
+	old := b.value.Load()
+	if reflect.DeepEqual(old, conf) {
+		return
+	}
+	b.value.Store(conf)
+	for _, callback := range b.onChange {
+		callback(old, conf)
+	}
+}
@@ -75,6 +75,8 @@ type ConfigManager struct {
 	sourceCfgs SourcesConfiger
 	// Management of configuration change subscribers.
 	listener *Listener
+	// Changes of the effective values since the configuration was loaded.
+	history *history
 }
 
 // CallbackFunc is the handler signature for configuration update events.
@@ -119,6 +121,7 @@ func init() {
 		globalCfgs: &ConfigsWithSyncMap{},
 		sourceCfgs: &SourcesConfigWithSyncMap{},
 		listener:   newListener(),
+		history:    &history{},
 	}
 }
 
@@ -319,14 +322,20 @@ func (m *ConfigManager) getConfigsWithPrefixs(prefixs ...string) map[string]*Val
 // setConfig updates the global configuration and notifies listeners asynchronously.
 func (m *ConfigManager) setConfig(sourceName, key string, value *Value) {
 	if m.sourceCfgs.Set(sourceName, key, value) {
+		previous, _ := m.globalCfgs.Get(key)
 		m.globalCfgs.Set(key, value)
+		m.history.record(key, previous, value)
 		go m.listener.notify(&Event{Type: EventTypeUpdate, Key: key, Value: value})
 	}
 }
 
 // delConfig removes a key globally and notifies listeners asynchronously.
 func (m *ConfigManager) delConfig(key string) {
+	previous, ok := m.globalCfgs.Get(key)
 	m.globalCfgs.Del(key)
+	if ok {
+		m.history.record(key, previous, nil)
+	}
 	go m.listener.notify(&Event{Type: EventTypeDelete, Key: key})
 }
 
@@ -342,11 +351,13 @@ func (m *ConfigManager) getValue(key string, opts *Options) any {
 	return m.getValueWithOptions(value.Value, opts)
 }
 
-// addListener registers a callback for configuration changes.
-func (m *ConfigManager) addListener(key string, opts *Options) {
+// addListener registers a callback for configuration changes
+// and returns a function unregistering it.
+func (m *ConfigManager) addListener(key string, opts *Options) func() {
 	if opts != nil && opts.watch != nil {
-		m.listener.watch(key, opts.watch)
+		return m.listener.watch(key, opts.watch)
 	}
+	return func() {}
 }
 
 // removeListener unregisters all callbacks for a specific key.
@@ -469,11 +480,12 @@ func (m *ConfigManager) setValue(key string, value any, ops *Options) error {
 		}
 		setValue = encyptedValue
 	}
+	setOptions := ops.setOptions()
 	if len(ops.sourceNames) == 0 {
-		return m.setValueToSource(key, GetString("asjard.config.setDefaultSource", "mem"), setValue)
+		return m.setValueToSource(key, GetString("asjard.config.setDefaultSource", "mem"), setValue, setOptions)
 	}
 	for _, sourceName := range ops.sourceNames {
-		if err := m.setValueToSource(key, sourceName, setValue); err != nil {
+		if err := m.setValueToSource(key, sourceName, setValue, setOptions); err != nil {
 			return err
 		}
 	}
@@ -481,7 +493,7 @@ func (m *ConfigManager) setValue(key string, value any, ops *Options) error {
 }
 
 // setValueToSource pushes configuration to a specific source or all sources if sourceName is empty.
-func (m *ConfigManager) setValueToSource(key, sourceName string, value any) error {
+func (m *ConfigManager) setValueToSource(key, sourceName string, value any, opts *SetOptions) error {
 	if sourceName == "" {
 		m.sm.RLock()
 		sourcers := make([]Sourcer, 0, len(m.sourcers))
@@ -491,7 +503,7 @@ func (m *ConfigManager) setValueToSource(key, sourceName string, value any) erro
 		m.sm.RUnlock()
 		for _, sourcer := range sourcers {
 			logger.Debug("set key to source", "key", key, "source", sourcer.Name(), "value", value)
-			if err := sourcer.Set(key, value); err != nil {
+			if err := setToSource(sourcer, key, value, opts); err != nil {
 				return err
 			}
 		}
@@ -500,11 +512,19 @@ func (m *ConfigManager) setValueToSource(key, sourceName string, value any) erro
 		if !ok {
 			return fmt.Errorf("source '%s' not found", sourceName)
 		}
-		return sourcer.Set(key, value)
+		return setToSource(sourcer, key, value, opts)
 	}
 	return nil
 }
 
+// setToSource writes a configuration with the options if the source supports them.
+func setToSource(sourcer Sourcer, key string, value any, opts *SetOptions) error {
+	if setter, ok := sourcer.(OptionsSetter); ok {
+		return setter.SetWithOptions(key, value, opts)
+	}
+	return sourcer.Set(key, value)
+}
+
 // disconnect triggers shutdown for all registered configuration sources.
 func (m *ConfigManager) disconnect() {
 	m.sm.RLock()
@@ -541,6 +561,12 @@ func GetWithPrefix(prefixKey string, opts ...Option) map[string]any {
 	return configmanager.getValueByPrefix(prefixKey, getReadOptions(opts))
 }
 
+// GetValues returns a snapshot of the effective configurations,
+// every value keeps the source it comes from. Keys are properties-formatted.
+func GetValues() map[string]*Value {
+	return configmanager.getConfigs()
+}
+
 // GetString retrieves a string value with a fallback default. Supports case transformation via Options.
 func GetString(key string, defaultValue string, opts ...Option) string {
 	options := getReadOptions(opts)
@@ -886,21 +912,24 @@ func GetKeyValueToMap(key string, value any) map[string]any {
 }
 
 // AddListener attaches a callback for updates to a specific direct key.
-func AddListener(key string, callback func(*Event)) {
+// The returned function detaches this callback only.
+func AddListener(key string, callback func(*Event)) func() {
 	options := GetOptions(WithWatch(callback))
-	configmanager.addListener(key, options)
+	return configmanager.addListener(key, options)
 }
 
 // AddPatternListener attaches a callback using regex pattern matching for keys.
-func AddPatternListener(pattern string, callback func(*Event)) {
+// The returned function detaches this callback only.
+func AddPatternListener(pattern string, callback func(*Event)) func() {
 	options := GetOptions(WithMatchWatch(pattern, callback))
-	configmanager.addListener("", options)
+	return configmanager.addListener("", options)
 }
 
 // AddPrefixListener attaches a callback for updates to any key starting with a specific prefix.
-func AddPrefixListener(prefix string, callback func(*Event)) {
+// The returned function detaches this callback only.
+func AddPrefixListener(prefix string, callback func(*Event)) func() {
 	options := GetOptions(WithPrefixWatch(prefix, callback))
-	configmanager.addListener("", options)
+	return configmanager.addListener("", options)
 }
 
 // RemoveListener unregisters all callbacks for the specified key.
@@ -155,6 +155,187 @@ func TestAddDuplicateSource(t *testing.T) {
 	})
 }
 
+type testOptionsSetter struct {
+	testSource
+	opts *SetOptions
+}
+
+func (s *testOptionsSetter) SetWithOptions(key string, value any, opts *SetOptions) error {
+	s.opts = opts
+	return nil
+}
+
+func TestSetOptions(t *testing.T) {
+	opts := GetOptions().setOptions()
+	assert.Equal(t, &SetOptions{Scope: ScopeGlobal}, opts)
+
+	opts = GetOptions(WithScope(ScopeService), WithRevision(0), WithCipher("")).setOptions()
+	assert.Equal(t, &SetOptions{Scope: ScopeService, CAS: true, Cipher: "default"}, opts)
+
+	setter := &testOptionsSetter{}
+	assert.Nil(t, setToSource(setter, "key", "value", opts))
+	assert.Equal(t, opts, setter.opts)
+
+	for value, want := range map[any]string{"a": "a", 1: "1", true: "true"} {
+		out, err := MarshalValue(value, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, want, string(out))
+	}
+	out, err := MarshalValue([]string{"a"}, &SetOptions{Cipher: "aes"})
+	assert.Nil(t, err)
+	assert.Equal(t, `encrypted_aes:["a"]`, string(out))
+
+	_, err = GetRevision("key")
+	assert.NotNil(t, err)
+	_, err = GetRevision("key", WithSource(testSourceName))
+	assert.NotNil(t, err)
+}
+
+type testBindConfig struct {
+	Name string         `json:"name"`
+	Size int            `json:"size"`
+	Tags map[string]int `json:"tags"`
+}
+
+type testBindValidator struct{}
+
+func (testBindValidator) Struct(s any) error {
+	if s.(testBindConfig).Size < 0 {
+		return fmt.Errorf("size must not be negative")
+	}
+	return nil
+}
+
+func TestBind(t *testing.T) {
+	prefix := "test_bind_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	assert.Nil(t, Set(prefix+".name", "a"))
+
+	conf, cancel, err := Bind(prefix, testBindConfig{Size: 1}, WithValidator(testBindValidator{}))
+	assert.Nil(t, err)
+	assert.Equal(t, testBindConfig{Name: "a", Size: 1}, *conf.Load())
+
+	changes := make(chan [2]testBindConfig, 10)
+	conf.OnChange(func(old, new *testBindConfig) {
+		changes <- [2]testBindConfig{*old, *new}
+	})
+
+	t.Run("Change", func(t *testing.T) {
+		assert.Nil(t, Set(prefix+".size", 2))
+		select {
+		case change := <-changes:
+			assert.Equal(t, testBindConfig{Name: "a", Size: 1}, change[0])
+			assert.Equal(t, testBindConfig{Name: "a", Size: 2}, change[1])
+		case <-time.After(time.Second):
+			t.Fatal("OnChange not called")
+		}
+		assert.Equal(t, 2, conf.Load().Size)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		assert.Nil(t, Set(prefix+".size", -1))
+		assert.Nil(t, Set(prefix+".name", "b"))
+		select {
+		case change := <-changes:
+			t.Fatalf("invalid config applied: %+v", change)
+		case <-time.After(100 * time.Millisecond):
+		}
+		assert.Equal(t, testBindConfig{Name: "a", Size: 2}, *conf.Load())
+	})
+
+	t.Run("InvalidInitial", func(t *testing.T) {
+		_, _, err := Bind(prefix, testBindConfig{}, WithValidator(testBindValidator{}))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("DefaultsNotShared", func(t *testing.T) {
+		prefix := prefix + "_defaults"
+		defaults := testBindConfig{Size: 1, Tags: map[string]int{"a": 1}}
+		conf, cancel, err := Bind(prefix, defaults)
+		assert.Nil(t, err)
+		defer cancel()
+		assert.Nil(t, Set(prefix+".tags.b", 2))
+		assert.Eventually(t, func() bool {
+			return conf.Load().Tags["b"] == 2
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, map[string]int{"a": 1}, defaults.Tags)
+	})
+
+	t.Run("DefaultValidator", func(t *testing.T) {
+		type validatedConfig struct {
+			Size int `json:"size" validate:"gte=1"`
+		}
+		prefix := prefix + "_validate"
+		assert.Nil(t, Set(prefix+".size", 0))
+		_, _, err := Bind(prefix, validatedConfig{Size: 1})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		cancel()
+		assert.Nil(t, Set(prefix+".size", 3))
+		select {
+		case change := <-changes:
+			t.Fatalf("canceled binding changed: %+v", change)
+		case <-time.After(100 * time.Millisecond):
+		}
+		assert.Equal(t, 2, conf.Load().Size)
+	})
+}
+
+func TestIntrospection(t *testing.T) {
+	key := "test_introspection_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	assert.Nil(t, Set(key, "v1"))
+	assert.Nil(t, Set(key, "v1"))
+	assert.Nil(t, Set(key, "v2"))
+
+	t.Run("SourceValues", func(t *testing.T) {
+		values := GetSourceValues(key)
+		if assert.Len(t, values, 1) {
+			assert.Equal(t, testSourceName, values[0].Sourcer.Name())
+			assert.Equal(t, "v2", values[0].Value)
+		}
+		assert.Empty(t, GetSourceValues(key+"_not_exist"))
+	})
+
+	t.Run("Changes", func(t *testing.T) {
+		changes := GetChanges(key)
+		if assert.Len(t, changes, 2) {
+			assert.Equal(t, EventTypeCreate, changes[0].Type)
+			assert.Nil(t, changes[0].Previous)
+			assert.Equal(t, "v1", changes[0].Value.Value)
+			assert.Equal(t, EventTypeUpdate, changes[1].Type)
+			assert.Equal(t, "v1", changes[1].Previous.Value)
+			assert.Equal(t, "v2", changes[1].Value.Value)
+		}
+		assert.GreaterOrEqual(t, len(GetChanges("")), 2)
+	})
+
+	t.Run("Rotation", func(t *testing.T) {
+		h := &history{}
+		for i := range maxChanges + 10 {
+			h.record("key", nil, &Value{Value: i})
+		}
+		changes := h.get("key")
+		assert.Len(t, changes, maxChanges)
+		assert.Equal(t, 10, changes[0].Value.Value)
+		assert.Equal(t, maxChanges+9, changes[maxChanges-1].Value.Value)
+	})
+
+	t.Run("Sensitive", func(t *testing.T) {
+		h := &history{}
+		value := &Value{Value: "secret", Sensitive: true}
+		h.record("key", nil, value)
+		h.record("key", value, nil)
+		changes := h.get("key")
+		if assert.Len(t, changes, 2) {
+			assert.Nil(t, changes[0].Value.Value)
+			assert.True(t, changes[0].Value.Sensitive)
+			assert.Nil(t, changes[1].Previous.Value)
+		}
+		assert.Equal(t, "secret", value.Value)
+	})
+}
+
 //gocyclo:ignore
 func TestGetWithUnmarshal(t *testing.T) {
 	t.Run("JsonUnmarshalWithParam", func(t *testing.T) {
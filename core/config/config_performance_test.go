@@ -132,7 +132,7 @@ func TestManagerDoesNotCallSourcesUnderLock(t *testing.T) {
 	done := make(chan struct{})
 	go func() {
 		defer close(done)
-		require.NoError(t, manager.setValueToSource("key", "", "value"))
+		require.NoError(t, manager.setValueToSource("key", "", "value", &SetOptions{}))
 	}()
 	select {
 	case <-done:
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxChanges is the number of changes kept in the history, the oldest are dropped.
+	maxChanges = 1024
+
+	// MaskedValue replaces the encrypted and the sensitive values when they are shown.
+	MaskedValue = ValueEncryptFlag + "******"
+)
+
+// Change is a change of the effective value of a key.
+type Change struct {
+	Key  string
+	Type EventType
+	// Previous is the effective value before the change, nil if the key was not set.
+	Previous *Value
+	// Value is the effective value after the change, nil if the key was deleted.
+	Value *Value
+	Time  time.Time
+}
+
+// history keeps the last changes of the effective configuration since it was loaded.
+type history struct {
+	mu      sync.RWMutex
+	changes []*Change
+	// next is the index of the next change in changes once it is full.
+	next int
+}
+
+// record adds a change to the history, the changes happening
+// while the configuration is loaded are not recorded.
+func (h *history) record(key string, previous, value *Value) {
+	if !loadedFlag.Load() || sameValue(previous, value) {
+		return
+	}
+	change := &Change{
+		Key:      key,
+		Type:     EventTypeUpdate,
+		Previous: redactValue(previous),
+		Value:    redactValue(value),
+		Time:     time.Now(),
+	}
+	switch {
+	case value == nil:
+		change.Type = EventTypeDelete
+	case previous == nil:
+		change.Type = EventTypeCreate
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.changes) < maxChanges {
+		h.changes = append(h.changes, change)
+		return
+	}
+	h.changes[h.next] = change
+	h.next = (h.next + 1) % maxChanges
+}
+
+// get returns the changes of a key, or of every key if key is empty, oldest first.
+func (h *history) get(key string) []*Change {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	changes := make([]*Change, 0)
+	for i := range len(h.changes) {
+		change := h.changes[(h.next+i)%len(h.changes)]
+		if key == "" || change.Key == key {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// MaskValue returns the value to show of a configuration value,
+// the sensitive values and the encrypted values, also in lists and maps, are masked.
+func MaskValue(value *Value) any {
+	if value == nil {
+		return nil
+	}
+	if value.Sensitive {
+		return MaskedValue
+	}
+	return maskValue(value.Value)
+}
+
+func maskValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, ValueEncryptFlag) {
+			return MaskedValue
+		}
+	case []any:
+		values := make([]any, 0, len(v))
+		for _, item := range v {
+			values = append(values, maskValue(item))
+		}
+		return values
+	case map[string]any:
+		values := make(map[string]any, len(v))
+		for key, item := range v {
+			values[key] = maskValue(item)
+		}
+		return values
+	}
+	return value
+}
+
+// redactValue returns a copy of a sensitive value without its value.
+func redactValue(value *Value) *Value {
+	if value == nil || !value.Sensitive {
+		return value
+	}
+	redacted := *value
+	redacted.Value = nil
+	return &redacted
+}
+
+// sameValue reports whether two values are the same value from the same source.
+func sameValue(a, b *Value) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return sourceName(a) == sourceName(b) && a.Ref == b.Ref && reflect.DeepEqual(a.Value, b.Value)
+}
+
+func sourceName(value *Value) string {
+	if value.Sourcer == nil {
+		return ""
+	}
+	return value.Sourcer.Name()
+}
+
+// GetSourceValues returns the value of a key in every loaded source,
+// ordered by descending source priority, the first value is the effective one
+// unless the key was deleted from its source.
+func GetSourceValues(key string) []*Value {
+	sourcesMu.RLock()
+	registered := append([]*Source(nil), sources...)
+	sourcesMu.RUnlock()
+	values := make([]*Value, 0, len(registered))
+	for i := len(registered) - 1; i >= 0; i-- {
+		if value, ok := configmanager.sourceCfgs.Get(registered[i].name, key); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// GetChanges returns the changes of the effective value of a key since the configuration
+// was loaded, oldest first. The changes of every key are returned if key is empty.
+// Only the last changes are kept, without the values of the sensitive values.
+func GetChanges(key string) []*Change {
+	return configmanager.history.get(key)
+}
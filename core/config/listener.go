@@ -2,6 +2,7 @@ package config
 
 import (
 	"regexp"
+	"slices"
 	"sync"
 
 	"github.com/asjard/asjard/core/logger"
@@ -20,19 +21,32 @@ type Listener struct {
 }
 
 type callbackGroup struct {
-	mu        sync.RWMutex
-	callbacks []CallbackFunc
+	mu sync.RWMutex
+	// The callbacks are stored by pointer to be removed one by one.
+	callbacks []*CallbackFunc
 }
 
-func (g *callbackGroup) add(callback CallbackFunc) {
+// add adds the callback and returns a function removing it.
+func (g *callbackGroup) add(callback CallbackFunc) func() {
+	entry := &callback
 	g.mu.Lock()
-	g.callbacks = append(g.callbacks, callback)
+	g.callbacks = append(g.callbacks, entry)
 	g.mu.Unlock()
+	return func() {
+		g.mu.Lock()
+		g.callbacks = slices.DeleteFunc(g.callbacks, func(c *CallbackFunc) bool {
+			return c == entry
+		})
+		g.mu.Unlock()
+	}
 }
 
 func (g *callbackGroup) snapshot() []CallbackFunc {
 	g.mu.RLock()
-	callbacks := append([]CallbackFunc(nil), g.callbacks...)
+	callbacks := make([]CallbackFunc, 0, len(g.callbacks))
+	for _, callback := range g.callbacks {
+		callbacks = append(callbacks, *callback)
+	}
 	g.mu.RUnlock()
 	return callbacks
 }
@@ -53,21 +67,28 @@ func newListener() *Listener {
 
 // watch registers a new listener based on the provided options.
 // It can register a listener for a specific key, a regex pattern, or both.
-func (l *Listener) watch(key string, opt *watchOptions) {
+// The returned function removes the registered callbacks only.
+func (l *Listener) watch(key string, opt *watchOptions) func() {
 	if opt == nil || opt.callback == nil {
-		return
+		return func() {}
 	}
+	var removes []func()
 
 	// Register as a pattern-based listener if a regex pattern is provided.
 	if opt.pattern != "" {
 		group, _ := l.matchCallbacks.LoadOrStore(opt.pattern, &callbackGroup{})
-		group.(*callbackGroup).add(opt.callback)
+		removes = append(removes, group.(*callbackGroup).add(opt.callback))
 	}
 
 	// Register as a direct key listener if a specific key is provided.
 	if key != "" {
 		group, _ := l.callbacks.LoadOrStore(key, &callbackGroup{})
-		group.(*callbackGroup).add(opt.callback)
+		removes = append(removes, group.(*callbackGroup).add(opt.callback))
+	}
+	return func() {
+		for _, remove := range removes {
+			remove()
+		}
 	}
 }
 
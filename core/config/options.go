@@ -40,6 +40,13 @@ type Options struct {
 	toLower bool
 	// keys provides an ordered list of fallback keys to check if the primary key is missing.
 	keys []string
+	// validator validates the structs bound by Bind, defaults to the validator set by SetDefaultValidator.
+	validator StructValidator
+	// scope is the hierarchical prefix Set writes into, for the sources supporting it.
+	scope string
+	// cas makes Set write only if the revision of the key is revision.
+	cas      bool
+	revision int64
 }
 
 // ListenFunc is a filter function used to determine if a callback should be triggered based on event details.
@@ -176,6 +183,31 @@ func WithChain(keys []string) func(opts *Options) {
 	}
 }
 
+// WithValidator sets the validator of the structs bound by Bind.
+func WithValidator(validator StructValidator) func(opts *Options) {
+	return func(opts *Options) {
+		opts.validator = validator
+	}
+}
+
+// WithScope sets the hierarchical prefix Set writes into, e.g., ScopeService,
+// for the sources implementing OptionsSetter.
+func WithScope(scope string) func(opts *Options) {
+	return func(opts *Options) {
+		opts.scope = scope
+	}
+}
+
+// WithRevision makes Set write only if the revision of the key is revision, see GetRevision,
+// a revision of 0 means the key must not exist.
+// Set returns ErrRevisionConflict otherwise.
+func WithRevision(revision int64) func(opts *Options) {
+	return func(opts *Options) {
+		opts.cas = true
+		opts.revision = revision
+	}
+}
+
 // GetOptions processes a slice of Option functions and returns the final populated Options struct.
 // It initializes defaults like the system-wide delimiter.
 func GetOptions(opts ...Option) *Options {
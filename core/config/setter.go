@@ -0,0 +1,132 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/asjard/asjard/core/security"
+)
+
+// Scopes are the hierarchical prefixes of the remote sources such as etcd and consul,
+// the configurations are written into with WithScope.
+const (
+	// ScopeGlobal is the prefix of every service of the application: /{app}/configs/
+	ScopeGlobal = "global"
+	// ScopeEnv is the prefix of the environment: /{app}/configs/{env}/
+	ScopeEnv = "env"
+	// ScopeService is the prefix of the service: /{app}/configs/service/{service}/
+	ScopeService = "service"
+	// ScopeRegion is the prefix of the service in the region: /{app}/configs/service/{service}/{region}/
+	ScopeRegion = "region"
+	// ScopeAZ is the prefix of the service in the availability zone: /{app}/configs/service/{service}/{region}/{az}/
+	ScopeAZ = "az"
+	// ScopeEnvService is the prefix of the service in the environment: /{app}/configs/{env}/service/{service}/
+	ScopeEnvService = "envService"
+	// ScopeEnvRegion is the prefix of the service in the region of the environment.
+	ScopeEnvRegion = "envRegion"
+	// ScopeEnvAZ is the prefix of the service in the availability zone of the environment.
+	ScopeEnvAZ = "envAz"
+	// ScopeGroup is the prefix of the service group shared by its entrypoints: /{app}/configs/service/{group}/
+	ScopeGroup = "group"
+	// ScopeGroupRegion is the prefix of the service group in the region: /{app}/configs/service/{group}/{region}/
+	ScopeGroupRegion = "groupRegion"
+	// ScopeGroupAZ is the prefix of the service group in the availability zone: /{app}/configs/service/{group}/{region}/{az}/
+	ScopeGroupAZ = "groupAz"
+	// ScopeEnvGroup is the prefix of the service group in the environment: /{app}/configs/{env}/service/{group}/
+	ScopeEnvGroup = "envGroup"
+	// ScopeEnvGroupRegion is the prefix of the service group in the region of the environment.
+	ScopeEnvGroupRegion = "envGroupRegion"
+	// ScopeEnvGroupAZ is the prefix of the service group in the availability zone of the environment.
+	ScopeEnvGroupAZ = "envGroupAz"
+	// ScopeInstance is the prefix of the instance: /{app}/configs/runtime/{instanceID}/
+	ScopeInstance = "instance"
+)
+
+// ErrRevisionConflict is returned by Set if the revision of the key is not the one given by WithRevision.
+var ErrRevisionConflict = errors.New("config revision conflict")
+
+// SetOptions are the options of a source writing a configuration.
+type SetOptions struct {
+	// Scope is the prefix the configuration is written into, defaults to ScopeGlobal.
+	Scope string
+	// CAS writes the configuration only if its revision is Revision,
+	// a revision of 0 means the key must not exist.
+	CAS      bool
+	Revision int64
+	// Cipher is the name of the cipher the value is encrypted with by WithCipher,
+	// empty if the value is not encrypted.
+	Cipher string
+}
+
+// OptionsSetter is implemented by the sources writing into hierarchical prefixes
+// or supporting compare-and-swap, Set calls it instead of Sourcer.Set.
+type OptionsSetter interface {
+	SetWithOptions(key string, value any, opts *SetOptions) error
+}
+
+// Revisioner is implemented by the sources versioning their configurations.
+type Revisioner interface {
+	// Revision returns the revision of a key in a scope, 0 if the key does not exist.
+	Revision(key, scope string) (int64, error)
+}
+
+// GetRevision returns the revision of a key in the scope given by WithScope of the source given by WithSource,
+// it is given to WithRevision to update the key only if it was not changed in between.
+func GetRevision(key string, opts ...Option) (int64, error) {
+	options := GetOptions(opts...)
+	if len(options.sourceNames) != 1 {
+		return 0, errors.New("get revision requires one source")
+	}
+	sourcer, ok := configmanager.getSourcer(options.sourceNames[0])
+	if !ok {
+		return 0, fmt.Errorf("source '%s' not found", options.sourceNames[0])
+	}
+	revisioner, ok := sourcer.(Revisioner)
+	if !ok {
+		return 0, fmt.Errorf("source '%s' does not support revisions", options.sourceNames[0])
+	}
+	return revisioner.Revision(key, options.setOptions().Scope)
+}
+
+// MarshalValue returns the value written into a remote source,
+// strings and bytes are written as they are, other values as json.
+// Encrypted values are flagged with encrypted_{cipher}: to be decrypted when they are read.
+func MarshalValue(value any, opts *SetOptions) ([]byte, error) {
+	var out []byte
+	switch v := value.(type) {
+	case string:
+		out = []byte(v)
+	case []byte:
+		out = v
+	default:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		out = b
+	}
+	if opts != nil && opts.Cipher != "" {
+		return append([]byte(ValueEncryptFlag+opts.Cipher+ValueEncryptSplitSymbol), out...), nil
+	}
+	return out, nil
+}
+
+// setOptions returns the options of the sources writing a configuration.
+func (opts *Options) setOptions() *SetOptions {
+	setOptions := &SetOptions{
+		Scope:    opts.scope,
+		CAS:      opts.cas,
+		Revision: opts.revision,
+	}
+	if opts.cipher {
+		setOptions.Cipher = opts.cipherName
+		if setOptions.Cipher == "" {
+			setOptions.Cipher = security.DefaultCipherName
+		}
+	}
+	if setOptions.Scope == "" {
+		setOptions.Scope = ScopeGlobal
+	}
+	return setOptions
+}
@@ -137,7 +137,8 @@ func (s *File) read(file string) (map[string]*config.Value, error) {
 	if err != nil {
 		return nil, err
 	}
-	if strings.HasPrefix(baseName, FileEncryptFlag) {
+	encrypted := strings.HasPrefix(baseName, FileEncryptFlag)
+	if encrypted {
 		nameList := strings.Split(baseName, FileNameSplitSymbol)
 		var decryptOptions []security.Option
 		if len(nameList) > 2 {
@@ -159,6 +160,8 @@ func (s *File) read(file string) (map[string]*config.Value, error) {
 			Sourcer: s,
 			Value:   value,
 			Ref:     file,
+			// 加密文件解密后的配置不可展示
+			Sensitive: encrypted,
 		}
 	}
 	return configs, nil
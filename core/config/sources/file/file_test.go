@@ -29,12 +29,14 @@ func TestFile(t *testing.T) {
 	var m sync.RWMutex
 	var eventKey string
 	var eventValue any
+	var eventSensitive bool
 	source, err := New(&config.SourceOptions{
 		Callback: func(event *config.Event) {
 			m.Lock()
 			defer m.Unlock()
 			eventKey = event.Key
 			eventValue = event.Value.Value
+			eventSensitive = event.Value.Sensitive
 		},
 	})
 	defer source.Disconnect()
@@ -45,6 +47,7 @@ func TestFile(t *testing.T) {
 		value, ok := configs[testKey]
 		assert.Equal(t, true, ok)
 		assert.Equal(t, testValue, value.Value)
+		assert.False(t, value.Sensitive)
 	})
 	t.Run("EncryptFile", func(t *testing.T) {
 		testFile := "encrypted_base64_file.yaml"
@@ -58,6 +61,7 @@ func TestFile(t *testing.T) {
 		defer m.RUnlock()
 		assert.Equal(t, testKey, eventKey)
 		assert.Equal(t, testValue, eventValue)
+		assert.True(t, eventSensitive)
 	})
 	t.Run("MultiPaths", func(t *testing.T) {
 		testFile := "test_file1.yaml"
@@ -23,6 +23,10 @@ type Value struct {
 	// Even within a single source, different values might have different priorities
 	// (e.g., a CLI flag vs. a default value within the same internal provider).
 	Priority int
+
+	// Sensitive marks a value which must not be shown, e.g., a value of an encrypted file
+	// which is stored decrypted. It is masked by the introspection and not kept in the history.
+	Sensitive bool
 }
 
 // String provides a formatted string representation of the Value object.
@@ -74,14 +74,29 @@ const (
 	// Metrics and Monitoring
 	ConfigMetricsPrefix = Framework + ".metrics"
 
+	// Configuration introspection API
+	ConfigIntrospectionEnabled = Framework + ".config.introspection.enabled"
+
+	// Feature flags
+	ConfigFeatureFlagPrefix = Framework + ".featureFlags"
+
+	// Authentication, %s represents the authenticator name (e.g., 'jwt').
+	ConfigAuthPrefix         = Framework + ".auth"
+	ConfigAuthWithNamePrefix = ConfigAuthPrefix + ".%s"
+
+	// Authorization policies
+	ConfigAuthzPrefix = Framework + ".authz"
+
 	// Service Registry and Discovery parameters
 	ConfigRegistryFailureThreshold    = "asjard.registry.failureThreshold"
 	ConfigRegistryHealthCheck         = "asjard.registry.healthCheck"
 	ConfigRegistryHealthCheckInterval = "asjard.registry.healthCheckInterval"
+	ConfigRegistryHealthCheckTimeout  = "asjard.registry.healthCheckTimeout"
 	ConfigRegistryLocalDiscoverPrefix = "asjard.registry.localDiscover"
 	ConfigRegistryAutoRegiste         = "asjard.registry.autoRegiste"
 	CofigRegistryAutoDiscove          = "asjard.registry.autoDiscove"
 	ConfigRegistryDelayRegiste        = "asjard.registry.delayRegiste"
+	ConfigRegistryHeartbeat           = "asjard.registry.heartbeat"
 	ConfigRegistryHeartbeatInterval   = "asjard.registry.heartbeatInterval"
 
 	// Resilience (Circuit Breaker) and Observability Interceptors
@@ -93,7 +108,13 @@ const (
 	ConfigInterceptorClientRest2RpcContextPrefix           = "asjard.interceptors.client.rest2RpcContext"
 	ConfigInterceptorClientSlowLogPrefix                   = "asjard.interceptors.client.slowLog"
 	ConfigInterceptorClientErrLogPrefix                    = "asjard.interceptors.client.errLog"
+	ConfigInterceptorClientRateLimiterPrefix               = "asjard.interceptors.client.rateLimiter"
+	ConfigInterceptorClientRetryPrefix                     = "asjard.interceptors.client.retry"
+	ConfigInterceptorClientTimeoutPrefix                   = "asjard.interceptors.client.timeout"
 	ConfigInterceptorServerAccessLogPrefix                 = "asjard.interceptors.server.accessLog"
+	ConfigInterceptorServerQuotaPrefix                     = "asjard.interceptors.server.quota"
+	ConfigInterceptorServerAuthPrefix                      = "asjard.interceptors.server.auth"
+	ConfigInterceptorServerAuthzPrefix                     = "asjard.interceptors.server.authz"
 
 	// Security/Cryptography Keys
 	// %s represents the cipher instance name (e.g., 'default').
@@ -45,13 +45,15 @@ type PushGatewayConfig struct {
 // defaultConfig provides the "out-of-the-box" settings if no external configuration is found.
 var defaultConfig = Config{
 	BuiltInCollectors: utils.JSONStrings{
-		"go_collector",                 // Go runtime stats (GC, Goroutines)
-		"process_collector",            // OS process stats (CPU, Memory)
-		"db_default",                   // Standard database connection pool stats
-		"api_requests_total",           // HTTP/gRPC request counter
-		"api_requests_latency_seconds", // Request duration histogram
-		"api_request_size_bytes",       // Inbound payload size
-		"api_response_size_bytes",      // Outbound payload size
+		"go_collector",                   // Go runtime stats (GC, Goroutines)
+		"process_collector",              // OS process stats (CPU, Memory)
+		"db_default",                     // Standard database connection pool stats
+		"api_requests_total",             // HTTP/gRPC request counter
+		"api_requests_latency_seconds",   // Request duration histogram
+		"api_request_size_bytes",         // Inbound payload size
+		"api_response_size_bytes",        // Outbound payload size
+		"client_outlier_ejections_total", // Ejections of outlier instances by the client
+		"feature_flag_evaluations_total", // Feature flag evaluations by flag and result
 	},
 	PushGateway: PushGatewayConfig{
 		// Default to pushing every 5 seconds.
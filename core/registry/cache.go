@@ -178,16 +178,66 @@ func (c *cache) notify(eventType EventType, instance *Instance) {
 
 // healthCheck is a loop that periodically triggers the health probe mechanism.
 func (c *cache) healthCheck() {
+	if c.conf.HealthCheckInterval.Duration <= 0 {
+		logger.Warn("registry health check disabled, invalid healthCheckInterval",
+			"interval", c.conf.HealthCheckInterval.Duration.String())
+		return
+	}
 	ticker := time.NewTicker(c.conf.HealthCheckInterval.Duration)
 	for _ = range ticker.C {
 		c.doHealthCheck()
 	}
 }
 
-// doHealthCheck executes the probing logic (placeholder implementation commented out).
+// doHealthCheck probes all cached instances concurrently, at most healthCheckConcurrency at a time,
+// instances failing more than failureThreshold consecutive times are evicted.
 func (c *cache) doHealthCheck() {
-	// Logic: Iterate through services, call healthCheckFunc,
-	// update failure thresholds, and delete instances that exceed the threshold.
+	if c.healthCheckFunc == nil {
+		return
+	}
+	c.sm.RLock()
+	instances := make([]*Instance, 0, len(c.services))
+	for _, instance := range c.services {
+		instances = append(instances, instance)
+	}
+	c.sm.RUnlock()
+
+	concurrency := c.conf.HealthCheckConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultConfig.HealthCheckConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, instance := range instances {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(instance *Instance) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			failKey := instance.DiscoverName + instance.Service.Instance.Name + instance.Service.Instance.ID
+			err := c.healthCheckFunc(instance.DiscoverName, instance.Service)
+			if err == nil {
+				c.resetFailureThreshold(failKey)
+				return
+			}
+			threshold := c.getFailureThreshold(failKey)
+			logger.Warn("instance health check fail",
+				"instance_id", instance.Service.Instance.ID,
+				"instance_name", instance.Service.Instance.Name,
+				"registry", instance.DiscoverName,
+				"failures", threshold,
+				"err", err)
+			if threshold < c.failureThreshold {
+				c.setFailureThreshold(failKey, threshold+1)
+				return
+			}
+			c.resetFailureThreshold(failKey)
+			c.delete(instance)
+		}(instance)
+	}
+	wg.Wait()
 }
 
 // getFailureThreshold retrieves the current number of consecutive failures for a key.
@@ -207,3 +257,10 @@ func (c *cache) setFailureThreshold(failKey string, threshold int) {
 	c.failureThresholds[failKey] = threshold
 	c.fm.Unlock()
 }
+
+// resetFailureThreshold clears the failure count of an instance.
+func (c *cache) resetFailureThreshold(failKey string) {
+	c.fm.Lock()
+	delete(c.failureThresholds, failKey)
+	c.fm.Unlock()
+}
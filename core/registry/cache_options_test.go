@@ -1,13 +1,27 @@
 package registry
 
 import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/asjard/asjard/core/config"
 	"github.com/asjard/asjard/core/runtime"
 	"github.com/asjard/asjard/core/server"
+	"github.com/asjard/asjard/utils"
 	"github.com/stretchr/testify/require"
 )
 
+func TestMain(m *testing.M) {
+	if err := config.Load(-1); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
 func testInstance(id string) *Instance {
 	return &Instance{DiscoverName: "local", Service: &server.Service{
 		APP: runtime.APP{App: "app", Region: "region", Environment: "dev", Instance: runtime.Instance{
@@ -67,3 +81,140 @@ func TestDiscoveryOptions(t *testing.T) {
 	opts.Callback(nil)
 	require.True(t, called)
 }
+
+type testHealthChecker struct {
+	name   string
+	err    error
+	checks int
+}
+
+func (c *testHealthChecker) GetAll() ([]*Instance, error) { return nil, nil }
+func (c *testHealthChecker) Name() string                 { return c.name }
+func (c *testHealthChecker) Registe(*server.Service) error {
+	c.checks += 100
+	return nil
+}
+func (c *testHealthChecker) Remove(*server.Service) {}
+func (c *testHealthChecker) HealthCheck(context.Context, *server.Service) error {
+	c.checks++
+	return c.err
+}
+
+func TestHealthCheckEviction(t *testing.T) {
+	var failed atomic.Bool
+	c := newCache(&Config{DiscoverConfig: DiscoverConfig{FailureThreshold: 2}}, func(string, *server.Service) error {
+		if failed.Load() {
+			return errors.New("unhealthy")
+		}
+		return nil
+	})
+	var events []*Event
+	c.addListener(NewOptions([]Option{WithWatch("watch", func(event *Event) {
+		events = append(events, event)
+	})}))
+	instance := testInstance("one")
+	c.update([]*Instance{instance})
+
+	c.doHealthCheck()
+	require.Len(t, c.pick(NewOptions(nil)), 1)
+
+	failed.Store(true)
+	c.doHealthCheck()
+	require.Len(t, c.pick(NewOptions(nil)), 1, "first failure keeps the instance")
+	failed.Store(false)
+	c.doHealthCheck()
+	failed.Store(true)
+	c.doHealthCheck()
+	require.Len(t, c.pick(NewOptions(nil)), 1, "a success resets the failures")
+	c.doHealthCheck()
+	require.Empty(t, c.pick(NewOptions(nil)))
+	require.Equal(t, EventTypeDelete, events[len(events)-1].Type)
+	require.Empty(t, c.failureThresholds)
+}
+
+func TestHealthCheckConcurrency(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	c := newCache(&Config{DiscoverConfig: DiscoverConfig{HealthCheckConcurrency: 2}}, func(string, *server.Service) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			max := maxRunning.Load()
+			if n <= max || maxRunning.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		c.update([]*Instance{testInstance(id)})
+	}
+	c.doHealthCheck()
+	require.Equal(t, int32(2), maxRunning.Load())
+}
+
+func TestRegistryManagerHealthCheck(t *testing.T) {
+	checker := &testHealthChecker{name: "checker"}
+	r := &RegistryManager{
+		conf:      &Config{DiscoverConfig: DiscoverConfig{HealthCheckTimeout: utils.JSONDuration{Duration: time.Second}}},
+		discovers: []Discovery{checker, &testHealthChecker{name: "other"}},
+	}
+	instance := testInstance("one").Service
+	require.NoError(t, r.healthCheck("checker", instance))
+	require.Equal(t, 1, checker.checks)
+	checker.err = errors.New("unhealthy")
+	require.Error(t, r.healthCheck("checker", instance))
+	require.Error(t, r.healthCheck("missing", instance))
+
+	t.Run("probe", func(t *testing.T) {
+		var probed []string
+		defer delete(healthProbes, "grpc")
+		AddHealthProbe("grpc", func(_ context.Context, _ *server.Service, address string) error {
+			probed = append(probed, address)
+			if address == "127.0.0.1:1" {
+				return errors.New("refused")
+			}
+			return nil
+		})
+		service := server.NewService()
+		service.Region = runtime.GetAPP().Region
+		require.NoError(t, probe(context.Background(), service), "nothing to probe")
+
+		service.AddEndpoint("grpc", server.AddressConfig{Listen: "127.0.0.1:1"})
+		require.Error(t, probe(context.Background(), service))
+		service.AddEndpoint("grpc", server.AddressConfig{Listen: "127.0.0.1:2"})
+		require.NoError(t, probe(context.Background(), service))
+		require.Equal(t, []string{"127.0.0.1:1", "127.0.0.1:1", "127.0.0.1:2"}, probed)
+	})
+}
+
+type testRegister struct{ registes int }
+
+func (r *testRegister) Name() string { return "plain" }
+func (r *testRegister) Registe(*server.Service) error {
+	r.registes++
+	return nil
+}
+func (r *testRegister) Remove(*server.Service) {}
+
+func TestHeartbeat(t *testing.T) {
+	checker := &testHealthChecker{name: "checker"}
+	r := &RegistryManager{
+		conf:      &Config{RegisterConfig: RegisterConfig{HeartbeatInterval: utils.JSONDuration{Duration: time.Second}}},
+		registers: []Register{checker},
+	}
+	r.doHeartbeat(context.Background())
+	require.Equal(t, 1, checker.checks)
+	checker.err = errors.New("lost")
+	r.doHeartbeat(context.Background())
+	require.Equal(t, 102, checker.checks, "registe again after a failed check")
+
+	// The registers without HealthChecker are not registered again.
+	plain := &testRegister{}
+	r.registers = []Register{plain}
+	r.doHeartbeat(context.Background())
+	require.Equal(t, 0, plain.registes)
+
+	r.conf.HeartbeatInterval.Duration = 0
+	require.Error(t, r.heartbeat())
+}
@@ -20,8 +20,8 @@ type RegisterConfig struct {
 	AutoRegiste bool `json:"autoRegiste"`
 	// DelayRegiste allows for a "warm-up" period before the service is marked as available.
 	DelayRegiste utils.JSONDuration `json:"delayRegiste"`
-	// Hearbeat enables active signaling to the registry to prove the service is still alive.
-	Hearbeat bool `json:"hearbeat"`
+	// Heartbeat enables active signaling to the registry to prove the service is still alive.
+	Heartbeat bool `json:"heartbeat"`
 	// HeartbeatInterval defines how often the heartbeat signal is sent.
 	HeartbeatInterval utils.JSONDuration `json:"heartbeatInterval"`
 }
@@ -34,6 +34,10 @@ type DiscoverConfig struct {
 	HealthCheck bool `json:"healthCheck"`
 	// HealthCheckInterval defines the frequency of local health probes.
 	HealthCheckInterval utils.JSONDuration `json:"healthCheckInterval"`
+	// HealthCheckTimeout bounds the duration of a single instance probe.
+	HealthCheckTimeout utils.JSONDuration `json:"healthCheckTimeout"`
+	// HealthCheckConcurrency is the maximum number of instances probed at the same time.
+	HealthCheckConcurrency int `json:"healthCheckConcurrency"`
 	// FailureThreshold is the number of consecutive failed probes allowed before an instance is removed.
 	FailureThreshold int `json:"failureThreshold"`
 }
@@ -45,9 +49,11 @@ var defaultConfig = Config{
 		HeartbeatInterval: utils.JSONDuration{Duration: 5 * time.Second},
 	},
 	DiscoverConfig: DiscoverConfig{
-		AutoDiscove:         true,
-		HealthCheckInterval: utils.JSONDuration{Duration: 10 * time.Second},
-		FailureThreshold:    1,
+		AutoDiscove:            true,
+		HealthCheckInterval:    utils.JSONDuration{Duration: 10 * time.Second},
+		HealthCheckTimeout:     utils.JSONDuration{Duration: 3 * time.Second},
+		HealthCheckConcurrency: 16,
+		FailureThreshold:       1,
 	},
 }
 
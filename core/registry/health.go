@@ -1 +1,84 @@
 package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/asjard/asjard/core/runtime"
+	"github.com/asjard/asjard/core/server"
+)
+
+// HealthChecker is an optional interface for Discovery and Register backends.
+//
+// A Discovery implementing it is used to probe the instances it discovered,
+// instead of the built-in protocol probes.
+// A Register implementing it is called with the current service on every heartbeat
+// and receives Registe again if the check fails, a Register without it is not checked.
+type HealthChecker interface {
+	// HealthCheck returns an error if the service is not healthy.
+	HealthCheck(ctx context.Context, service *server.Service) error
+}
+
+// HealthProbeFunc probes a single address of an instance over a specific protocol.
+// The instance is used to find the client configuration of the service, e.g. its TLS certificate.
+type HealthProbeFunc func(ctx context.Context, instance *server.Service, address string) error
+
+var (
+	// healthProbes maintains the probes by protocol name.
+	healthProbes = make(map[string]HealthProbeFunc)
+	hpm          sync.RWMutex
+)
+
+// AddHealthProbe registers the probe used to check instances exposing the protocol.
+// It is called by the protocol packages (in their init functions),
+// e.g. the built-in health handler registers probes for grpc and rest.
+func AddHealthProbe(protocol string, probe HealthProbeFunc) {
+	hpm.Lock()
+	healthProbes[protocol] = probe
+	hpm.Unlock()
+}
+
+// probe checks the instance through the first of its protocols having a registered probe.
+// The instance is healthy if any of the reachable addresses of the protocol responds,
+// an instance without any probeable protocol is considered healthy.
+func probe(ctx context.Context, instance *server.Service) error {
+	hpm.RLock()
+	defer hpm.RUnlock()
+	protocols := make([]string, 0, len(instance.Endpoints))
+	for protocol := range instance.Endpoints {
+		if _, ok := healthProbes[protocol]; ok {
+			protocols = append(protocols, protocol)
+		}
+	}
+	// Probe in a stable order, map iteration is random.
+	sort.Strings(protocols)
+	for _, protocol := range protocols {
+		addresses := probeAddresses(instance, protocol)
+		if len(addresses) == 0 {
+			continue
+		}
+		var errs []error
+		for _, address := range addresses {
+			err := healthProbes[protocol](ctx, instance, address)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, fmt.Errorf("%s://%s: %w", protocol, address, err))
+		}
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// probeAddresses returns the addresses reachable from the current node,
+// following the same rules as the client picker:
+// listen addresses in the same region, advertise addresses across regions.
+func probeAddresses(instance *server.Service, protocol string) []string {
+	if instance.Region == runtime.GetAPP().Region {
+		return instance.GetListenAddresses(protocol)
+	}
+	return instance.GetAdvertiseAddresses(protocol)
+}
@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -29,6 +30,9 @@ type RegistryManager struct {
 	registers []Register
 	// discovers is the list of active backends being polled for upstream services.
 	discovers []Discovery
+
+	// heartbeatCancel stops the heartbeat loop when the service is removed.
+	heartbeatCancel context.CancelFunc
 }
 
 var registryManager *RegistryManager
@@ -74,36 +78,74 @@ func (r *RegistryManager) delayRegiste(duration time.Duration) error {
 	go func(duration time.Duration) {
 		t := time.After(duration)
 		<-t
-		r.doRegiste()
+		if err := r.doRegiste(); err != nil {
+			logger.Error("delay registe fail", "err", err)
+		}
 	}(duration)
 	return nil
 }
 
-// doRegiste performs the actual call to external registry backends.
+// doRegiste performs the actual call to external registry backends
+// and starts the heartbeat once the service is registered.
 func (r *RegistryManager) doRegiste() error {
 	for _, register := range r.registers {
 		if err := register.Registe(r.currentService); err != nil {
 			return err
 		}
 	}
+	if r.conf.Heartbeat {
+		return r.heartbeat()
+	}
 	return nil
 }
 
-// heartbeat starts a background goroutine to periodically signal vitality to registers.
+// heartbeat starts a background goroutine to periodically check the registration in the registers.
 func (r *RegistryManager) heartbeat() error {
+	if r.conf.HeartbeatInterval.Duration <= 0 {
+		return fmt.Errorf("invalid registry heartbeatInterval '%s'", r.conf.HeartbeatInterval.Duration)
+	}
+	for _, register := range r.registers {
+		if _, ok := register.(HealthChecker); !ok {
+			logger.Warn("registry heartbeat not supported by register", "register", register.Name())
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.heartbeatCancel = cancel
 	go func(duration time.Duration) {
 		ticker := time.NewTicker(duration)
+		defer ticker.Stop()
 		for {
-			<-ticker.C
-			r.doHeartbeat()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.doHeartbeat(ctx)
+			}
 		}
 	}(r.conf.HeartbeatInterval.Duration)
 	return nil
 }
 
-// doHeartbeat sends the keep-alive signal to all registers.
-func (r *RegistryManager) doHeartbeat() {
-	// Implementation placeholder for heartbeat logic.
+// doHeartbeat checks the registration in the registers implementing HealthChecker,
+// a failed check is followed by a new registration, the backend may have lost the service.
+// The registers without HealthChecker keep their registration alive by themselves.
+func (r *RegistryManager) doHeartbeat(ctx context.Context) {
+	for _, register := range r.registers {
+		checker, ok := register.(HealthChecker)
+		if !ok {
+			continue
+		}
+		hctx, cancel := context.WithTimeout(ctx, r.conf.HeartbeatInterval.Duration)
+		err := checker.HealthCheck(hctx, r.currentService)
+		cancel()
+		if err == nil {
+			continue
+		}
+		logger.Warn("registry heartbeat fail, registe again", "register", register.Name(), "err", err)
+		if err := register.Registe(r.currentService); err != nil {
+			logger.Error("registry heartbeat registe fail", "register", register.Name(), "err", err)
+		}
+	}
 }
 
 // remove handles the "un-registration" of the service, typically during shutdown.
@@ -111,6 +153,9 @@ func (r *RegistryManager) remove() error {
 	if !r.conf.AutoRegiste {
 		return nil
 	}
+	if r.heartbeatCancel != nil {
+		r.heartbeatCancel()
+	}
 	for _, register := range r.registers {
 		register.Remove(r.currentService)
 	}
@@ -147,10 +192,22 @@ func (r *RegistryManager) discove() error {
 }
 
 // healthCheck facilitates active probing of discovered instances using the specific discovery source.
+// Discovery backends implementing HealthChecker probe their own instances,
+// the others are probed through the registered protocol probes.
 func (r *RegistryManager) healthCheck(discoverName string, instance *server.Service) error {
+	timeout := r.conf.HealthCheckTimeout.Duration
+	if timeout <= 0 {
+		timeout = r.conf.HealthCheckInterval.Duration
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 	for _, discover := range r.discovers {
 		if discover.Name() == discoverName {
 			// Proxy health check to the specific discovery implementation if supported.
+			if checker, ok := discover.(HealthChecker); ok {
+				return checker.HealthCheck(ctx, instance)
+			}
+			return probe(ctx, instance)
 		}
 	}
 	return fmt.Errorf("service '%s(%s)' health check discover '%s' not found",
@@ -188,3 +188,43 @@ func (app APP) ResourceKey(resource, key string, opts ...Option) string {
 	bufPool.Put(buf)
 	return s
 }
+
+// ConfigScopePaths returns the path segments of a configuration scope below /{app}/configs,
+// e.g. [service {service} {region}] for config.ScopeRegion.
+// It is shared by the remote sources writing into hierarchical prefixes such as etcd and consul.
+func (app APP) ConfigScopePaths(scope string) ([]string, error) {
+	switch scope {
+	case config.ScopeGlobal, "":
+		return nil, nil
+	case config.ScopeEnv:
+		return []string{app.Environment}, nil
+	case config.ScopeService:
+		return []string{"service", app.Instance.Name}, nil
+	case config.ScopeRegion:
+		return []string{"service", app.Instance.Name, app.Region}, nil
+	case config.ScopeAZ:
+		return []string{"service", app.Instance.Name, app.Region, app.AZ}, nil
+	case config.ScopeEnvService:
+		return []string{app.Environment, "service", app.Instance.Name}, nil
+	case config.ScopeEnvRegion:
+		return []string{app.Environment, "service", app.Instance.Name, app.Region}, nil
+	case config.ScopeEnvAZ:
+		return []string{app.Environment, "service", app.Instance.Name, app.Region, app.AZ}, nil
+	case config.ScopeGroup:
+		return []string{"service", app.Instance.Group}, nil
+	case config.ScopeGroupRegion:
+		return []string{"service", app.Instance.Group, app.Region}, nil
+	case config.ScopeGroupAZ:
+		return []string{"service", app.Instance.Group, app.Region, app.AZ}, nil
+	case config.ScopeEnvGroup:
+		return []string{app.Environment, "service", app.Instance.Group}, nil
+	case config.ScopeEnvGroupRegion:
+		return []string{app.Environment, "service", app.Instance.Group, app.Region}, nil
+	case config.ScopeEnvGroupAZ:
+		return []string{app.Environment, "service", app.Instance.Group, app.Region, app.AZ}, nil
+	case config.ScopeInstance:
+		return []string{"runtime", app.Instance.ID}, nil
+	default:
+		return nil, fmt.Errorf("unsupported config scope '%s'", scope)
+	}
+}
@@ -63,11 +63,14 @@ func AddInterceptor(name string, newInterceptor NewServerInterceptor, supportPro
 	nsm.Unlock()
 }
 
-// getServerInterceptors retrieves and initializes the interceptors configured for a protocol.
-// It merges protocol-specific interceptors with global ones based on the server configuration.
-func getServerInterceptors(protocol string) ([]UnaryServerInterceptor, error) {
+// getServerInterceptors retrieves and initializes the interceptors named for a protocol.
+// It merges protocol-specific interceptors with global ones, the instances in
+// existing are reused instead of being created again.
+// The interceptors are returned in order and by name.
+func getServerInterceptors(protocol string, names []string, existing map[string]ServerInterceptor) ([]ServerInterceptor, map[string]ServerInterceptor, error) {
 	logger.Debug("get server intereptors", "protocol", protocol)
-	var interceptors []UnaryServerInterceptor
+	var interceptors []ServerInterceptor
+	named := make(map[string]ServerInterceptor, len(names))
 	nsm.RLock()
 	defer nsm.RUnlock()
 
@@ -76,27 +79,31 @@ func getServerInterceptors(protocol string) ([]UnaryServerInterceptor, error) {
 	maps.Copy(newInterceptors, newServerInterceptors[protocol])
 	maps.Copy(newInterceptors, newServerInterceptors[constant.AllProtocol])
 
-	conf := GetConfigWithProtocol(protocol)
 	// Build the slice based on the order defined in the configuration.
-	for _, interceptorName := range conf.Interceptors {
+	for _, interceptorName := range names {
+		if _, ok := named[interceptorName]; ok {
+			continue
+		}
+		if interceptor, ok := existing[interceptorName]; ok {
+			interceptors = append(interceptors, interceptor)
+			named[interceptorName] = interceptor
+			continue
+		}
 		if newInerceptor, ok := newInterceptors[interceptorName]; ok {
 			interceptor, err := newInerceptor()
 			if err != nil {
-				return interceptors, err
+				return interceptors, named, err
 			}
-			interceptors = append(interceptors, interceptor.Interceptor())
+			interceptors = append(interceptors, interceptor)
+			named[interceptorName] = interceptor
 		}
 	}
-	return interceptors, nil
+	return interceptors, named, nil
 }
 
 // getChainUnaryInterceptors converts a slice of interceptors into a single
 // chained interceptor.
-func getChainUnaryInterceptors(protocol string) (UnaryServerInterceptor, error) {
-	interceptors, err := getServerInterceptors(protocol)
-	if err != nil {
-		return nil, err
-	}
+func getChainUnaryInterceptors(interceptors []UnaryServerInterceptor) UnaryServerInterceptor {
 	var chainedInt UnaryServerInterceptor
 	if len(interceptors) == 0 {
 		chainedInt = nil
@@ -106,7 +113,7 @@ func getChainUnaryInterceptors(protocol string) (UnaryServerInterceptor, error)
 		// More than one? Chain them together recursively.
 		chainedInt = chainUnaryInterceptors(interceptors)
 	}
-	return chainedInt, nil
+	return chainedInt
 }
 
 // chainUnaryInterceptors kicks off the recursive wrapping of interceptors.
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
+)
+
+// closeCheckInterval is how often the requests of a replaced chain are checked
+// before its removed interceptors are closed.
+const closeCheckInterval = 100 * time.Millisecond
+
+// closeTimeout bounds the wait for the requests of a replaced chain,
+// e.g., a long-lived stream, the removed interceptors are closed after it anyway.
+var closeTimeout = time.Minute
+
+// interceptorChain is the unary and stream chain built from the interceptors of a protocol.
+type interceptorChain struct {
+	// names is the configured interceptor list the chain is built from.
+	names []string
+	// interceptors stores the instances of the chain by name.
+	interceptors map[string]ServerInterceptor
+	unary        UnaryServerInterceptor
+	stream       StreamServerInterceptor
+	// inflight counts the requests executing the chain.
+	inflight atomic.Int64
+}
+
+// protocolInterceptors holds the interceptor chain of a protocol.
+// The chain is rebuilt when the interceptor list of the protocol changes,
+// requests already started keep the chain they started with.
+//
+// Interceptors removed from the list are closed if they implement io.Closer,
+// after every request executing them has finished.
+type protocolInterceptors struct {
+	protocol string
+	chain    atomic.Pointer[interceptorChain]
+	// cm serializes the rebuilds of the chain.
+	cm sync.Mutex
+}
+
+// newProtocolInterceptors builds the interceptor chain of a protocol and watches its configuration.
+func newProtocolInterceptors(protocol string) (*protocolInterceptors, error) {
+	p := &protocolInterceptors{protocol: protocol}
+	if err := p.loadAndWatch(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Interceptor returns the unary interceptor executing the current chain.
+func (p *protocolInterceptors) Interceptor() UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *UnaryServerInfo, handler UnaryHandler) (any, error) {
+		chain := p.acquire()
+		defer chain.inflight.Add(-1)
+		if chain.unary == nil {
+			return handler(ctx, req)
+		}
+		return chain.unary(ctx, req, info, handler)
+	}
+}
+
+// StreamInterceptor returns the stream interceptor executing the current chain.
+func (p *protocolInterceptors) StreamInterceptor() StreamServerInterceptor {
+	return func(srv any, ss ServerStream, info *StreamServerInfo, handler StreamHandler) error {
+		chain := p.acquire()
+		defer chain.inflight.Add(-1)
+		if chain.stream == nil {
+			return handler(srv, ss)
+		}
+		return chain.stream(srv, ss, info, handler)
+	}
+}
+
+// acquire returns the current chain with the request counted in its inflight requests.
+// The chain is loaded again if it was replaced before the request was counted,
+// otherwise closeRemoved could close its interceptors while the request executes them.
+func (p *protocolInterceptors) acquire() *interceptorChain {
+	for {
+		chain := p.chain.Load()
+		chain.inflight.Add(1)
+		if p.chain.Load() == chain {
+			return chain
+		}
+		chain.inflight.Add(-1)
+	}
+}
+
+func (p *protocolInterceptors) loadAndWatch() error {
+	if err := p.load(); err != nil {
+		return err
+	}
+	config.AddPrefixListener(constant.ConfigServerPrefix, p.watch)
+	return nil
+}
+
+// load rebuilds the chain if the interceptor list changed.
+func (p *protocolInterceptors) load() error {
+	p.cm.Lock()
+	defer p.cm.Unlock()
+	names := []string(GetConfigWithProtocol(p.protocol).Interceptors)
+	current := p.chain.Load()
+	var existing map[string]ServerInterceptor
+	if current != nil {
+		if slices.Equal(current.names, names) {
+			return nil
+		}
+		existing = current.interceptors
+	}
+
+	interceptors, named, err := getServerInterceptors(p.protocol, names, existing)
+	if err != nil {
+		// Release the interceptors created for the failed chain.
+		for name, interceptor := range named {
+			if _, ok := existing[name]; !ok {
+				closeInterceptor(p.protocol, interceptor)
+			}
+		}
+		return err
+	}
+
+	chain := &interceptorChain{
+		names:        names,
+		interceptors: named,
+	}
+	unaryInterceptors := make([]UnaryServerInterceptor, 0, len(interceptors))
+	streamInterceptors := make([]StreamServerInterceptor, 0, len(interceptors))
+	for _, interceptor := range interceptors {
+		unaryInterceptors = append(unaryInterceptors, interceptor.Interceptor())
+		if si, ok := interceptor.(ServerStreamInterceptor); ok {
+			streamInterceptors = append(streamInterceptors, si.StreamInterceptor())
+		}
+	}
+	chain.unary = getChainUnaryInterceptors(unaryInterceptors)
+	chain.stream = chainStreamInterceptors(streamInterceptors)
+	p.chain.Store(chain)
+
+	if current != nil {
+		logger.Info("server interceptors changed", "protocol", p.protocol, "old", current.names, "new", names)
+		go p.closeRemoved(current, chain)
+	}
+	return nil
+}
+
+func (p *protocolInterceptors) watch(event *config.Event) {
+	if err := p.load(); err != nil {
+		logger.Error("server interceptors load config fail", "protocol", p.protocol, "err", err)
+	}
+}
+
+// closeRemoved closes the interceptors of old missing in current,
+// once the requests executing old have finished or closeTimeout has elapsed.
+func (p *protocolInterceptors) closeRemoved(old, current *interceptorChain) {
+	deadline := time.Now().Add(closeTimeout)
+	for old.inflight.Load() > 0 {
+		if time.Now().After(deadline) {
+			logger.Warn("close removed server interceptors with requests in flight",
+				"protocol", p.protocol, "requests", old.inflight.Load())
+			break
+		}
+		time.Sleep(closeCheckInterval)
+	}
+	for name, interceptor := range old.interceptors {
+		if _, ok := current.interceptors[name]; !ok {
+			closeInterceptor(p.protocol, interceptor)
+		}
+	}
+}
+
+// closeInterceptor releases an interceptor implementing io.Closer.
+func closeInterceptor(protocol string, interceptor ServerInterceptor) {
+	closer, ok := interceptor.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		logger.Error("close server interceptor fail", "protocol", protocol, "interceptor", interceptor.Name(), "err", err)
+	}
+}
@@ -7,6 +7,13 @@ type ServerOptions struct {
 	// Interceptor is the primary entry point for the server's middleware pipeline.
 	// It usually contains a "chained" interceptor that wraps multiple individual
 	// interceptors (logging, metrics, auth, etc.) into a single execution flow.
+	// The chain built by Init follows the configuration, every request executes
+	// the chain configured when it starts.
 	// If nil, the server will execute the business logic directly without middleware.
 	Interceptor UnaryServerInterceptor
+
+	// StreamInterceptor is the middleware pipeline for streaming RPCs.
+	// It only contains interceptors implementing ServerStreamInterceptor.
+	// If nil, streaming handlers are executed directly.
+	StreamInterceptor StreamServerInterceptor
 }
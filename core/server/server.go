@@ -32,19 +32,21 @@ var (
 )
 
 // Init initializes all servers that have been registered with the framework.
-// For each protocol, it constructs the interceptor chain and passes it to the server factory.
+// For each protocol, it constructs the unary and stream interceptor chains and passes it to the server factory.
+// The chains are rebuilt when the interceptors of the protocol change, without restarting the server.
 func Init() ([]Server, error) {
 	var servers []Server
 	for protocol, newServer := range newServerFuncs {
-		// 1. Generate the combined middleware chain for this specific protocol.
-		interceptor, err := getChainUnaryInterceptors(protocol)
+		// 1. Generate the combined middleware chains for this specific protocol.
+		interceptors, err := newProtocolInterceptors(protocol)
 		if err != nil {
 			return servers, err
 		}
 
 		// 2. Instantiate the server using its factory function and the generated options.
 		server, err := newServer(&ServerOptions{
-			Interceptor: interceptor,
+			Interceptor:       interceptors.Interceptor(),
+			StreamInterceptor: interceptors.StreamInterceptor(),
 		})
 		if err != nil {
 			return servers, err
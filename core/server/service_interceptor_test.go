@@ -3,9 +3,14 @@ package server
 import (
 	"context"
 	"errors"
+	"slices"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/asjard/asjard/core/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -65,3 +70,129 @@ func TestChainUnaryInterceptors(t *testing.T) {
 	})
 	require.ErrorIs(t, err, wantErr)
 }
+
+type testServerStream struct{ ctx context.Context }
+
+func (s testServerStream) Context() context.Context { return s.ctx }
+func (testServerStream) SendMsg(any) error          { return nil }
+func (testServerStream) RecvMsg(any) error          { return nil }
+
+type ctxKey struct{}
+
+func TestChainStreamInterceptors(t *testing.T) {
+	require.Nil(t, chainStreamInterceptors(nil))
+
+	var calls []string
+	makeInterceptor := func(name string) StreamServerInterceptor {
+		return func(srv any, ss ServerStream, info *StreamServerInfo, next StreamHandler) error {
+			calls = append(calls, name+"-before")
+			err := next(srv, WrapServerStream(ss, context.WithValue(ss.Context(), ctxKey{}, name)))
+			calls = append(calls, name+"-after")
+			return err
+		}
+	}
+	chain := chainStreamInterceptors([]StreamServerInterceptor{makeInterceptor("one"), makeInterceptor("two")})
+	err := chain(nil, testServerStream{ctx: context.Background()}, &StreamServerInfo{}, func(_ any, ss ServerStream) error {
+		calls = append(calls, "handler")
+		require.Equal(t, "two", ss.Context().Value(ctxKey{}))
+		require.IsType(t, testServerStream{}, ss.(*WrappedServerStream).ServerStream)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"one-before", "two-before", "handler", "two-after", "one-after"}, calls)
+}
+
+type testChainInterceptor struct {
+	name   string
+	calls  *[]string
+	block  chan struct{}
+	closed atomic.Bool
+}
+
+func (i *testChainInterceptor) Name() string { return i.name }
+
+func (i *testChainInterceptor) Interceptor() UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *UnaryServerInfo, next UnaryHandler) (any, error) {
+		*i.calls = append(*i.calls, i.name)
+		if req == "block" && i.block != nil {
+			<-i.block
+		}
+		return next(ctx, req)
+	}
+}
+
+func (i *testChainInterceptor) Close() error {
+	i.closed.Store(true)
+	return nil
+}
+
+func TestProtocolInterceptorsReload(t *testing.T) {
+	// The listeners of a protocol live as long as the process, use a new protocol every run.
+	protocol := "testReload" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	var calls []string
+	one := &testChainInterceptor{name: "testReloadOne", calls: &calls}
+	two := &testChainInterceptor{name: "testReloadTwo", calls: &calls, block: make(chan struct{})}
+	AddInterceptor(one.name, func() (ServerInterceptor, error) { return one, nil }, protocol)
+	AddInterceptor(two.name, func() (ServerInterceptor, error) { return two, nil }, protocol)
+	require.NoError(t, config.Set("asjard.servers."+protocol+".interceptors", one.name+","+two.name))
+	require.Eventually(t, func() bool {
+		return len(GetConfigWithProtocol(protocol).Interceptors) > 0
+	}, 3*time.Second, 20*time.Millisecond)
+
+	interceptors, err := newProtocolInterceptors(protocol)
+	require.NoError(t, err)
+	interceptor := interceptors.Interceptor()
+	handler := func(context.Context, any) (any, error) { return nil, nil }
+
+	_, err = interceptor(context.Background(), "request", &UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	require.Equal(t, []string{one.name, two.name}, calls)
+
+	// Keep a request in the old chain.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		interceptor(context.Background(), "block", &UnaryServerInfo{}, handler)
+	}()
+	require.Eventually(t, func() bool { return interceptors.chain.Load().inflight.Load() == 1 }, 3*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, config.Set("asjard.servers."+protocol+".interceptors", one.name))
+	require.Eventually(t, func() bool {
+		return !slices.Contains(interceptors.chain.Load().names, two.name)
+	}, 3*time.Second, 20*time.Millisecond)
+	// The removed interceptor is not closed while the old chain is executing.
+	time.Sleep(2 * closeCheckInterval)
+	require.False(t, two.closed.Load())
+
+	close(two.block)
+	<-done
+	require.Eventually(t, two.closed.Load, 3*time.Second, 20*time.Millisecond)
+	require.False(t, one.closed.Load())
+
+	calls = nil
+	_, err = interceptor(context.Background(), "request", &UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	require.Equal(t, []string{one.name}, calls)
+}
+
+func TestProtocolInterceptorsCloseTimeout(t *testing.T) {
+	timeout := closeTimeout
+	closeTimeout = 2 * closeCheckInterval
+	defer func() { closeTimeout = timeout }()
+
+	removed := &testChainInterceptor{name: "testCloseTimeout"}
+	old := &interceptorChain{interceptors: map[string]ServerInterceptor{removed.name: removed}}
+	// A long-lived stream never leaves the old chain.
+	old.inflight.Add(1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		(&protocolInterceptors{protocol: "testCloseTimeout"}).closeRemoved(old, &interceptorChain{})
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("removed interceptors not closed after the timeout")
+	}
+	require.True(t, removed.closed.Load())
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+)
+
+// StreamServerInfo contains metadata about a streaming RPC call.
+// This is passed to stream interceptors to provide context about the server and method being called.
+type StreamServerInfo struct {
+	// Server is the underlying service implementation.
+	Server any
+	// FullMethod is the path to the RPC (e.g., "/user.UserService/Watch").
+	FullMethod string
+	// Protocol identifies the transport (e.g., "grpc").
+	Protocol string
+	// IsClientStream indicates whether the client sends a stream of messages.
+	IsClientStream bool
+	// IsServerStream indicates whether the server sends a stream of messages.
+	IsServerStream bool
+}
+
+// ServerStream is the protocol independent view of a server side stream.
+// It is a subset of grpc.ServerStream so that gRPC streams can be passed through as is.
+type ServerStream interface {
+	// Context returns the context of this stream.
+	Context() context.Context
+	// SendMsg sends a message to the client.
+	SendMsg(m any) error
+	// RecvMsg blocks until it receives a message from the client or the stream is done.
+	RecvMsg(m any) error
+}
+
+// StreamHandler is the signature of the final business logic of a streaming RPC.
+type StreamHandler func(srv any, stream ServerStream) error
+
+// StreamServerInterceptor is a middleware function that wraps the execution of a streaming RPC.
+// It can replace the stream (e.g., to enrich its context) before passing it to the handler.
+type StreamServerInterceptor func(srv any, ss ServerStream, info *StreamServerInfo, handler StreamHandler) error
+
+// ServerStreamInterceptor is an optional interface a ServerInterceptor can implement
+// to also take part in streaming RPCs.
+// Interceptors that do not implement it are skipped for streaming methods.
+type ServerStreamInterceptor interface {
+	// StreamInterceptor returns the function that wraps streaming RPCs.
+	StreamInterceptor() StreamServerInterceptor
+}
+
+// WrappedServerStream overrides the context of a ServerStream.
+// Interceptors use it to pass an enriched context to the next step in the chain.
+type WrappedServerStream struct {
+	ServerStream
+	// Ctx is the context returned by Context().
+	Ctx context.Context
+}
+
+// WrapServerStream returns a ServerStream whose Context() returns ctx.
+func WrapServerStream(ss ServerStream, ctx context.Context) *WrappedServerStream {
+	if ws, ok := ss.(*WrappedServerStream); ok {
+		return &WrappedServerStream{ServerStream: ws.ServerStream, Ctx: ctx}
+	}
+	return &WrappedServerStream{ServerStream: ss, Ctx: ctx}
+}
+
+// Context returns the overridden context.
+func (w *WrappedServerStream) Context() context.Context {
+	if w.Ctx == nil {
+		return w.ServerStream.Context()
+	}
+	return w.Ctx
+}
+
+// chainStreamInterceptors converts a slice of stream interceptors into a single chained interceptor.
+func chainStreamInterceptors(interceptors []StreamServerInterceptor) StreamServerInterceptor {
+	if len(interceptors) == 0 {
+		return nil
+	}
+	if len(interceptors) == 1 {
+		return interceptors[0]
+	}
+	return func(srv any, ss ServerStream, info *StreamServerInfo, handler StreamHandler) error {
+		return interceptors[0](srv, ss, info, getChainStreamHandler(interceptors, 0, info, handler))
+	}
+}
+
+// getChainStreamHandler returns a StreamHandler that, when called, executes the next interceptor in the slice.
+func getChainStreamHandler(interceptors []StreamServerInterceptor, curr int, info *StreamServerInfo, finalHandler StreamHandler) StreamHandler {
+	if curr == len(interceptors)-1 {
+		return finalHandler
+	}
+	return func(srv any, ss ServerStream) error {
+		return interceptors[curr+1](srv, ss, info, getChainStreamHandler(interceptors, curr+1, info, finalHandler))
+	}
+}
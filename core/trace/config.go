@@ -16,6 +16,8 @@ type Config struct {
 	// It must include the protocol scheme:
 	// Example: http://127.0.0.1:4318 (OTLP/HTTP)
 	// Example: grpc://127.0.0.1:4317 (OTLP/gRPC)
+	// Example: file://logs/trace.json (JSON lines, for offline debugging)
+	// Other schemes can be registered with AddExporter.
 	Endpoint string `json:"endpoint"`
 
 	// Timeout defines the maximum time allowed for an export request to complete.
@@ -28,13 +30,40 @@ type Config struct {
 	KeyFile string `json:"keyFile"`
 
 	// CaFile is the relative path to the Certificate Authority file to verify the collector.
-	CaFile string `json:"cafile"`
+	CaFile string `json:"caFile"`
+
+	// Sampler decides which spans are exported, it is reloaded on change.
+	Sampler SamplerConfig `json:"sampler"`
+}
+
+// SamplerConfig defines the sampling strategy.
+type SamplerConfig struct {
+	// Ratio is the fraction of the traces sampled, between 0 and 1.
+	Ratio float64 `json:"ratio"`
+	// ParentBased follows the sampling decision of the caller if present.
+	ParentBased bool `json:"parentBased"`
+	// AlwaysOnError exports the spans not sampled that end with an error.
+	AlwaysOnError bool `json:"alwaysOnError"`
+	// Methods overrides the ratio of specific endpoints.
+	Methods []*MethodSamplerConfig `json:"methods"`
+}
+
+// MethodSamplerConfig specifies the ratio of a gRPC or REST endpoint.
+type MethodSamplerConfig struct {
+	// Name is protocol://method, method or protocol,
+	// e.g., "grpc:///api.v1.server.Server/Hello", "/api.v1.server.Server/Hello" or "rest".
+	Name  string  `json:"name"`
+	Ratio float64 `json:"ratio"`
 }
 
 // defaultTraceConfig sets the baseline values for tracing,
-// ensuring a 1-second timeout if not explicitly configured.
+// ensuring a 1-second timeout and sampling every trace if not explicitly configured.
 var defaultTraceConfig = Config{
 	Timeout: utils.JSONDuration{Duration: time.Second},
+	Sampler: SamplerConfig{
+		Ratio:       1,
+		ParentBased: true,
+	},
 }
 
 // GetConfig retrieves the trace configuration from the global config system.
@@ -0,0 +1,167 @@
+package trace
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/asjard/asjard/utils"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+// NewExporterFunc creates a span exporter for an endpoint.
+type NewExporterFunc func(u *url.URL, conf *Config) (sdktrace.SpanExporter, error)
+
+var (
+	newExporters = map[string]NewExporterFunc{
+		"http":  newHTTPExporter,
+		"https": newHTTPExporter,
+		"grpc":  newGRPCExporter,
+		"file":  newFileExporter,
+	}
+	nem sync.RWMutex
+)
+
+// AddExporter registers the exporter of an endpoint scheme.
+func AddExporter(scheme string, newExporter NewExporterFunc) {
+	nem.Lock()
+	newExporters[scheme] = newExporter
+	nem.Unlock()
+}
+
+// newExporter creates the exporter of the endpoint scheme.
+// Spans are discarded if the endpoint is empty,
+// the trace ids are still propagated.
+func newExporter(conf *Config) (sdktrace.SpanExporter, error) {
+	if conf.Endpoint == "" {
+		return stdouttrace.New(stdouttrace.WithWriter(io.Discard))
+	}
+	u, err := url.Parse(conf.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	nem.RLock()
+	newExporter, ok := newExporters[u.Scheme]
+	nem.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("trace exporter '%s' not found", u.Scheme)
+	}
+	return newExporter(u, conf)
+}
+
+// newHTTPExporter exports spans with OTLP/HTTP.
+func newHTTPExporter(u *url.URL, conf *Config) (sdktrace.SpanExporter, error) {
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host)}
+	if u.Path != "" {
+		options = append(options, otlptracehttp.WithURLPath(u.Path))
+	}
+	if conf.Timeout.Duration != 0 {
+		options = append(options, otlptracehttp.WithTimeout(conf.Timeout.Duration))
+	}
+	tlsConfig, err := conf.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case tlsConfig != nil:
+		options = append(options, otlptracehttp.WithTLSClientConfig(tlsConfig))
+	case u.Scheme == "http":
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.New(context.Background(), options...)
+}
+
+// newGRPCExporter exports spans with OTLP/gRPC.
+func newGRPCExporter(u *url.URL, conf *Config) (sdktrace.SpanExporter, error) {
+	options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(u.Host)}
+	if conf.Timeout.Duration != 0 {
+		options = append(options, otlptracegrpc.WithTimeout(conf.Timeout.Duration))
+	}
+	tlsConfig, err := conf.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		options = append(options, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+	return otlptracegrpc.New(context.Background(), options...)
+}
+
+// fileExporter writes spans to a file as JSON lines.
+type fileExporter struct {
+	sdktrace.SpanExporter
+	file *os.File
+}
+
+// newFileExporter writes spans to the file of the endpoint,
+// file://relative/path.json is relative to the working directory,
+// file:///absolute/path.json is an absolute path.
+func newFileExporter(u *url.URL, _ *Config) (sdktrace.SpanExporter, error) {
+	path := u.Host + u.Path
+	if path == "" {
+		return nil, fmt.Errorf("trace file exporter path is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &fileExporter{SpanExporter: exporter, file: file}, nil
+}
+
+// Shutdown flushes the exporter and closes the file.
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	if err := e.SpanExporter.Shutdown(ctx); err != nil {
+		return err
+	}
+	return e.file.Close()
+}
+
+// tlsConfig returns the TLS configuration of the exporter,
+// the files are relative to the certificate directory.
+// It returns nil if no file is configured.
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	if c.CaFile == "" && c.CertFile == "" && c.KeyFile == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{}
+	if c.CaFile != "" {
+		ca, err := os.ReadFile(filepath.Join(utils.GetCertDir(), c.CaFile))
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("trace caFile '%s' has no valid certificate", c.CaFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(filepath.Join(utils.GetCertDir(), c.CertFile),
+			filepath.Join(utils.GetCertDir(), c.KeyFile))
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,166 @@
+package trace
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/logger"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sampler decides which spans are exported.
+// Its configuration is reloaded when "asjard.trace.sampler" changes.
+type Sampler struct {
+	conf atomic.Pointer[samplerConfig]
+}
+
+// samplerConfig is the compiled form of SamplerConfig.
+type samplerConfig struct {
+	parentBased   bool
+	alwaysOnError bool
+	sampler       sdktrace.Sampler
+	// methods stores the samplers of the methods by protocol://method, method or protocol.
+	methods map[string]sdktrace.Sampler
+}
+
+var _ sdktrace.Sampler = &Sampler{}
+
+// NewSampler creates a sampler and watches its configuration.
+func NewSampler() (*Sampler, error) {
+	s := &Sampler{}
+	if err := s.loadAndWatch(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ShouldSample implements sdktrace.Sampler.
+//
+// If parentBased is enabled a span with a remote or local parent follows the decision of its parent.
+// Otherwise the ratio of the most specific method is used.
+// Spans not sampled are still recorded if alwaysOnError is enabled,
+// so the error processor can export them when they end with an error.
+func (s *Sampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	conf := s.conf.Load()
+	psc := trace.SpanContextFromContext(p.ParentContext)
+
+	var result sdktrace.SamplingResult
+	if conf.parentBased && psc.IsValid() {
+		result = sdktrace.SamplingResult{
+			Decision:   sdktrace.Drop,
+			Tracestate: psc.TraceState(),
+		}
+		if psc.IsSampled() {
+			result.Decision = sdktrace.RecordAndSample
+		}
+	} else {
+		result = conf.getSampler(p.Name).ShouldSample(p)
+	}
+
+	if result.Decision == sdktrace.Drop && conf.alwaysOnError {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+// Description implements sdktrace.Sampler.
+func (s *Sampler) Description() string {
+	return "AsjardSampler"
+}
+
+func (s *Sampler) loadAndWatch() error {
+	if err := s.load(); err != nil {
+		return err
+	}
+	config.AddPrefixListener("asjard.trace.sampler", s.watch)
+	return nil
+}
+
+func (s *Sampler) load() error {
+	conf := defaultTraceConfig.Sampler
+	if err := config.GetWithUnmarshal("asjard.trace.sampler", &conf); err != nil {
+		return err
+	}
+	compiled := &samplerConfig{
+		parentBased:   conf.ParentBased,
+		alwaysOnError: conf.AlwaysOnError,
+		sampler:       newRatioSampler(conf.Ratio),
+		methods:       make(map[string]sdktrace.Sampler, len(conf.Methods)),
+	}
+	for _, method := range conf.Methods {
+		if method.Name == "" {
+			continue
+		}
+		compiled.methods[method.Name] = newRatioSampler(method.Ratio)
+	}
+	s.conf.Store(compiled)
+	return nil
+}
+
+func (s *Sampler) watch(event *config.Event) {
+	if err := s.load(); err != nil {
+		logger.Error("trace sampler load config fail", "err", err)
+	}
+}
+
+// getSampler returns the sampler of a span name formatted as {protocol}://{method}.
+// Priorities: protocol://method > method > protocol > global.
+func (c *samplerConfig) getSampler(name string) sdktrace.Sampler {
+	if sampler, ok := c.methods[name]; ok {
+		return sampler
+	}
+	protocol, method, ok := strings.Cut(name, "://")
+	if ok {
+		if sampler, ok := c.methods[method]; ok {
+			return sampler
+		}
+		if sampler, ok := c.methods[protocol]; ok {
+			return sampler
+		}
+	}
+	return c.sampler
+}
+
+func newRatioSampler(ratio float64) sdktrace.Sampler {
+	switch {
+	case ratio >= 1:
+		return sdktrace.AlwaysSample()
+	case ratio <= 0:
+		return sdktrace.NeverSample()
+	default:
+		return sdktrace.TraceIDRatioBased(ratio)
+	}
+}
+
+// errorSpanProcessor exports the spans recorded but not sampled when they end with an error.
+// The batch processor ignores spans not sampled, so they are forwarded with the sampled flag set.
+type errorSpanProcessor struct {
+	sdktrace.SpanProcessor
+}
+
+func newErrorSpanProcessor(next sdktrace.SpanProcessor) sdktrace.SpanProcessor {
+	return &errorSpanProcessor{SpanProcessor: next}
+}
+
+// OnEnd forwards the sampled spans and the failed spans.
+func (p *errorSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.SpanProcessor.OnEnd(s)
+		return
+	}
+	if s.Status().Code == codes.Error {
+		p.SpanProcessor.OnEnd(sampledSpan{ReadOnlySpan: s})
+	}
+}
+
+// sampledSpan marks a span as sampled.
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	return s.ReadOnlySpan.SpanContext().WithTraceFlags(trace.FlagsSampled)
+}
@@ -4,16 +4,9 @@
 package trace
 
 import (
-	"context"
-	"io"
-	"net/url"
-
 	"github.com/asjard/asjard/core/runtime"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
-	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -30,61 +23,32 @@ func Init() error {
 		return nil
 	}
 
-	// Parse the collector endpoint to determine the protocol (HTTP vs gRPC).
-	u, err := url.Parse(conf.Endpoint)
+	// Create the exporter of the endpoint scheme.
+	exporter, err := newExporter(conf)
 	if err != nil {
 		return err
 	}
 
-	var exporter sdktrace.SpanExporter
-	switch u.Scheme {
-	case "http", "https":
-		// Configure OTLP/HTTP exporter.
-		var options []otlptracehttp.Option
-		options = append(options, otlptracehttp.WithEndpoint(u.Host))
-		if u.Path != "" {
-			options = append(options, otlptracehttp.WithURLPath(u.Path))
-		}
-		if conf.Timeout.Duration != 0 {
-			options = append(options, otlptracehttp.WithTimeout(conf.Timeout.Duration))
-		}
-		// Fallback to insecure if TLS certificates are not provided.
-		if conf.KeyFile == "" || conf.CaFile == "" || conf.CertFile == "" {
-			options = append(options, otlptracehttp.WithInsecure())
-		}
-		exporter, err = otlptracehttp.New(context.Background(), options...)
-
-	case "grpc":
-		// Configure OTLP/gRPC exporter.
-		var options []otlptracegrpc.Option
-		options = append(options, otlptracegrpc.WithEndpoint(u.Host))
-		if conf.Timeout.Duration != 0 {
-			options = append(options, otlptracegrpc.WithTimeout(conf.Timeout.Duration))
-		}
-		if conf.KeyFile == "" || conf.CaFile == "" || conf.CertFile == "" {
-			options = append(options, otlptracegrpc.WithInsecure())
-		}
-		exporter, err = otlptracegrpc.New(context.Background(), options...)
-
-	default:
-		// Fallback exporter that discards data if the scheme is unrecognized.
-		exporter, err = stdouttrace.New(stdouttrace.WithWriter(io.Discard))
-	}
-
+	sampler, err := NewSampler()
 	if err != nil {
 		return err
 	}
 
+	// Buffer spans and send them in batches for better performance.
+	// Spans recorded but not sampled only exist if alwaysOnError is enabled,
+	// the processor is always wrapped so it can be switched on the fly.
+	processor := newErrorSpanProcessor(sdktrace.NewBatchSpanProcessor(exporter))
+
 	// Fetch current application metadata from the runtime package.
 	app := runtime.GetAPP()
 
 	// Initialize the TracerProvider.
-	// 1. WithSampler(AlwaysSample): Capture 100% of traces.
-	// 2. WithBatcher: Buffer spans and send them in batches for better performance.
+	// 1. WithSampler: Capture the configured fraction of traces.
+	// 2. WithSpanProcessor: Export the sampled spans, and the failed ones if alwaysOnError is enabled.
 	// 3. WithResource: Attach global attributes (App, Region, Env) to every span.
 	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithSpanProcessor(processor),
 		sdktrace.WithResource(resource.NewWithAttributes(
 			semconv.SchemaURL,
 			semconv.ServiceNameKey.String(app.Instance.Name),
@@ -1,11 +1,21 @@
 package trace
 
 import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/asjard/asjard/core/config"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestOTLPHTTPInitializationIntegration(t *testing.T) {
@@ -14,3 +24,107 @@ func TestOTLPHTTPInitializationIntegration(t *testing.T) {
 	require.Eventually(t, func() bool { return GetConfig().Enabled }, 3*time.Second, 20*time.Millisecond)
 	require.NoError(t, Init())
 }
+
+func TestSampler(t *testing.T) {
+	require.NoError(t, config.Set("asjard.trace.sampler.ratio", 0))
+	require.NoError(t, config.Set("asjard.trace.sampler.methods[0].name", "/api.v1.Server/Hello"))
+	require.NoError(t, config.Set("asjard.trace.sampler.methods[0].ratio", 1))
+	require.NoError(t, config.Set("asjard.trace.sampler.methods[1].name", "rest"))
+	require.NoError(t, config.Set("asjard.trace.sampler.methods[1].ratio", 1))
+	require.NoError(t, config.Set("asjard.trace.sampler.alwaysOnError", false))
+	require.Eventually(t, func() bool {
+		conf := GetConfig().Sampler
+		return conf.Ratio == 0 && !conf.AlwaysOnError && len(conf.Methods) == 2
+	}, 3*time.Second, 20*time.Millisecond)
+	sampler, err := NewSampler()
+	require.NoError(t, err)
+
+	params := func(name string) sdktrace.SamplingParameters {
+		return sdktrace.SamplingParameters{
+			ParentContext: context.Background(),
+			TraceID:       trace.TraceID{1},
+			Name:          name,
+		}
+	}
+	require.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(params("grpc:///api.v1.Server/Hello")).Decision)
+	require.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(params("rest:///api.v1.Server/Say")).Decision)
+	require.Equal(t, sdktrace.Drop, sampler.ShouldSample(params("grpc:///api.v1.Server/Say")).Decision)
+
+	t.Run("parentBased", func(t *testing.T) {
+		parent := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{1},
+			TraceFlags: trace.FlagsSampled,
+			Remote:     true,
+		})
+		p := params("grpc:///api.v1.Server/Say")
+		p.ParentContext = trace.ContextWithRemoteSpanContext(context.Background(), parent)
+		require.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(p).Decision)
+	})
+
+	t.Run("hotReload", func(t *testing.T) {
+		require.NoError(t, config.Set("asjard.trace.sampler.alwaysOnError", true))
+		require.Eventually(t, func() bool {
+			return sampler.ShouldSample(params("grpc:///api.v1.Server/Say")).Decision == sdktrace.RecordOnly
+		}, 3*time.Second, 20*time.Millisecond)
+		require.NoError(t, config.Set("asjard.trace.sampler.ratio", 1))
+		require.Eventually(t, func() bool {
+			return sampler.ShouldSample(params("grpc:///api.v1.Server/Say")).Decision == sdktrace.RecordAndSample
+		}, 3*time.Second, 20*time.Millisecond)
+		require.NoError(t, config.Set("asjard.trace.sampler.ratio", 0))
+	})
+}
+
+func TestFileExporterAndErrorSpans(t *testing.T) {
+	require.NoError(t, config.Set("asjard.trace.sampler.ratio", 0))
+	require.NoError(t, config.Set("asjard.trace.sampler.alwaysOnError", true))
+	require.Eventually(t, func() bool {
+		conf := GetConfig().Sampler
+		return conf.Ratio == 0 && conf.AlwaysOnError
+	}, 3*time.Second, 20*time.Millisecond)
+	sampler, err := NewSampler()
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "trace.json")
+	exporter, err := newExporter(&Config{Endpoint: "file://" + file})
+	require.NoError(t, err)
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithSpanProcessor(newErrorSpanProcessor(sdktrace.NewSimpleSpanProcessor(exporter))),
+	)
+	tracer := tp.Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "grpc:///api.v1.Server/Ok")
+	span.End()
+	_, span = tracer.Start(context.Background(), "grpc:///api.v1.Server/Fail")
+	span.SetStatus(codes.Error, "fail")
+	span.End()
+	require.NoError(t, tp.Shutdown(context.Background()))
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 1)
+	var exported struct {
+		Name        string
+		SpanContext struct{ TraceFlags string }
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &exported))
+	require.Equal(t, "grpc:///api.v1.Server/Fail", exported.Name)
+	require.Equal(t, "01", exported.SpanContext.TraceFlags)
+}
+
+func TestNewExporter(t *testing.T) {
+	_, err := newExporter(&Config{Endpoint: "unknown://127.0.0.1"})
+	require.Error(t, err)
+
+	_, err = newExporter(&Config{Endpoint: "https://127.0.0.1:4318", CaFile: "not-exist-ca.pem"})
+	require.Error(t, err)
+
+	AddExporter("custom", func(u *url.URL, conf *Config) (sdktrace.SpanExporter, error) {
+		return tracetest.NewInMemoryExporter(), nil
+	})
+	exporter, err := newExporter(&Config{Endpoint: "custom://"})
+	require.NoError(t, err)
+	require.IsType(t, &tracetest.InMemoryExporter{}, exporter)
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/security"
+	"github.com/asjard/asjard/utils"
+)
+
+const (
+	// APIKeyAuthenticatorName is the name of the static API key authenticator.
+	APIKeyAuthenticatorName = "apiKey"
+)
+
+// APIKeyConfig defines the static API keys.
+type APIKeyConfig struct {
+	// Header is the request header or metadata carrying the key.
+	Header string `json:"header"`
+	// Keys are the accepted keys.
+	Keys []*APIKeyEntryConfig `json:"keys"`
+}
+
+// APIKeyEntryConfig defines an API key.
+type APIKeyEntryConfig struct {
+	// Subject is the subject of the principal authenticated by the key.
+	Subject string `json:"subject"`
+	// Key is the API key encrypted with security, encrypted_{cipher}:{ciphertext},
+	// plaintext keys are ignored.
+	Key string `json:"key"`
+	// Scopes are the scopes granted to the key.
+	Scopes utils.JSONStrings `json:"scopes"`
+	// Roles are the roles of the key.
+	Roles utils.JSONStrings `json:"roles"`
+}
+
+var defaultAPIKeyConfig = APIKeyConfig{
+	Header: "x-api-key",
+}
+
+// APIKeyAuthenticator authenticates the requests carrying a static API key.
+type APIKeyAuthenticator struct {
+	name string
+	conf *config.Binding[APIKeyConfig]
+	keys atomic.Pointer[[]*apiKey]
+}
+
+// apiKey is a decrypted API key.
+type apiKey struct {
+	subject string
+	scopes  []string
+	roles   []string
+	// digest is the sha256 digest of the key, compared in constant time.
+	digest [sha256.Size]byte
+}
+
+func init() {
+	AddAuthenticator(APIKeyAuthenticatorName, NewAPIKeyAuthenticator)
+}
+
+// NewAPIKeyAuthenticator creates the API key authenticator configured in asjard.auth.{name}.
+// The keys are decrypted when the configuration is loaded and when it changes.
+func NewAPIKeyAuthenticator(name string) (Authenticator, error) {
+	conf, _, err := config.Bind(fmt.Sprintf(constant.ConfigAuthWithNamePrefix, name), defaultAPIKeyConfig,
+		config.WithDisableAutoDecryptValue())
+	if err != nil {
+		return nil, err
+	}
+	a := &APIKeyAuthenticator{
+		name: name,
+		conf: conf,
+	}
+	a.loadKeys(conf.Load())
+	conf.OnChange(func(_, new *APIKeyConfig) {
+		a.loadKeys(new)
+	})
+	return a, nil
+}
+
+// Authenticate checks the key of the request against every configured key.
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context) (*Principal, error) {
+	key := RequestMetadata(ctx, a.conf.Load().Header)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	digest := sha256.Sum256([]byte(key))
+	var matched *apiKey
+	for _, k := range *a.keys.Load() {
+		if subtle.ConstantTimeCompare(digest[:], k.digest[:]) == 1 && matched == nil {
+			matched = k
+		}
+	}
+	if matched == nil {
+		return nil, errors.New("invalid api key")
+	}
+	return &Principal{
+		Subject:       matched.subject,
+		Authenticator: a.name,
+		Scopes:        matched.scopes,
+		Roles:         matched.roles,
+	}, nil
+}
+
+// loadKeys decrypts the configured keys, the keys failing to decrypt are skipped.
+func (a *APIKeyAuthenticator) loadKeys(conf *APIKeyConfig) {
+	keys := make([]*apiKey, 0, len(conf.Keys))
+	for _, entry := range conf.Keys {
+		if entry == nil {
+			continue
+		}
+		key, err := decryptAPIKey(entry.Key)
+		if err != nil {
+			logger.Error("skip api key", "authenticator", a.name, "subject", entry.Subject, "err", err)
+			continue
+		}
+		keys = append(keys, &apiKey{
+			subject: entry.Subject,
+			scopes:  entry.Scopes,
+			roles:   entry.Roles,
+			digest:  sha256.Sum256([]byte(key)),
+		})
+	}
+	a.keys.Store(&keys)
+}
+
+// decryptAPIKey decrypts a key encrypted_{cipher}:{ciphertext}.
+func decryptAPIKey(value string) (string, error) {
+	encrypted, ok := strings.CutPrefix(value, config.ValueEncryptFlag)
+	if !ok {
+		return "", errors.New("api key is not encrypted")
+	}
+	cipherName, ciphertext, ok := strings.Cut(encrypted, config.ValueEncryptSplitSymbol)
+	if !ok || cipherName == "" {
+		return "", errors.New("api key has no cipher")
+	}
+	key, err := security.Decrypt(ciphertext, security.WithCipherName(cipherName))
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return "", errors.New("empty api key")
+	}
+	return key, nil
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/asjard/asjard/pkg/server/rest"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/metadata"
+)
+
+// ErrNoCredentials is returned by an authenticator if the request carries none of its credentials,
+// the next authenticator is tried.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Subject identifies the caller, e.g. the user ID or the name of the calling service.
+	Subject string
+	// Authenticator is the name of the authenticator which authenticated the caller.
+	Authenticator string
+	// Scopes are the permissions granted to the caller.
+	Scopes []string
+	// Roles are the roles of the caller, granted permissions by the authz policies.
+	Roles []string
+	// Claims are the attributes of the caller, e.g. the claims of a JWT.
+	Claims map[string]any
+	// Credentials are the request metadata the caller was authenticated with,
+	// forwarded to the downstream calls which authenticate the caller again.
+	// Credentials of the service itself, such as API keys and client certificates, are not forwarded.
+	Credentials map[string]string
+}
+
+// HasScopes reports whether the principal has all the scopes.
+func (p *Principal) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(p.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// Authenticator authenticates the caller of a request.
+type Authenticator interface {
+	// Authenticate returns the principal of the request,
+	// ErrNoCredentials if the request carries none of the credentials of the authenticator.
+	Authenticate(ctx context.Context) (*Principal, error)
+}
+
+// NewAuthenticatorFunc creates an authenticator configured in asjard.auth.{name}.
+type NewAuthenticatorFunc func(name string) (Authenticator, error)
+
+const (
+	// authenticatorMinBackoff and authenticatorMaxBackoff bound the time
+	// an authenticator failing to be created is not created again.
+	authenticatorMinBackoff = time.Second
+	authenticatorMaxBackoff = time.Minute
+)
+
+var (
+	newAuthenticators = make(map[string]NewAuthenticatorFunc)
+	nam               sync.RWMutex
+
+	authenticators = make(map[string]Authenticator)
+	// failures holds the last creation error of the authenticators until they are retried.
+	failures = make(map[string]*authenticatorFailure)
+	am       sync.RWMutex
+	// asf creates an authenticator once for the concurrent requests.
+	asf singleflight.Group
+)
+
+// authenticatorFailure is the creation error of an authenticator, returned until retryAt.
+type authenticatorFailure struct {
+	err      error
+	attempts int
+	retryAt  time.Time
+}
+
+// AddAuthenticator registers an authenticator.
+// This is typically called from an 'init' function.
+func AddAuthenticator(name string, newFunc NewAuthenticatorFunc) {
+	nam.Lock()
+	newAuthenticators[name] = newFunc
+	nam.Unlock()
+}
+
+// GetAuthenticator returns the named authenticator, creating it on first use.
+// An authenticator is created once for concurrent callers, outside of the lock of the others,
+// and a creation error is returned without creating it again for a growing backoff.
+func GetAuthenticator(name string) (Authenticator, error) {
+	am.RLock()
+	authenticator, ok := authenticators[name]
+	failure := failures[name]
+	am.RUnlock()
+	if ok {
+		return authenticator, nil
+	}
+	if failure != nil && time.Now().Before(failure.retryAt) {
+		return nil, failure.err
+	}
+	v, err, _ := asf.Do(name, func() (any, error) {
+		am.RLock()
+		authenticator, ok := authenticators[name]
+		am.RUnlock()
+		if ok {
+			return authenticator, nil
+		}
+		nam.RLock()
+		newFunc, ok := newAuthenticators[name]
+		nam.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("authenticator '%s' not found", name)
+		}
+		authenticator, err := newFunc(name)
+		am.Lock()
+		defer am.Unlock()
+		if err != nil {
+			failure, ok := failures[name]
+			if !ok {
+				failure = &authenticatorFailure{}
+				failures[name] = failure
+			}
+			failure.err = err
+			failure.attempts++
+			backoff := min(authenticatorMinBackoff<<min(failure.attempts-1, 10), authenticatorMaxBackoff)
+			failure.retryAt = time.Now().Add(backoff)
+			return nil, err
+		}
+		delete(failures, name)
+		authenticators[name] = authenticator
+		return authenticator, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(Authenticator), nil
+}
+
+type principalKey struct{}
+
+// NewContext returns a context carrying the principal.
+// The principal is attached to the rest context itself, as well as to its Context(),
+// so that it is propagated to the downstream calls made with either of them.
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	if rtx, ok := ctx.(*rest.Context); ok {
+		rtx.SetUserValue(principalKey{}, principal)
+		rtx.SetContext(context.WithValue(rtx.Context(), principalKey{}, principal))
+		return rtx
+	}
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal of the request.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// RequestMetadata reads a header of a rest request or the incoming metadata of other protocols.
+func RequestMetadata(ctx context.Context, key string) string {
+	if rtx, ok := ctx.(*rest.Context); ok {
+		if values := rtx.GetHeaderParam(key); len(values) != 0 {
+			return values[0]
+		}
+		return ""
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) != 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// MatchMethods reports whether one of the names selects the request,
+// a name is "*", a protocol, a full method or protocol://full method.
+func MatchMethods(names []string, protocol, method string) bool {
+	for _, name := range names {
+		if name == "*" || name == protocol || name == method || name == protocol+"://"+method {
+			return true
+		}
+	}
+	return false
+}
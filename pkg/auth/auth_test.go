@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/asjard/asjard/core/config"
+	_ "github.com/asjard/asjard/pkg/config/mem"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestMain(m *testing.M) {
+	if err := config.Load(-1); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// uniqueName returns an authenticator name not configured by the other tests.
+func uniqueName(prefix string) string {
+	return prefix + strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+func signToken(t *testing.T, alg, kid string, key any, claims map[string]any) string {
+	header, err := json.Marshal(map[string]any{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(crypto.SHA256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := crypto.SHA256.New()
+		digest.Write([]byte(signed))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest.Sum(nil))
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		digest := crypto.SHA256.New()
+		digest.Write([]byte(signed))
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest.Sum(nil))
+		require.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func bearerContext(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]any{
+		{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.Bytes()), "y": b64(ecKey.Y.Bytes())},
+	}})
+	require.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0o600))
+
+	name := uniqueName("jwt")
+	prefix := "asjard.auth." + name
+	require.NoError(t, config.Set(prefix+".secret", "secret"))
+	require.NoError(t, config.Set(prefix+".jwks", jwksFile))
+	require.NoError(t, config.Set(prefix+".issuer", "asjard"))
+	authenticator, err := NewJWTAuthenticator(name)
+	require.NoError(t, err)
+
+	claims := map[string]any{
+		"sub":   "u1",
+		"iss":   "asjard",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "read write",
+	}
+	for _, token := range []string{
+		signToken(t, "HS256", "", []byte("secret"), claims),
+		signToken(t, "RS256", "rsa", rsaKey, claims),
+		signToken(t, "ES256", "ec", ecKey, claims),
+	} {
+		principal, err := authenticator.Authenticate(bearerContext(token))
+		require.NoError(t, err)
+		require.Equal(t, "u1", principal.Subject)
+		require.Equal(t, name, principal.Authenticator)
+		require.True(t, principal.HasScopes("read", "write"))
+		require.Equal(t, map[string]string{"authorization": "Bearer " + token}, principal.Credentials)
+	}
+
+	_, err = authenticator.Authenticate(context.Background())
+	require.ErrorIs(t, err, ErrNoCredentials)
+
+	for desc, token := range map[string]string{
+		"wrong secret": signToken(t, "HS256", "", []byte("other"), claims),
+		"wrong kid":    signToken(t, "RS256", "ec", rsaKey, claims),
+		"none":         signToken(t, "none", "", nil, claims),
+		"expired": signToken(t, "HS256", "", []byte("secret"), map[string]any{
+			"sub": "u1", "iss": "asjard", "exp": time.Now().Add(-time.Hour).Unix(),
+		}),
+		"no expiry": signToken(t, "HS256", "", []byte("secret"), map[string]any{
+			"sub": "u1", "iss": "asjard",
+		}),
+		"issuer": signToken(t, "HS256", "", []byte("secret"), map[string]any{
+			"sub": "u1", "iss": "other", "exp": time.Now().Add(time.Hour).Unix(),
+		}),
+		"malformed": "abc",
+	} {
+		_, err := authenticator.Authenticate(bearerContext(token))
+		require.Error(t, err, desc)
+		require.NotErrorIs(t, err, ErrNoCredentials, desc)
+	}
+
+	// Tokens without expiry are accepted if allowed.
+	require.NoError(t, config.Set(prefix+".allowNoExpiry", true))
+	require.Eventually(t, func() bool {
+		_, err := authenticator.Authenticate(bearerContext(signToken(t, "HS256", "", []byte("secret"), map[string]any{
+			"sub": "u1", "iss": "asjard",
+		})))
+		return err == nil
+	}, 3*time.Second, 20*time.Millisecond)
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	name := uniqueName("apiKey")
+	prefix := "asjard.auth." + name
+	require.NoError(t, config.Set(prefix+".keys", []map[string]any{
+		{"subject": "svc-a", "key": "encrypted_base64:" + base64.StdEncoding.EncodeToString([]byte("key-a")), "scopes": []string{"read"}},
+		{"subject": "plain", "key": "key-plain"},
+	}))
+	authenticator, err := NewAPIKeyAuthenticator(name)
+	require.NoError(t, err)
+
+	principal, err := authenticator.Authenticate(metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "key-a")))
+	require.NoError(t, err)
+	require.Equal(t, "svc-a", principal.Subject)
+	require.Equal(t, []string{"read"}, principal.Scopes)
+
+	_, err = authenticator.Authenticate(metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "key-plain")))
+	require.Error(t, err, "plaintext keys are ignored")
+	_, err = authenticator.Authenticate(context.Background())
+	require.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestMTLSAuthenticator(t *testing.T) {
+	authenticator, err := NewMTLSAuthenticator(uniqueName("mtls"))
+	require.NoError(t, err)
+	_, err = authenticator.Authenticate(context.Background())
+	require.ErrorIs(t, err, ErrNoCredentials)
+
+	cert := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "svc-b", OrganizationalUnit: []string{"internal"}},
+	}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{cert}},
+		}},
+	})
+	principal, err := authenticator.Authenticate(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "svc-b", principal.Subject)
+	require.Equal(t, []string{"internal"}, principal.Scopes)
+
+	unverified := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}},
+	})
+	_, err = authenticator.Authenticate(unverified)
+	require.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	require.False(t, ok)
+	principal := &Principal{Subject: "u1"}
+	got, ok := FromContext(NewContext(context.Background(), principal))
+	require.True(t, ok)
+	require.Equal(t, principal, got)
+
+	_, err := GetAuthenticator("unknown")
+	require.Error(t, err)
+}
+
+func TestGetAuthenticator(t *testing.T) {
+	name := uniqueName("failing")
+	var created atomic.Int64
+	release := make(chan struct{})
+	AddAuthenticator(name, func(string) (Authenticator, error) {
+		created.Add(1)
+		<-release
+		return nil, errors.New("unavailable")
+	})
+
+	// Concurrent callers wait for a single creation.
+	var wg sync.WaitGroup
+	var failed atomic.Int64
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := GetAuthenticator(name); err != nil {
+				failed.Add(1)
+			}
+		}()
+	}
+	require.Eventually(t, func() bool { return created.Load() == 1 }, time.Second, 5*time.Millisecond)
+	// Other authenticators are not blocked meanwhile.
+	other := uniqueName("other")
+	AddAuthenticator(other, func(string) (Authenticator, error) { return NewMTLSAuthenticator(other) })
+	_, err := GetAuthenticator(other)
+	require.NoError(t, err)
+	close(release)
+	wg.Wait()
+	require.Equal(t, int64(1), created.Load())
+	require.Equal(t, int64(10), failed.Load())
+
+	// The error is returned without creating it again until the backoff elapses.
+	_, err = GetAuthenticator(name)
+	require.EqualError(t, err, "unavailable")
+	require.Equal(t, int64(1), created.Load())
+	am.Lock()
+	failures[name].retryAt = time.Now()
+	am.Unlock()
+	_, err = GetAuthenticator(name)
+	require.Error(t, err)
+	require.Equal(t, int64(2), created.Load())
+	am.RLock()
+	require.Equal(t, 2, failures[name].attempts)
+	require.True(t, failures[name].retryAt.After(time.Now().Add(authenticatorMinBackoff)))
+	am.RUnlock()
+}
+
+func TestMatchMethods(t *testing.T) {
+	for _, names := range [][]string{{"*"}, {"grpc"}, {"/api.v1.User/Get"}, {"rest", "grpc:///api.v1.User/Get"}} {
+		require.True(t, MatchMethods(names, "grpc", "/api.v1.User/Get"), names)
+	}
+	for _, names := range [][]string{nil, {"rest"}, {"/api.v1.User/List"}, {"rest:///api.v1.User/Get"}} {
+		require.False(t, MatchMethods(names, "grpc", "/api.v1.User/Get"), names)
+	}
+}
@@ -0,0 +1,16 @@
+/*
+Package auth authenticates the callers of a service.
+
+Authenticators are registered with AddAuthenticator and configured in asjard.auth.{name},
+the jwt, apiKey and mtls authenticators are built in.
+The auth server interceptor authenticates the requests with them and places
+the authenticated Principal in the request context:
+
+	if principal, ok := auth.FromContext(ctx); ok {
+		logger.L(ctx).Info("hello", "subject", principal.Subject)
+	}
+
+The auth client interceptor forwards the credentials of the principal, such as the JWT of the user,
+to the downstream calls, which authenticate the caller again.
+*/
+package auth
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/utils"
+)
+
+// jwksClient fetches the remote key sets.
+var jwksClient = &http.Client{Timeout: 5 * time.Second}
+
+// loadJWKS reads a JSON Web Key Set from an http(s) URL or a file relative to the configuration directory.
+// The keys of unsupported types are skipped.
+func loadJWKS(source string) ([]*jwk, error) {
+	var content []byte
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		resp, err := jwksClient.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetch jwks '%s' fail, status %d", source, resp.StatusCode)
+		}
+		if content, err = io.ReadAll(resp.Body); err != nil {
+			return nil, err
+		}
+	} else {
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(utils.GetConfDir(), source)
+		}
+		b, err := os.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		content = b
+	}
+	var jwks struct {
+		Keys []*jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &jwks); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %w", err)
+	}
+	keys := make([]*jwk, 0, len(jwks.Keys))
+	for _, key := range jwks.Keys {
+		if err := key.parse(); err != nil {
+			logger.Warn("skip jwks key", "kid", key.Kid, "kty", key.Kty, "err", err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// parse parses the public key, or the secret, of the JWK.
+func (k *jwk) parse() error {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return errors.New("invalid rsa exponent")
+		}
+		k.key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return fmt.Errorf("unsupported curve '%s'", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return errors.New("invalid ec point")
+		}
+		k.key = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return err
+		}
+		k.key = secret
+	default:
+		return fmt.Errorf("unsupported key type '%s'", k.Kty)
+	}
+	return nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
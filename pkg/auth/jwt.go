@@ -0,0 +1,333 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/runtime"
+	"github.com/asjard/asjard/utils"
+
+	// Register the hash functions of the signing algorithms.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+const (
+	// JWTAuthenticatorName is the name of the JWT authenticator.
+	JWTAuthenticatorName = "jwt"
+
+	bearerScheme = "bearer "
+)
+
+// JWTConfig defines the JWT authenticator.
+type JWTConfig struct {
+	// Header is the request header or metadata carrying the token,
+	// with the Bearer scheme if it is Authorization.
+	Header string `json:"header"`
+	// Secret is the key of the HS256, HS384 and HS512 tokens, it should be encrypted.
+	Secret string `json:"secret"`
+	// Jwks is the JSON Web Key Set verifying the tokens,
+	// an http(s) URL or a file relative to the configuration directory.
+	Jwks string `json:"jwks"`
+	// RefreshInterval is the interval the JWKS is reloaded at.
+	RefreshInterval utils.JSONDuration `json:"refreshInterval"`
+	// Algorithms are the accepted signing algorithms, all the supported ones if empty.
+	Algorithms utils.JSONStrings `json:"algorithms"`
+	// Issuer and Audience are checked if they are set.
+	Issuer   string `json:"issuer"`
+	Audience string `json:"audience"`
+	// Leeway is the clock skew tolerated on exp and nbf.
+	Leeway utils.JSONDuration `json:"leeway"`
+	// AllowNoExpiry accepts the tokens without the exp claim, which are valid forever.
+	AllowNoExpiry bool `json:"allowNoExpiry"`
+	// SubjectClaim is the claim of the principal subject.
+	SubjectClaim string `json:"subjectClaim"`
+	// ScopesClaim is the claim of the principal scopes,
+	// a space separated string or an array of strings.
+	ScopesClaim string `json:"scopesClaim"`
+	// RolesClaim is the claim of the principal roles, same format as ScopesClaim.
+	RolesClaim string `json:"rolesClaim"`
+}
+
+var defaultJWTConfig = JWTConfig{
+	Header:          "Authorization",
+	RefreshInterval: utils.JSONDuration{Duration: 5 * time.Minute},
+	Leeway:          utils.JSONDuration{Duration: time.Minute},
+	SubjectClaim:    "sub",
+	ScopesClaim:     "scope",
+	RolesClaim:      "roles",
+}
+
+// JWTAuthenticator authenticates the requests carrying a JSON Web Token
+// signed with HS256/384/512, RS256/384/512 or ES256/384/512.
+// The JWKS is reloaded every RefreshInterval and when the configuration changes.
+type JWTAuthenticator struct {
+	name string
+	conf *config.Binding[JWTConfig]
+	keys atomic.Pointer[[]*jwk]
+}
+
+// jwk is a key of a JSON Web Key Set.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+
+	// key is the parsed *rsa.PublicKey, *ecdsa.PublicKey or []byte.
+	key any
+}
+
+// jwtAlgorithm is a supported signing algorithm.
+type jwtAlgorithm struct {
+	kty  string
+	hash crypto.Hash
+}
+
+var jwtAlgorithms = map[string]jwtAlgorithm{
+	"HS256": {"oct", crypto.SHA256},
+	"HS384": {"oct", crypto.SHA384},
+	"HS512": {"oct", crypto.SHA512},
+	"RS256": {"RSA", crypto.SHA256},
+	"RS384": {"RSA", crypto.SHA384},
+	"RS512": {"RSA", crypto.SHA512},
+	"ES256": {"EC", crypto.SHA256},
+	"ES384": {"EC", crypto.SHA384},
+	"ES512": {"EC", crypto.SHA512},
+}
+
+func init() {
+	AddAuthenticator(JWTAuthenticatorName, NewJWTAuthenticator)
+}
+
+// NewJWTAuthenticator creates the JWT authenticator configured in asjard.auth.{name}.
+func NewJWTAuthenticator(name string) (Authenticator, error) {
+	conf, _, err := config.Bind(fmt.Sprintf(constant.ConfigAuthWithNamePrefix, name), defaultJWTConfig)
+	if err != nil {
+		return nil, err
+	}
+	a := &JWTAuthenticator{
+		name: name,
+		conf: conf,
+	}
+	if err := a.loadKeys(); err != nil {
+		return nil, err
+	}
+	conf.OnChange(func(old, new *JWTConfig) {
+		if old.Jwks != new.Jwks || old.Secret != new.Secret {
+			if err := a.loadKeys(); err != nil {
+				logger.Error("load jwks fail", "authenticator", name, "jwks", new.Jwks, "err", err)
+			}
+		}
+	})
+	go a.refresh()
+	return a, nil
+}
+
+// Authenticate verifies the token of the request.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context) (*Principal, error) {
+	conf := a.conf.Load()
+	token := RequestMetadata(ctx, conf.Header)
+	if strings.EqualFold(conf.Header, "Authorization") {
+		if len(token) < len(bearerScheme) || !strings.EqualFold(token[:len(bearerScheme)], bearerScheme) {
+			return nil, ErrNoCredentials
+		}
+		token = strings.TrimSpace(token[len(bearerScheme):])
+	}
+	if token == "" {
+		return nil, ErrNoCredentials
+	}
+	claims, err := a.verify(conf, token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	subject, _ := claims[conf.SubjectClaim].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("token has no %s claim", conf.SubjectClaim)
+	}
+	return &Principal{
+		Subject:       subject,
+		Authenticator: a.name,
+		Scopes:        claimStrings(claims[conf.ScopesClaim]),
+		Roles:         claimStrings(claims[conf.RolesClaim]),
+		Claims:        claims,
+		Credentials:   map[string]string{strings.ToLower(conf.Header): RequestMetadata(ctx, conf.Header)},
+	}, nil
+}
+
+// verify checks the signature and the registered claims of a token and returns its claims.
+func (a *JWTAuthenticator) verify(conf *JWTConfig, token string, now time.Time) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	alg, ok := jwtAlgorithms[header.Alg]
+	if !ok || (len(conf.Algorithms) != 0 && !conf.Algorithms.Contains(header.Alg)) {
+		return nil, fmt.Errorf("algorithm '%s' not accepted", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range a.getKeys() {
+		if key.Kty != alg.kty || (header.Kid != "" && key.Kid != "" && key.Kid != header.Kid) ||
+			(key.Alg != "" && key.Alg != header.Alg) {
+			continue
+		}
+		if verifySignature(alg, key.key, signed, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("invalid token signature")
+	}
+
+	claims := make(map[string]any)
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	leeway := conf.Leeway.Duration
+	exp, ok := claims["exp"].(float64)
+	if !ok && !conf.AllowNoExpiry {
+		return nil, errors.New("token has no exp claim")
+	}
+	if ok && now.After(time.Unix(int64(exp), 0).Add(leeway)) {
+		return nil, errors.New("token is expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(leeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, errors.New("token is not valid yet")
+	}
+	if conf.Issuer != "" && claims["iss"] != conf.Issuer {
+		return nil, errors.New("invalid token issuer")
+	}
+	if conf.Audience != "" && !utils.JSONStrings(claimStrings(claims["aud"])).Contains(conf.Audience) {
+		return nil, errors.New("invalid token audience")
+	}
+	return claims, nil
+}
+
+func (a *JWTAuthenticator) getKeys() []*jwk {
+	if keys := a.keys.Load(); keys != nil {
+		return *keys
+	}
+	return nil
+}
+
+// loadKeys loads the secret and the JWKS.
+func (a *JWTAuthenticator) loadKeys() error {
+	conf := a.conf.Load()
+	var keys []*jwk
+	if conf.Secret != "" {
+		keys = append(keys, &jwk{Kty: "oct", key: []byte(conf.Secret)})
+	}
+	if conf.Jwks != "" {
+		jwks, err := loadJWKS(conf.Jwks)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, jwks...)
+	}
+	a.keys.Store(&keys)
+	return nil
+}
+
+// refresh reloads the JWKS every RefreshInterval, the last keys are kept if it fails.
+func (a *JWTAuthenticator) refresh() {
+	for {
+		interval := a.conf.Load().RefreshInterval.Duration
+		if interval <= 0 {
+			interval = defaultJWTConfig.RefreshInterval.Duration
+		}
+		select {
+		case <-runtime.Exit:
+			return
+		case <-time.After(interval):
+			if a.conf.Load().Jwks == "" {
+				continue
+			}
+			if err := a.loadKeys(); err != nil {
+				logger.Error("refresh jwks fail", "authenticator", a.name, "jwks", a.conf.Load().Jwks, "err", err)
+			}
+		}
+	}
+}
+
+// verifySignature verifies the signature of the signed part of a token with a key.
+func verifySignature(alg jwtAlgorithm, key any, signed, signature []byte) bool {
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(alg.hash.New, k)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case *rsa.PublicKey:
+		h := alg.hash.New()
+		h.Write(signed)
+		return rsa.VerifyPKCS1v15(k, alg.hash, h.Sum(nil), signature) == nil
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		h := alg.hash.New()
+		h.Write(signed)
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(k, h.Sum(nil), r, s)
+	}
+	return false
+}
+
+func decodeSegment(segment string, out any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// claimStrings returns the strings of a claim,
+// either a space separated string or an array of strings.
+func claimStrings(claim any) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
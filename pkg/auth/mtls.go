@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// MTLSAuthenticatorName is the name of the client certificate authenticator.
+	MTLSAuthenticatorName = "mtls"
+
+	// MTLSSubjectCommonName takes the subject from the common name of the certificate.
+	MTLSSubjectCommonName = "cn"
+	// MTLSSubjectDNS takes the subject from the first DNS name of the certificate.
+	MTLSSubjectDNS = "dns"
+	// MTLSSubjectURI takes the subject from the first URI of the certificate, e.g. a SPIFFE ID.
+	MTLSSubjectURI = "uri"
+)
+
+// MTLSConfig defines the client certificate authenticator.
+type MTLSConfig struct {
+	// Subject is the field of the certificate the subject is taken from: cn, dns or uri.
+	Subject string `json:"subject"`
+}
+
+var defaultMTLSConfig = MTLSConfig{
+	Subject: MTLSSubjectCommonName,
+}
+
+// MTLSAuthenticator authenticates the gRPC callers by their verified client certificate,
+// the grpc server must verify the client certificates with asjard.servers.grpc.clientCaFile.
+// The organizational units of the certificate are the scopes of the principal.
+type MTLSAuthenticator struct {
+	name string
+	conf *config.Binding[MTLSConfig]
+}
+
+func init() {
+	AddAuthenticator(MTLSAuthenticatorName, NewMTLSAuthenticator)
+}
+
+// NewMTLSAuthenticator creates the client certificate authenticator configured in asjard.auth.{name}.
+func NewMTLSAuthenticator(name string) (Authenticator, error) {
+	conf, _, err := config.Bind(fmt.Sprintf(constant.ConfigAuthWithNamePrefix, name), defaultMTLSConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &MTLSAuthenticator{
+		name: name,
+		conf: conf,
+	}, nil
+}
+
+// Authenticate returns the identity of the verified client certificate of the connection.
+func (a *MTLSAuthenticator) Authenticate(ctx context.Context) (*Principal, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return nil, ErrNoCredentials
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	subject, err := certSubject(cert, a.conf.Load().Subject)
+	if err != nil {
+		return nil, err
+	}
+	uris := make([]any, 0, len(cert.URIs))
+	for _, uri := range cert.URIs {
+		uris = append(uris, uri.String())
+	}
+	dnsNames := make([]any, 0, len(cert.DNSNames))
+	for _, name := range cert.DNSNames {
+		dnsNames = append(dnsNames, name)
+	}
+	return &Principal{
+		Subject:       subject,
+		Authenticator: a.name,
+		Scopes:        cert.Subject.OrganizationalUnit,
+		Claims: map[string]any{
+			"cn":     cert.Subject.CommonName,
+			"dns":    dnsNames,
+			"uri":    uris,
+			"serial": cert.SerialNumber.String(),
+		},
+	}, nil
+}
+
+// certSubject returns the field of the certificate identifying the caller.
+func certSubject(cert *x509.Certificate, field string) (string, error) {
+	switch field {
+	case MTLSSubjectDNS:
+		if len(cert.DNSNames) != 0 {
+			return cert.DNSNames[0], nil
+		}
+	case MTLSSubjectURI:
+		if len(cert.URIs) != 0 {
+			return cert.URIs[0].String(), nil
+		}
+	default:
+		if cert.Subject.CommonName != "" {
+			return cert.Subject.CommonName, nil
+		}
+	}
+	return "", fmt.Errorf("client certificate has no %s", field)
+}
@@ -0,0 +1,85 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/asjard/asjard/pkg/auth"
+	"github.com/asjard/asjard/pkg/protobuf/authzpb"
+)
+
+// Request is the request to authorize.
+type Request struct {
+	// Protocol is the protocol of the request, e.g. grpc or rest.
+	Protocol string
+	// FullMethod is the method of the request, e.g. /api.v1.User/Delete.
+	FullMethod string
+	// Principal is the authenticated caller, nil if the request is not authenticated.
+	Principal *auth.Principal
+	// Authz is the authorization option of the method, nil if it has none.
+	Authz *authzpb.Authz
+}
+
+// PolicyEngine decides whether a request is allowed.
+type PolicyEngine interface {
+	// Authorize returns nil if the request is allowed,
+	// a status error, typically Unauthenticated or PermissionDenied, otherwise.
+	Authorize(ctx context.Context, req *Request) error
+}
+
+// NewPolicyEngineFunc creates a policy engine.
+type NewPolicyEngineFunc func(name string) (PolicyEngine, error)
+
+var (
+	newPolicyEngines = make(map[string]NewPolicyEngineFunc)
+	npm              sync.RWMutex
+
+	policyEngines = make(map[string]PolicyEngine)
+	pm            sync.Mutex
+)
+
+// AddPolicyEngine registers a policy engine.
+// This is typically called from an 'init' function.
+func AddPolicyEngine(name string, newFunc NewPolicyEngineFunc) {
+	npm.Lock()
+	newPolicyEngines[name] = newFunc
+	npm.Unlock()
+}
+
+// GetPolicyEngine returns the named policy engine, creating it on first use.
+func GetPolicyEngine(name string) (PolicyEngine, error) {
+	pm.Lock()
+	defer pm.Unlock()
+	if engine, ok := policyEngines[name]; ok {
+		return engine, nil
+	}
+	npm.RLock()
+	newFunc, ok := newPolicyEngines[name]
+	npm.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("policy engine '%s' not found", name)
+	}
+	engine, err := newFunc(name)
+	if err != nil {
+		return nil, err
+	}
+	policyEngines[name] = engine
+	return engine, nil
+}
+
+// HasPermission reports whether one of the granted permissions covers the permission.
+// A granted permission ending with * covers all the permissions with its prefix,
+// e.g. user:* covers user:write and * covers everything.
+func HasPermission(granted []string, permission string) bool {
+	for _, g := range granted {
+		if g == permission {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(g, "*"); ok && strings.HasPrefix(permission, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/pkg/auth"
+	_ "github.com/asjard/asjard/pkg/config/mem"
+	"github.com/asjard/asjard/pkg/protobuf/authzpb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestMain(m *testing.M) {
+	if err := config.Load(-1); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestHasPermission(t *testing.T) {
+	require.True(t, HasPermission([]string{"user:read", "user:write"}, "user:write"))
+	require.True(t, HasPermission([]string{"user:*"}, "user:write"))
+	require.True(t, HasPermission([]string{"*"}, "order:delete"))
+	require.False(t, HasPermission([]string{"user:*"}, "order:read"))
+	require.False(t, HasPermission(nil, "user:read"))
+}
+
+func TestDefaultPolicyEngine(t *testing.T) {
+	require.NoError(t, config.Set("asjard.authz.roles", map[string]any{
+		"editor": map[string]any{"permissions": []string{"user:*"}},
+	}))
+	require.NoError(t, config.Set("asjard.authz.policies", []map[string]any{
+		{"methods": []string{"grpc:///api.v1.Tenant/Get"}, "conditions": []map[string]any{
+			{"claim": "tenant", "metadata": "x-tenant-id"},
+		}},
+	}))
+	engine, err := GetPolicyEngine(DefaultPolicyEngineName)
+	require.NoError(t, err)
+	_, err = GetPolicyEngine("unknown")
+	require.Error(t, err)
+
+	write := &authzpb.Authz{Permissions: []string{"user:write"}}
+	admin := &authzpb.Authz{Roles: []string{"admin"}}
+	editor := &auth.Principal{Subject: "u1", Roles: []string{"editor"}, Claims: map[string]any{"tenant": "t1"}}
+	scoped := &auth.Principal{Subject: "u2", Scopes: []string{"user:write"}}
+	ctx := context.Background()
+
+	for desc, c := range map[string]struct {
+		req    *Request
+		status int
+	}{
+		"no requirement":        {&Request{FullMethod: "/api.v1.User/Get"}, http.StatusOK},
+		"no principal":          {&Request{FullMethod: "/api.v1.User/Update", Authz: write}, http.StatusUnauthorized},
+		"role permission":       {&Request{FullMethod: "/api.v1.User/Update", Authz: write, Principal: editor}, http.StatusOK},
+		"scope permission":      {&Request{FullMethod: "/api.v1.User/Update", Authz: write, Principal: scoped}, http.StatusOK},
+		"missing role":          {&Request{FullMethod: "/api.v1.User/Delete", Authz: admin, Principal: editor}, http.StatusForbidden},
+		"condition no metadata": {&Request{Protocol: "grpc", FullMethod: "/api.v1.Tenant/Get", Principal: editor}, http.StatusForbidden},
+		"condition no claim":    {&Request{Protocol: "grpc", FullMethod: "/api.v1.Tenant/Get", Principal: scoped}, http.StatusForbidden},
+	} {
+		err := engine.Authorize(ctx, c.req)
+		if c.status == http.StatusOK {
+			require.NoError(t, err, desc)
+			continue
+		}
+		require.Equal(t, uint32(c.status), status.FromError(err).Status, desc)
+	}
+
+	tenantCtx := func(tenant string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs("x-tenant-id", tenant))
+	}
+	req := &Request{Protocol: "grpc", FullMethod: "/api.v1.Tenant/Get", Principal: editor}
+	require.NoError(t, engine.Authorize(tenantCtx("t1"), req))
+	require.Error(t, engine.Authorize(tenantCtx("t2"), req))
+}
+
+func TestGetMethodAuthz(t *testing.T) {
+	options := &descriptorpb.MethodOptions{}
+	proto.SetExtension(options, authzpb.E_Authz, &authzpb.Authz{Roles: []string{"admin"}})
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("authz_test.proto"),
+		Package:    proto.String("api.v1.authztest"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/empty.proto"},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Test"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{Name: proto.String("Admin"), InputType: proto.String(".google.protobuf.Empty"), OutputType: proto.String(".google.protobuf.Empty"), Options: options},
+				{Name: proto.String("Public"), InputType: proto.String(".google.protobuf.Empty"), OutputType: proto.String(".google.protobuf.Empty")},
+			},
+		}},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+	require.NoError(t, protoregistry.GlobalFiles.RegisterFile(fd))
+
+	authz := authzpb.GetMethodAuthz("/api.v1.authztest.Test/Admin")
+	require.NotNil(t, authz)
+	require.Equal(t, []string{"admin"}, authz.GetRoles())
+	require.Nil(t, authzpb.GetMethodAuthz("/api.v1.authztest.Test/Public"))
+	require.Nil(t, authzpb.GetMethodAuthz("/api.v1.authztest.Test/Unknown"))
+
+	authzpb.AddMethodAuthz("/api.v1.authztest.Test/Rest", &authzpb.Authz{Permissions: []string{"user:write"}})
+	require.Equal(t, []string{"user:write"}, authzpb.GetMethodAuthz("/api.v1.authztest.Test/Rest").GetPermissions())
+}
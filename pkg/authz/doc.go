@@ -0,0 +1,20 @@
+/*
+Package authz authorizes the principals authenticated by the auth package.
+
+The requirements of a method are declared next to its definition with the authz method option:
+
+	import "github.com/asjard/protobuf/authz.proto";
+
+	rpc Delete(DeleteReq) returns (google.protobuf.Empty) {
+		option (asjard.api.authz) = {
+			permissions: [ "user:write" ]
+			roles: [ "admin" ]
+		};
+	};
+
+and completed by the policies configured in asjard.authz, which also grant permissions to the roles.
+The authz server interceptor, placed after the auth interceptor, evaluates them
+with the policy engine configured in asjard.interceptors.server.authz.engine.
+Policy engines are registered with AddPolicyEngine, the default one is built in.
+*/
+package authz
@@ -0,0 +1,197 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/pkg/auth"
+	"github.com/asjard/asjard/utils"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// DefaultPolicyEngineName is the name of the policy engine configured in asjard.authz.
+	DefaultPolicyEngineName = "default"
+)
+
+// Config defines the roles and the policies of the default policy engine.
+type Config struct {
+	// Roles grant permissions to the principals having them, by role name.
+	Roles map[string]*RoleConfig `json:"roles"`
+	// Policies are the requirements of the methods added to their authz option,
+	// every matching policy must be met.
+	Policies []*PolicyConfig `json:"policies"`
+}
+
+// RoleConfig defines a role.
+type RoleConfig struct {
+	// Permissions are granted to the principals having the role.
+	Permissions utils.JSONStrings `json:"permissions"`
+}
+
+// PolicyConfig defines the requirements of some methods.
+type PolicyConfig struct {
+	// Methods selects the requests, e.g. "grpc:///pkg.Service/Method", "/pkg.Service/Method", "grpc" or "*".
+	Methods utils.JSONStrings `json:"methods"`
+	// Roles accepts only the principals having one of the roles.
+	Roles utils.JSONStrings `json:"roles"`
+	// Permissions accepts only the principals having all the permissions.
+	Permissions utils.JSONStrings `json:"permissions"`
+	// Conditions are the requirements on the principal attributes, all of them must be met.
+	Conditions []*ConditionConfig `json:"conditions"`
+}
+
+// ConditionConfig defines a requirement on a claim of the principal.
+type ConditionConfig struct {
+	// Claim is the name of the claim, e.g. tenant.
+	Claim string `json:"claim"`
+	// Values accepts only the claims with one of the values if it is not empty.
+	Values utils.JSONStrings `json:"values"`
+	// Metadata accepts only the claims equal to the request header or metadata if it is set,
+	// e.g. x-tenant-id for the principals of their own tenant only.
+	Metadata string `json:"metadata"`
+}
+
+// DefaultPolicyEngine evaluates the authz option of the methods and the policies in asjard.authz.
+// A request is allowed if there is no requirement on its method,
+// otherwise it must be authenticated and meet every requirement.
+// The roles and policies are reloaded when the configuration changes.
+type DefaultPolicyEngine struct {
+	conf *config.Binding[Config]
+}
+
+// requirement is the authz option of a method or a policy.
+type requirement struct {
+	roles       []string
+	permissions []string
+	conditions  []*ConditionConfig
+}
+
+func init() {
+	AddPolicyEngine(DefaultPolicyEngineName, NewDefaultPolicyEngine)
+}
+
+// NewDefaultPolicyEngine creates the policy engine configured in asjard.authz.
+func NewDefaultPolicyEngine(_ string) (PolicyEngine, error) {
+	conf, _, err := config.Bind(constant.ConfigAuthzPrefix, Config{})
+	if err != nil {
+		return nil, err
+	}
+	return &DefaultPolicyEngine{conf: conf}, nil
+}
+
+// Authorize checks the principal against the requirements of the method.
+func (e *DefaultPolicyEngine) Authorize(ctx context.Context, req *Request) error {
+	conf := e.conf.Load()
+	requirements := e.requirements(conf, req)
+	if len(requirements) == 0 {
+		return nil
+	}
+	if req.Principal == nil {
+		return status.Error(codes.Unauthenticated, "missing credentials")
+	}
+	permissions := e.permissions(conf, req.Principal)
+	for _, r := range requirements {
+		if !r.allow(ctx, req.Principal, permissions) {
+			logger.L(ctx).Debug("authorize fail", "full_method", req.FullMethod, "subject", req.Principal.Subject)
+			return status.Error(codes.PermissionDenied, "permission denied")
+		}
+	}
+	return nil
+}
+
+// requirements returns the authz option of the method and the matching policies.
+func (e *DefaultPolicyEngine) requirements(conf *Config, req *Request) []*requirement {
+	var requirements []*requirement
+	if req.Authz != nil && (len(req.Authz.Roles) != 0 || len(req.Authz.Permissions) != 0) {
+		requirements = append(requirements, &requirement{
+			roles:       req.Authz.Roles,
+			permissions: req.Authz.Permissions,
+		})
+	}
+	for _, policy := range conf.Policies {
+		if policy != nil && auth.MatchMethods(policy.Methods, req.Protocol, req.FullMethod) {
+			requirements = append(requirements, &requirement{
+				roles:       policy.Roles,
+				permissions: policy.Permissions,
+				conditions:  policy.Conditions,
+			})
+		}
+	}
+	return requirements
+}
+
+// permissions returns the scopes of the principal and the permissions of its roles.
+func (e *DefaultPolicyEngine) permissions(conf *Config, principal *auth.Principal) []string {
+	permissions := slices.Clone(principal.Scopes)
+	for _, role := range principal.Roles {
+		if rc, ok := conf.Roles[role]; ok && rc != nil {
+			permissions = append(permissions, rc.Permissions...)
+		}
+	}
+	return permissions
+}
+
+// allow reports whether the principal meets the requirement.
+func (r *requirement) allow(ctx context.Context, principal *auth.Principal, permissions []string) bool {
+	if len(r.roles) != 0 && !slices.ContainsFunc(r.roles, func(role string) bool {
+		return slices.Contains(principal.Roles, role)
+	}) {
+		return false
+	}
+	for _, permission := range r.permissions {
+		if !HasPermission(permissions, permission) {
+			return false
+		}
+	}
+	for _, condition := range r.conditions {
+		if condition != nil && !condition.match(ctx, principal) {
+			return false
+		}
+	}
+	return true
+}
+
+// match reports whether the claim of the principal meets the condition.
+func (c *ConditionConfig) match(ctx context.Context, principal *auth.Principal) bool {
+	values := claimValues(principal.Claims[c.Claim])
+	if len(values) == 0 {
+		return false
+	}
+	if len(c.Values) != 0 && !slices.ContainsFunc(values, c.Values.Contains) {
+		return false
+	}
+	if c.Metadata != "" {
+		md := auth.RequestMetadata(ctx, c.Metadata)
+		if md == "" || !slices.Contains(values, md) {
+			return false
+		}
+	}
+	return true
+}
+
+// claimValues returns the values of a claim, a scalar or an array.
+func claimValues(claim any) []string {
+	switch v := claim.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, claimValues(item)...)
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
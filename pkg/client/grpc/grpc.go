@@ -32,6 +32,8 @@ type Client struct {
 	balanceName string
 	// Global interceptor for all connections created by this client.
 	interceptor client.UnaryClientInterceptor
+	// Global stream interceptor for all connections created by this client.
+	streamInterceptor client.StreamClientInterceptor
 }
 
 // ClientConn wraps a standard grpc.ClientConn to satisfy the framework's ClientConnInterface.
@@ -61,6 +63,9 @@ func NewClient(options *client.ClientOptions) client.ClientInterface {
 	if options.Interceptor != nil {
 		c.interceptor = options.Interceptor
 	}
+	if options.StreamInterceptor != nil {
+		c.streamInterceptor = options.StreamInterceptor
+	}
 	return c
 }
 
@@ -130,6 +135,19 @@ func (c Client) NewConn(target string, opts ...client.ConnOption) (client.Client
 			}))
 	}
 
+	streamInterceptor := c.streamInterceptor
+	if connOptions.StreamInterceptor != nil {
+		streamInterceptor = client.ChainStreamInterceptors(c.streamInterceptor, connOptions.StreamInterceptor)
+	}
+	if streamInterceptor != nil {
+		options = append(options,
+			grpc.WithStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				return streamInterceptor(ctx, desc, &ClientConn{ClientConn: cc, serviceName: serviceName, protocol: Protocol}, method, func(ctx context.Context, desc *grpc.StreamDesc, cc client.ClientConnInterface, method string) (grpc.ClientStream, error) {
+					return streamer(ctx, desc, cc.Conn().(*grpc.ClientConn), method, opts...)
+				})
+			}))
+	}
+
 	// Create the underlying gRPC client.
 	conn, err := grpc.NewClient(target, options...)
 	if err != nil {
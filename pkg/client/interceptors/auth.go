@@ -0,0 +1,59 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/asjard/asjard/core/client"
+	"github.com/asjard/asjard/pkg/auth"
+	cgrpc "github.com/asjard/asjard/pkg/client/grpc"
+	crest "github.com/asjard/asjard/pkg/client/rest"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// AuthInterceptorName is the unique identifier for the principal propagation interceptor.
+	AuthInterceptorName = "auth"
+)
+
+// Auth forwards the credentials of the principal authenticated by the auth server interceptor,
+// e.g., the JWT of the user, to the downstream calls, which authenticate the caller again.
+// It should be placed after rest2RpcContext, which replaces the outgoing metadata of rest requests.
+type Auth struct{}
+
+func init() {
+	// Register the interceptor for gRPC and rest client protocols.
+	client.AddInterceptor(AuthInterceptorName, NewAuthInterceptor, cgrpc.Protocol, crest.Protocol)
+}
+
+// NewAuthInterceptor creates the principal propagation interceptor.
+func NewAuthInterceptor() (client.ClientInterceptor, error) {
+	return &Auth{}, nil
+}
+
+// Name returns the interceptor's registration name.
+func (*Auth) Name() string {
+	return AuthInterceptorName
+}
+
+// Interceptor adds the credentials of the principal of the request, if any,
+// to the outgoing metadata, unless the call sets its own.
+func (*Auth) Interceptor() client.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc client.ClientConnInterface, invoker client.UnaryInvoker) error {
+		principal, ok := auth.FromContext(ctx)
+		if !ok || len(principal.Credentials) == 0 {
+			return invoker(ctx, method, req, reply, cc)
+		}
+		md, ok := metadata.FromOutgoingContext(ctx)
+		if ok {
+			md = md.Copy()
+		} else {
+			md = make(metadata.MD)
+		}
+		for key, value := range principal.Credentials {
+			if len(md.Get(key)) == 0 {
+				md.Set(key, value)
+			}
+		}
+		return invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc)
+	}
+}
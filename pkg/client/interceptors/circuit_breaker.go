@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/asjard/asjard/core/status"
 	"github.com/asjard/asjard/utils"
 	"github.com/sony/gobreaker"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 )
 
@@ -110,6 +112,113 @@ func (ccb *CircuitBreaker) Interceptor() client.UnaryClientInterceptor {
 	}
 }
 
+// StreamInterceptor guards the creation of streams with the circuit breaker.
+// The outcome is reported once, when the stream is terminated.
+func (ccb *CircuitBreaker) StreamInterceptor() client.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc client.ClientConnInterface, method string, streamer client.Streamer) (grpc.ClientStream, error) {
+		commandName := ccb.match(cc.Protocol(), cc.ServiceName(), method)
+		ccb.cm.RLock()
+		breaker, ok := ccb.breakers[commandName]
+		ccb.cm.RUnlock()
+		if !ok {
+			return streamer(ctx, desc, cc, method)
+		}
+
+		success, err := breaker.Allow()
+		if err != nil {
+			logger.L(ctx).Error("circuit breaker open", "command_name", commandName, "err", err)
+			return nil, status.Error(codes.Unavailable, "circuit breaker is open")
+		}
+
+		cs, err := streamer(ctx, desc, cc, method)
+		if err != nil {
+			success(!ccb.isFailure(err))
+			return cs, err
+		}
+		return newCircuitBreakerClientStream(ctx, desc, cs, ccb, success), nil
+	}
+}
+
+// circuitBreakerClientStream reports the final result of a stream to the circuit breaker,
+// exactly once, when the stream is terminated by an error, by the last response,
+// or by the context of the caller.
+type circuitBreakerClientStream struct {
+	grpc.ClientStream
+	desc *grpc.StreamDesc
+	ccb  *CircuitBreaker
+	done func(bool)
+	once sync.Once
+	// finished is closed once the result is reported.
+	finished chan struct{}
+}
+
+func newCircuitBreakerClientStream(ctx context.Context, desc *grpc.StreamDesc, cs grpc.ClientStream, ccb *CircuitBreaker, done func(bool)) *circuitBreakerClientStream {
+	s := &circuitBreakerClientStream{
+		ClientStream: cs,
+		desc:         desc,
+		ccb:          ccb,
+		done:         done,
+		finished:     make(chan struct{}),
+	}
+	// A stream abandoned by the caller is never received until io.EOF,
+	// report it once its context is done so the breaker does not wait for it forever.
+	go func() {
+		select {
+		case <-ctx.Done():
+			// A stream canceled by the caller is not a failure of the service.
+			s.report(!errors.Is(ctx.Err(), context.DeadlineExceeded))
+		case <-s.finished:
+		}
+	}()
+	return s
+}
+
+// report reports the result of the stream once.
+func (s *circuitBreakerClientStream) report(success bool) {
+	s.once.Do(func() {
+		s.done(success)
+		close(s.finished)
+	})
+}
+
+// SendMsg sends a message and reports the errors other than io.EOF,
+// io.EOF means the stream was terminated and its status is returned by RecvMsg.
+func (s *circuitBreakerClientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.report(!s.ccb.isFailure(err))
+	}
+	return err
+}
+
+// CloseSend closes the send direction and reports its error.
+func (s *circuitBreakerClientStream) CloseSend() error {
+	err := s.ClientStream.CloseSend()
+	if err != nil {
+		s.report(!s.ccb.isFailure(err))
+	}
+	return err
+}
+
+// RecvMsg receives the next message and reports the result once the stream is terminated.
+// Streams without server streaming are terminated by their only response.
+func (s *circuitBreakerClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err != nil:
+		s.report(errors.Is(err, io.EOF) || !s.ccb.isFailure(err))
+	case !s.desc.ServerStreams:
+		s.report(true)
+	}
+	return err
+}
+
+// isFailure reports whether err should be counted as a failure by the circuit breaker.
+// Only 5xx and timeouts are failures, 4xx business errors keep the channel healthy.
+func (ccb *CircuitBreaker) isFailure(err error) bool {
+	return status.FromError(err).Status/100 == 5 || errors.Is(err, context.DeadlineExceeded)
+}
+
 // do executes the request within a Gobreaker context.
 func (ccb *CircuitBreaker) do(ctx context.Context, commandName, method string, req, reply any, cc client.ClientConnInterface, invoker client.UnaryInvoker) error {
 	ccb.cm.RLock()
@@ -159,9 +268,8 @@ func (ccb *CircuitBreaker) do(ctx context.Context, commandName, method string, r
 
 	// 根据业务状态码 精准决定是否上报失败
 	if invokeErr != nil {
-		es := status.FromError(invokeErr)
 		// 校验状态码：如果是网络超时、5xx 服务端崩溃，或者 context 层面超时，判定为失败
-		if es.Status%100 == 5 || errors.Is(invokeErr, context.DeadlineExceeded) {
+		if ccb.isFailure(invokeErr) {
 			success(false) // 触发熔断计数
 		} else {
 			success(true) // 4xx 等业务客户端错误，依然视作当前通道健康，不计入失败率
@@ -182,37 +290,72 @@ func (ccb *CircuitBreaker) do(ctx context.Context, commandName, method string, r
 
 // match identifies which Hystrix command configuration should be applied to the request.
 func (ccb *CircuitBreaker) match(protocol, service, method string) string {
-	fullName := ccb.buildKey(protocol, "//", service, "/", method)
+	priorities := matchPriorities(prioritiesPool.Get().([]string)[:0], protocol, service, method)
+	defer prioritiesPool.Put(priorities)
+	fullName := priorities[0]
 	if name, ok := ccb.cache.Load(fullName); ok {
 		return name.(string)
 	}
 
-	priorities := prioritiesPool.Get().([]string)
-	priorities = priorities[:0]
-	priorities = append(priorities,
-		fullName,
-		ccb.buildKey(protocol, "//", service),
-		ccb.buildKey(protocol, "//", method),
-		ccb.buildKey(protocol),
-		ccb.buildKey("//", service, "/", method),
-		ccb.buildKey("///", method),
-		ccb.buildKey("//", service),
-	)
-	defer prioritiesPool.Put(priorities)
-
 	ccb.cm.RLock()
 	defer ccb.cm.RUnlock()
-	for _, name := range priorities {
+	for idx, name := range priorities {
 		if _, ok := ccb.breakers[name]; ok {
 			ccb.cache.Store(fullName, name)
 			return name
 		}
+		// The names configured in the legacy format are matched after their documented format.
+		if idx < 3 {
+			legacyName := legacyCircuitBreakerName(idx, protocol, service, method)
+			if _, ok := ccb.breakers[legacyName]; ok {
+				ccb.cache.Store(fullName, legacyName)
+				return legacyName
+			}
+		}
 	}
 	return DefaultCommandConfigName
 }
 
+// legacyCircuitBreakerName returns the legacy name of the idx-th of the first three matchPriorities,
+// protocol//service/method, protocol//service and protocol//method, which were matched
+// before the names followed the documented protocol://service/method format.
+//
+// Deprecated: kept for the existing configurations, use the documented format instead.
+func legacyCircuitBreakerName(idx int, protocol, service, method string) string {
+	switch idx {
+	case 0:
+		return buildKey(protocol, "//", service, "/", method)
+	case 1:
+		return buildKey(protocol, "//", service)
+	default:
+		return buildKey(protocol, "//", method)
+	}
+}
+
+// isLegacyCircuitBreakerName reports whether the configured name is in the legacy format.
+func isLegacyCircuitBreakerName(name string) bool {
+	return !strings.HasPrefix(name, "//") && !strings.Contains(name, "://") && strings.Contains(name, "//")
+}
+
+// matchPriorities appends the configuration names matching a call to dst,
+// from the most to the least specific:
+// protocol://service/method, protocol://service, protocol:///method, protocol,
+// //service/method, ///method and //service.
+func matchPriorities(dst []string, protocol, service, method string) []string {
+	method = strings.TrimPrefix(method, "/")
+	return append(dst,
+		buildKey(protocol, "://", service, "/", method),
+		buildKey(protocol, "://", service),
+		buildKey(protocol, ":///", method),
+		protocol,
+		buildKey("//", service, "/", method),
+		buildKey("///", method),
+		buildKey("//", service),
+	)
+}
+
 // buildKey efficiently joins string parts using a pool.
-func (ccb *CircuitBreaker) buildKey(parts ...string) string {
+func buildKey(parts ...string) string {
 	b := builderPool.Get().(*strings.Builder)
 	b.Reset()
 	for _, p := range parts {
@@ -252,6 +395,9 @@ func (ccb *CircuitBreaker) load() error {
 		if err := config.GetWithUnmarshal(fmt.Sprintf("%s.methods[%d]", ConfigPrefix, idx), &mc); err != nil {
 			return err
 		}
+		if isLegacyCircuitBreakerName(method.Name) {
+			logger.Warn("circuit breaker method name is deprecated, use protocol://service/method instead", "name", method.Name)
+		}
 		rawConfigs[method.Name] = mc.GobreakerConfig
 	}
 
@@ -7,7 +7,9 @@ import (
 
 	"github.com/asjard/asjard/core/client"
 	"github.com/asjard/asjard/core/status"
-	"github.com/asjard/asjard/pkg/client/grpc"
+	cgrpc "github.com/asjard/asjard/pkg/client/grpc"
+	crest "github.com/asjard/asjard/pkg/client/rest"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 )
@@ -30,8 +32,8 @@ type CycleChainInterceptor struct {
 }
 
 func init() {
-	// Register the interceptor specifically for gRPC client protocols.
-	client.AddInterceptor(CycleChainInterceptorName, NewCycleChainInterceptor, grpc.Protocol)
+	// Register the interceptor for gRPC and rest client protocols.
+	client.AddInterceptor(CycleChainInterceptorName, NewCycleChainInterceptor, cgrpc.Protocol, crest.Protocol)
 }
 
 // NewCycleChainInterceptor creates a new instance of the cycle detection interceptor.
@@ -45,40 +47,79 @@ func (CycleChainInterceptor) Name() string {
 }
 
 // Interceptor provides the logic to track the call chain and prevent loops.
-// It currently focuses on gRPC-to-gRPC hops within a distributed trace.
+// It tracks gRPC and rest hops within a distributed trace.
 func (s CycleChainInterceptor) Interceptor() client.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply any, cc client.ClientConnInterface, invoker client.UnaryInvoker) error {
-		// Only apply logic to gRPC client connections.
-		if _, ok := cc.(*grpc.ClientConn); !ok {
+		// Only apply logic to gRPC and rest client connections.
+		protocol, ok := s.protocol(cc)
+		if !ok {
 			return invoker(ctx, method, req, reply, cc)
 		}
 
-		// Extract metadata from the context to check the existing call chain.
-		md, ok := metadata.FromOutgoingContext(ctx)
+		ctx, err := s.appendChain(ctx, protocol, method)
+		if err != nil {
+			return err
+		}
+
+		return invoker(ctx, method, req, reply, cc)
+	}
+}
+
+// StreamInterceptor applies the same cycle detection to streaming RPCs.
+func (s CycleChainInterceptor) StreamInterceptor() client.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc client.ClientConnInterface, method string, streamer client.Streamer) (grpc.ClientStream, error) {
+		protocol, ok := s.protocol(cc)
 		if !ok {
-			// Fallback to incoming context if outgoing is empty (start of a new hop).
-			md, ok = metadata.FromIncomingContext(ctx)
-			if !ok {
-				md = metadata.New(map[string]string{})
-			}
+			return streamer(ctx, desc, cc, method)
 		}
+		ctx, err := s.appendChain(ctx, protocol, method)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method)
+	}
+}
 
-		// Format the current method into a standardized string: grpc://Service.Method
-		currentRequestMethod := "grpc://" + strings.ReplaceAll(strings.Trim(method, "/"), "/", ".")
+// protocol returns the protocol of the connections whose calls are tracked.
+func (CycleChainInterceptor) protocol(cc client.ClientConnInterface) (string, bool) {
+	switch cc.(type) {
+	case *cgrpc.ClientConn:
+		return cgrpc.Protocol, true
+	case *crest.ClientConn:
+		return crest.Protocol, true
+	default:
+		return "", false
+	}
+}
 
-		// 1. Detect Cycles:
-		// Check if the current method has already appeared in the upstream chain.
-		if requestChains, ok := md[HeaderRequestChain]; ok && slices.Contains(requestChains, currentRequestMethod) {
-			// Found a match! Append it one last time for visibility and return an error.
-			requestChains = append(requestChains, currentRequestMethod)
-			return status.Errorf(codes.Canceled, "cycle call, chains: %s", strings.Join(requestChains, " -> "))
+// appendChain checks the call chain carried by ctx for method and
+// returns a new context with method appended to the outgoing chain.
+func (CycleChainInterceptor) appendChain(ctx context.Context, protocol, method string) (context.Context, error) {
+	// Extract metadata from the context to check the existing call chain.
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		// Fallback to incoming context if outgoing is empty (start of a new hop).
+		md, ok = metadata.FromIncomingContext(ctx)
+		if !ok {
+			md = metadata.New(map[string]string{})
 		}
+	}
 
-		// 2. Propagate Chain:
-		// Append the current method to the chain and pass it down to the next service.
-		md[HeaderRequestChain] = append(md[HeaderRequestChain], currentRequestMethod)
+	// Format the current method into a standardized string: {protocol}://Service.Method
+	currentRequestMethod := protocol + "://" + strings.ReplaceAll(strings.Trim(method, "/"), "/", ".")
 
-		// Create a new context containing the updated outgoing metadata.
-		return invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc)
+	// 1. Detect Cycles:
+	// Check if the current method has already appeared in the upstream chain.
+	if requestChains, ok := md[HeaderRequestChain]; ok && slices.Contains(requestChains, currentRequestMethod) {
+		// Found a match! Append it one last time for visibility and return an error.
+		requestChains = append(requestChains, currentRequestMethod)
+		return nil, status.Errorf(codes.Canceled, "cycle call, chains: %s", strings.Join(requestChains, " -> "))
 	}
+
+	// 2. Propagate Chain:
+	// Append the current method to the chain and pass it down to the next service.
+	md[HeaderRequestChain] = append(md[HeaderRequestChain], currentRequestMethod)
+
+	// Create a new context containing the updated outgoing metadata.
+	return metadata.NewOutgoingContext(ctx, md), nil
 }
@@ -2,6 +2,8 @@ package interceptors
 
 import (
 	"context"
+	"errors"
+	"io"
 	"sync"
 
 	"github.com/asjard/asjard/core/client"
@@ -9,6 +11,7 @@ import (
 	"github.com/asjard/asjard/core/constant"
 	"github.com/asjard/asjard/core/logger"
 	"github.com/asjard/asjard/utils"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -84,6 +87,46 @@ func (e *ErrLogInterceptor) Interceptor() client.UnaryClientInterceptor {
 	}
 }
 
+// StreamInterceptor logs failures while creating a stream and errors received on it.
+func (e *ErrLogInterceptor) StreamInterceptor() client.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc client.ClientConnInterface, method string, streamer client.Streamer) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method)
+		if err != nil {
+			if !e.skip(method) {
+				logger.L(ctx).Error("new stream error",
+					"protocol", cc.Protocol(),
+					"to", cc.ServiceName(),
+					"method", method,
+					"err", err)
+			}
+			return cs, err
+		}
+		return &errLogClientStream{ClientStream: cs, e: e, ctx: ctx, cc: cc, method: method}, nil
+	}
+}
+
+// errLogClientStream logs the error which terminates a stream.
+type errLogClientStream struct {
+	grpc.ClientStream
+	e      *ErrLogInterceptor
+	ctx    context.Context
+	cc     client.ClientConnInterface
+	method string
+}
+
+// RecvMsg receives the next message and logs any error except the normal end of stream.
+func (s *errLogClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil && !errors.Is(err, io.EOF) && !s.e.skip(s.method) {
+		logger.L(s.ctx).Error("stream response error",
+			"protocol", s.cc.Protocol(),
+			"to", s.cc.ServiceName(),
+			"method", s.method,
+			"err", err)
+	}
+	return err
+}
+
 // skip checks if the interceptor is disabled or if the current method is in the exclusion list.
 func (e *ErrLogInterceptor) skip(method string) bool {
 	e.cfgMutex.RLock()
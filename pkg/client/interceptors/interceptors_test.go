@@ -3,16 +3,34 @@ package interceptors
 import (
 	"context"
 	"errors"
+	"io"
 	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/asjard/asjard/core/client"
 	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/runtime"
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/pkg/auth"
 	clientgrpc "github.com/asjard/asjard/pkg/client/grpc"
+	clientrest "github.com/asjard/asjard/pkg/client/rest"
 	_ "github.com/asjard/asjard/pkg/config/mem"
+	"github.com/asjard/asjard/pkg/server/rest"
+	"github.com/sony/gobreaker"
 	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	_ "google.golang.org/protobuf/types/known/emptypb"
 )
 
 func TestMain(m *testing.M) {
@@ -74,6 +92,12 @@ func TestCycleChainInterceptor(t *testing.T) {
 		return nil
 	})
 	require.Error(t, err)
+
+	require.NoError(t, interceptor(context.Background(), "/svc/Method", nil, nil, &clientrest.ClientConn{}, func(ctx context.Context, _ string, _, _ any, _ client.ClientConnInterface) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		require.Equal(t, []string{"rest://svc.Method"}, md.Get(HeaderRequestChain))
+		return nil
+	}))
 }
 
 func TestInterceptorNamesAndConstructors(t *testing.T) {
@@ -91,3 +115,380 @@ func TestInterceptorNamesAndConstructors(t *testing.T) {
 		require.NotNil(t, got.Interceptor())
 	}
 }
+
+func TestCycleChainStreamInterceptor(t *testing.T) {
+	interceptor := (CycleChainInterceptor{}).StreamInterceptor()
+	cc := &clientgrpc.ClientConn{}
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs(HeaderRequestChain, "grpc://svc.Method"))
+	_, err := interceptor(ctx, &grpc.StreamDesc{}, cc, "/svc/Method", func(context.Context, *grpc.StreamDesc, client.ClientConnInterface, string) (grpc.ClientStream, error) {
+		t.Fatal("cycle must stop stream creation")
+		return nil, nil
+	})
+	require.Error(t, err)
+
+	_, err = interceptor(context.Background(), &grpc.StreamDesc{}, cc, "/svc/Method", func(ctx context.Context, _ *grpc.StreamDesc, _ client.ClientConnInterface, _ string) (grpc.ClientStream, error) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		require.Equal(t, []string{"grpc://svc.Method"}, md.Get(HeaderRequestChain))
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestRateLimiterInterceptor(t *testing.T) {
+	require.NoError(t, config.Set("asjard.interceptors.client.rateLimiter.enabled", true))
+	require.NoError(t, config.Set("asjard.interceptors.client.rateLimiter.limit", 1))
+	for key, value := range map[string]any{
+		"methods[0].name":    "grpc://waitService",
+		"methods[0].wait":    true,
+		"methods[0].maxWait": "10ms",
+		"methods[1].name":    "//freeService",
+		"methods[1].limit":   -1,
+		"methods[2].name":    "//zeroService",
+		"methods[2].limit":   0,
+	} {
+		require.NoError(t, config.Set("asjard.interceptors.client.rateLimiter."+key, value))
+	}
+	created, err := NewRateLimiter()
+	require.NoError(t, err)
+	rl := created.(*RateLimiter)
+	require.Equal(t, RateLimiterInterceptorName, rl.Name())
+	require.NoError(t, rl.load())
+
+	invoker := func(context.Context, string, any, any, client.ClientConnInterface) error { return nil }
+	interceptor := rl.Interceptor()
+
+	// fail fast, the default limiter is per target service.
+	require.NoError(t, interceptor(context.Background(), "/svc/Method", nil, nil, fakeConn{protocol: "grpc", service: "a"}, invoker))
+	require.Error(t, interceptor(context.Background(), "/svc/Method", nil, nil, fakeConn{protocol: "grpc", service: "a"}, invoker))
+	require.NoError(t, interceptor(context.Background(), "/svc/Method", nil, nil, fakeConn{protocol: "grpc", service: "b"}, invoker))
+
+	// wait mode gives up after maxWait.
+	waitConn := fakeConn{protocol: "grpc", service: "waitService"}
+	require.Equal(t, "grpc://waitService", rl.match(waitConn.protocol, waitConn.service, "/svc/Method"))
+	require.NoError(t, interceptor(context.Background(), "/svc/Method", nil, nil, waitConn, invoker))
+	require.Error(t, interceptor(context.Background(), "/svc/Method", nil, nil, waitConn, invoker))
+
+	// overrides with no limit, a zero limit does not block the calls.
+	for i := 0; i < 3; i++ {
+		require.NoError(t, interceptor(context.Background(), "/svc/Method", nil, nil, fakeConn{protocol: "rest", service: "freeService"}, invoker))
+		require.NoError(t, interceptor(context.Background(), "/svc/Method", nil, nil, fakeConn{protocol: "rest", service: "zeroService"}, invoker))
+	}
+
+	// hot reload disables the limiter.
+	require.NoError(t, config.Set("asjard.interceptors.client.rateLimiter.enabled", false))
+	require.NoError(t, rl.load())
+	require.NoError(t, interceptor(context.Background(), "/svc/Method", nil, nil, fakeConn{protocol: "grpc", service: "a"}, invoker))
+}
+
+func TestMatchPriorities(t *testing.T) {
+	require.Equal(t, []string{
+		"grpc://svc/api.v1.User/Get",
+		"grpc://svc",
+		"grpc:///api.v1.User/Get",
+		"grpc",
+		"//svc/api.v1.User/Get",
+		"///api.v1.User/Get",
+		"//svc",
+	}, matchPriorities(nil, "grpc", "svc", "/api.v1.User/Get"))
+}
+
+func TestCircuitBreakerMatch(t *testing.T) {
+	ccb := &CircuitBreaker{breakers: map[string]*gobreaker.TwoStepCircuitBreaker{
+		"grpc://svc/api.v1.User/Get": nil,
+		"grpc//svc//api.v1.User/Get": nil,
+		"grpc//other":                nil,
+		"grpc:///api.v1.User/List":   nil,
+	}}
+	require.Equal(t, "grpc://svc/api.v1.User/Get", ccb.match("grpc", "svc", "/api.v1.User/Get"))
+	// The legacy names are still matched.
+	require.Equal(t, "grpc//other", ccb.match("grpc", "other", "/api.v1.User/Get"))
+	require.Equal(t, "grpc:///api.v1.User/List", ccb.match("grpc", "svc", "/api.v1.User/List"))
+	require.Equal(t, DefaultCommandConfigName, ccb.match("rest", "svc", "/api.v1.User/Get"))
+
+	require.True(t, isLegacyCircuitBreakerName("grpc//svc//api.v1.User/Get"))
+	require.False(t, isLegacyCircuitBreakerName("grpc://svc/api.v1.User/Get"))
+	require.False(t, isLegacyCircuitBreakerName("//svc"))
+}
+
+func TestRetryInterceptor(t *testing.T) {
+	require.NoError(t, config.Set("asjard.interceptors.client.retry.enabled", true))
+	require.NoError(t, config.Set("asjard.interceptors.client.retry.initialBackoff", "1ms"))
+	require.NoError(t, config.Set("asjard.interceptors.client.retry.methods", []map[string]any{
+		{"name": "//noRetryService", "maxAttempts": 1},
+		{"name": "//hedgeService", "hedge": map[string]any{"enabled": true, "idempotent": true, "delay": "20ms"}},
+		{"name": "//notIdempotentService", "hedge": map[string]any{"enabled": true, "delay": "20ms"}},
+	}))
+	created, err := NewRetry()
+	require.NoError(t, err)
+	retry := created.(*Retry)
+	require.Equal(t, RetryInterceptorName, retry.Name())
+	require.NoError(t, retry.load())
+	interceptor := retry.Interceptor()
+
+	failing := func(failures int32, c codes.Code) (client.UnaryInvoker, *atomic.Int32) {
+		var calls atomic.Int32
+		return func(context.Context, string, any, any, client.ClientConnInterface) error {
+			if calls.Add(1) <= failures {
+				return status.Error(c, "fail")
+			}
+			return nil
+		}, &calls
+	}
+
+	// Unavailable is retried.
+	invoker, calls := failing(2, codes.Unavailable)
+	require.NoError(t, interceptor(context.Background(), "/svc/Method", nil, nil, fakeConn{protocol: "grpc", service: "a"}, invoker))
+	require.Equal(t, int32(3), calls.Load())
+
+	// The attempts are limited.
+	invoker, calls = failing(5, codes.Unavailable)
+	require.Error(t, interceptor(context.Background(), "/svc/Method", nil, nil, fakeConn{protocol: "grpc", service: "a"}, invoker))
+	require.Equal(t, int32(3), calls.Load())
+
+	// Business errors are not retried.
+	invoker, calls = failing(1, codes.InvalidArgument)
+	require.Error(t, interceptor(context.Background(), "/svc/Method", nil, nil, fakeConn{protocol: "grpc", service: "a"}, invoker))
+	require.Equal(t, int32(1), calls.Load())
+
+	// Method policies.
+	invoker, calls = failing(1, codes.Unavailable)
+	require.Error(t, interceptor(context.Background(), "/svc/Method", nil, nil, fakeConn{protocol: "grpc", service: "noRetryService"}, invoker))
+	require.Equal(t, int32(1), calls.Load())
+
+	// Hedging sends a second attempt when the first one is slow, the fastest reply wins.
+	type reply struct{ Attempt int32 }
+	var hedgeCalls atomic.Int32
+	hedgeInvoker := func(ctx context.Context, _ string, _, r any, _ client.ClientConnInterface) error {
+		attempt := hedgeCalls.Add(1)
+		if attempt == 1 {
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+				return status.Error(codes.Canceled, "canceled")
+			}
+		}
+		r.(*reply).Attempt = attempt
+		return nil
+	}
+	var got reply
+	start := time.Now()
+	require.NoError(t, interceptor(context.Background(), "/svc/Method", nil, &got, fakeConn{protocol: "grpc", service: "hedgeService"}, hedgeInvoker))
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+	require.Equal(t, int32(2), got.Attempt)
+
+	// The methods not declared idempotent are not hedged.
+	hedgeCalls.Store(0)
+	got = reply{}
+	slowInvoker := func(ctx context.Context, _ string, _, r any, _ client.ClientConnInterface) error {
+		attempt := hedgeCalls.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		r.(*reply).Attempt = attempt
+		return nil
+	}
+	require.NoError(t, interceptor(context.Background(), "/svc/Method", nil, &got, fakeConn{protocol: "grpc", service: "notIdempotentService"}, slowInvoker))
+	require.Equal(t, int32(1), got.Attempt)
+	require.Equal(t, int32(1), hedgeCalls.Load())
+	require.False(t, retry.isIdempotent("/svc/Method"))
+
+	// The idempotency_level option of the proto definition declares the method idempotent.
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test_retry_idempotent.proto"),
+		Package:    proto.String("test.retry"),
+		Dependency: []string{"google/protobuf/empty.proto"},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Idempotent"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{Name: proto.String("Get"), InputType: proto.String(".google.protobuf.Empty"), OutputType: proto.String(".google.protobuf.Empty"),
+					Options: &descriptorpb.MethodOptions{IdempotencyLevel: descriptorpb.MethodOptions_NO_SIDE_EFFECTS.Enum()}},
+				{Name: proto.String("Create"), InputType: proto.String(".google.protobuf.Empty"), OutputType: proto.String(".google.protobuf.Empty")},
+			},
+		}},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+	require.NoError(t, protoregistry.GlobalFiles.RegisterFile(fd))
+	require.True(t, retry.isIdempotent("/test.retry.Idempotent/Get"))
+	require.False(t, retry.isIdempotent("/test.retry.Idempotent/Create"))
+
+	// The budget stops the retries once exhausted.
+	budget := &retryBudget{}
+	conf := RetryBudgetConfig{Ratio: 0.5, MinRetriesPerSecond: 0}
+	for i := 0; i < 4; i++ {
+		budget.request(conf)
+	}
+	require.True(t, budget.withdraw(conf))
+	require.True(t, budget.withdraw(conf))
+	require.False(t, budget.withdraw(conf))
+
+	require.NoError(t, config.Set("asjard.interceptors.client.retry.enabled", false))
+	require.NoError(t, retry.load())
+}
+
+func TestTimeoutInterceptor(t *testing.T) {
+	protocol := "timeout-test"
+	require.NoError(t, config.Set("asjard.clients."+protocol+".timeout", "10s"))
+	require.NoError(t, config.Set("asjard.clients."+protocol+".svc.methods", []map[string]any{
+		{"name": "/api.v1.User/Get", "timeout": "1s"},
+	}))
+	require.NoError(t, config.Set("asjard.interceptors.client.timeout.minRemaining", "50ms"))
+	require.Eventually(t, func() bool {
+		return client.GetConfigWithService(protocol, "svc").MethodTimeout("/api.v1.User/Get") == time.Second
+	}, 3*time.Second, 20*time.Millisecond)
+	created, err := NewTimeout()
+	require.NoError(t, err)
+	timeout := created.(*Timeout)
+	require.Equal(t, TimeoutInterceptorName, timeout.Name())
+	require.Eventually(t, func() bool {
+		return timeout.conf.Load().MinRemaining.Duration == 50*time.Millisecond
+	}, 3*time.Second, 20*time.Millisecond)
+	interceptor := timeout.Interceptor()
+
+	remaining := func(ctx context.Context, method string) time.Duration {
+		var got time.Duration
+		require.NoError(t, interceptor(ctx, method, nil, nil, fakeConn{protocol: protocol, service: "svc"},
+			func(ctx context.Context, _ string, _, _ any, _ client.ClientConnInterface) error {
+				deadline, ok := ctx.Deadline()
+				require.True(t, ok)
+				got = time.Until(deadline)
+				return nil
+			}))
+		return got
+	}
+
+	// Method and service timeouts.
+	require.InDelta(t, time.Second, remaining(context.Background(), "/api.v1.User/Get"), float64(100*time.Millisecond))
+	require.InDelta(t, 10*time.Second, remaining(context.Background(), "/api.v1.User/List"), float64(100*time.Millisecond))
+
+	// A shorter incoming deadline is kept.
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	require.InDelta(t, 500*time.Millisecond, remaining(ctx, "/api.v1.User/Get"), float64(100*time.Millisecond))
+
+	// Calls without enough remaining time are not sent.
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = interceptor(ctx, "/api.v1.User/Get", nil, nil, fakeConn{protocol: protocol, service: "svc"},
+		func(context.Context, string, any, any, client.ClientConnInterface) error {
+			t.Fatal("call must be rejected")
+			return nil
+		})
+	require.Equal(t, uint32(504), status.FromError(err).Status)
+
+	// A closed interceptor no longer follows the configuration.
+	require.NoError(t, timeout.Close())
+	require.NoError(t, config.Set("asjard.interceptors.client.timeout.minRemaining", "80ms"))
+	time.Sleep(200 * time.Millisecond)
+	require.Equal(t, 50*time.Millisecond, timeout.conf.Load().MinRemaining.Duration)
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	created, err := NewAuthInterceptor()
+	require.NoError(t, err)
+	require.Equal(t, AuthInterceptorName, created.Name())
+	interceptor := created.Interceptor()
+
+	require.NoError(t, interceptor(context.Background(), "/svc/Method", nil, nil, &clientgrpc.ClientConn{}, func(ctx context.Context, _ string, _, _ any, _ client.ClientConnInterface) error {
+		_, ok := metadata.FromOutgoingContext(ctx)
+		require.False(t, ok)
+		return nil
+	}))
+
+	ctx := auth.NewContext(metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "r1"),
+		&auth.Principal{Subject: "u1", Authenticator: "jwt", Credentials: map[string]string{"authorization": "Bearer t1"}})
+	require.NoError(t, interceptor(ctx, "/svc/Method", nil, nil, &clientgrpc.ClientConn{}, func(ctx context.Context, _ string, _, _ any, _ client.ClientConnInterface) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		require.Equal(t, []string{"Bearer t1"}, md.Get("authorization"))
+		require.Empty(t, md.Get("x-auth-subject"))
+		require.Equal(t, []string{"r1"}, md.Get("x-request-id"))
+		return nil
+	}))
+}
+
+type fakeClientStream struct {
+	grpc.ClientStream
+	sendErr error
+	recvErr error
+}
+
+func (s *fakeClientStream) SendMsg(any) error { return s.sendErr }
+func (s *fakeClientStream) RecvMsg(any) error { return s.recvErr }
+func (s *fakeClientStream) CloseSend() error  { return nil }
+
+func TestCircuitBreakerClientStream(t *testing.T) {
+	ccb := &CircuitBreaker{}
+	var reports []bool
+	var rm sync.Mutex
+	done := func(success bool) {
+		rm.Lock()
+		reports = append(reports, success)
+		rm.Unlock()
+	}
+	reported := func() []bool {
+		rm.Lock()
+		defer rm.Unlock()
+		return slices.Clone(reports)
+	}
+
+	// The only response terminates a stream without server streaming.
+	cs := &fakeClientStream{}
+	s := newCircuitBreakerClientStream(context.Background(), &grpc.StreamDesc{ClientStreams: true}, cs, ccb, done)
+	require.NoError(t, s.SendMsg(nil))
+	require.NoError(t, s.CloseSend())
+	require.NoError(t, s.RecvMsg(nil))
+	cs.recvErr = io.EOF
+	require.ErrorIs(t, s.RecvMsg(nil), io.EOF)
+	require.Equal(t, []bool{true}, reported())
+
+	// Server errors are failures, io.EOF of SendMsg waits for the status of RecvMsg.
+	reports = nil
+	cs = &fakeClientStream{sendErr: io.EOF, recvErr: status.Error(codes.Unavailable, "unavailable")}
+	s = newCircuitBreakerClientStream(context.Background(), &grpc.StreamDesc{ServerStreams: true}, cs, ccb, done)
+	require.ErrorIs(t, s.SendMsg(nil), io.EOF)
+	require.Empty(t, reported())
+	require.Error(t, s.RecvMsg(nil))
+	require.Error(t, s.RecvMsg(nil))
+	require.Equal(t, []bool{false}, reported())
+
+	// A stream abandoned by the caller is reported once its context is done.
+	reports = nil
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	newCircuitBreakerClientStream(ctx, &grpc.StreamDesc{ServerStreams: true}, &fakeClientStream{}, ccb, done)
+	require.Eventually(t, func() bool { return len(reported()) == 1 }, time.Second, 5*time.Millisecond)
+	require.Equal(t, []bool{false}, reported())
+
+	reports = nil
+	ctx, cancel = context.WithCancel(context.Background())
+	newCircuitBreakerClientStream(ctx, &grpc.StreamDesc{ServerStreams: true}, &fakeClientStream{}, ccb, done)
+	cancel()
+	require.Eventually(t, func() bool { return len(reported()) == 1 }, time.Second, 5*time.Millisecond)
+	require.Equal(t, []bool{true}, reported())
+}
+
+func TestRest2RpcContextRoute(t *testing.T) {
+	interceptor, err := NewRest2RpcContext()
+	require.NoError(t, err)
+	for app, forwarded := range map[string]bool{
+		runtime.GetAPP().App: true,
+		"other":              false,
+		"":                   false,
+	} {
+		raw := &fasthttp.RequestCtx{}
+		raw.Request.Header.Set(client.HeaderRequestRoute, "canary")
+		raw.Request.Header.Set("x-request-id", "id")
+		if app != "" {
+			raw.Request.Header.Set(client.HeaderRequestApp, app)
+		}
+		var md metadata.MD
+		require.NoError(t, interceptor.Interceptor()(rest.NewContext(raw), "/svc/Method", nil, nil, nil,
+			func(ctx context.Context, _ string, _, _ any, _ client.ClientConnInterface) error {
+				md, _ = metadata.FromOutgoingContext(ctx)
+				return nil
+			}))
+		require.Equal(t, []string{"id"}, md.Get("x-request-id"), app)
+		if forwarded {
+			require.Equal(t, []string{"canary"}, md.Get(client.HeaderRequestRoute), app)
+		} else {
+			require.Empty(t, md.Get(client.HeaderRequestRoute), app)
+		}
+	}
+}
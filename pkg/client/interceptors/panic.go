@@ -7,6 +7,7 @@ import (
 	"github.com/asjard/asjard/core/client"
 	"github.com/asjard/asjard/core/logger"
 	"github.com/asjard/asjard/core/status"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -57,6 +58,24 @@ func (*Panic) Interceptor() client.UnaryClientInterceptor {
 	}
 }
 
+// StreamInterceptor returns the middleware function that handles panic recovery while creating streams.
+func (*Panic) StreamInterceptor() client.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc client.ClientConnInterface, method string, streamer client.Streamer) (cs grpc.ClientStream, err error) {
+		defer func() {
+			if rcv := recover(); rcv != nil {
+				logger.L(ctx).Error("stream panic",
+					"err", rcv,
+					"method", method,
+					"protocol", cc.Protocol(),
+					"service", cc.ServiceName(),
+					"stack", string(debug.Stack()))
+				err = status.InternalServerError()
+			}
+		}()
+		return streamer(ctx, desc, cc, method)
+	}
+}
+
 // Name returns the interceptor's unique name.
 func (*Panic) Name() string {
 	return PanicInterceptorName
@@ -1,3 +1,274 @@
 package interceptors
 
-// 客户端限速拦截器
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/asjard/asjard/core/client"
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/utils"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// RateLimiterInterceptorName is the unique identifier for this interceptor.
+	RateLimiterInterceptorName = "rateLimiter"
+)
+
+// RateLimiter throttles outgoing calls to protect fragile downstream services
+// from burst traffic, without requiring any change on the callee.
+// Configured names are matched with the same priorities as the circuit breaker,
+// every configured name owns a limiter shared by all the calls it matches.
+// Calls matching no name are limited by the global configuration per target service.
+type RateLimiter struct {
+	limiters map[string]*rate.Limiter
+	configs  map[string]RateLimiterLimitConfig
+	lm       sync.RWMutex
+	cache    sync.Map
+
+	enabled bool
+}
+
+// RateLimiterConfig represents the global and method-specific configuration.
+type RateLimiterConfig struct {
+	Enabled bool `json:"enabled"`
+	RateLimiterLimitConfig
+	Methods []RateLimiterMethodConfig `json:"methods"`
+}
+
+// RateLimiterLimitConfig defines the thresholds and behavior of a limiter.
+type RateLimiterLimitConfig struct {
+	// Limit is the number of requests per second, <=0 means no limit.
+	Limit float64 `json:"limit"`
+	// Burst is the maximum number of requests at once, <0 means the limit value.
+	Burst int `json:"burst"`
+	// Wait blocks the call until a token is available instead of failing fast.
+	Wait bool `json:"wait"`
+	// MaxWait bounds the waiting time in wait mode, 0 means until the context deadline.
+	MaxWait utils.JSONDuration `json:"maxWait"`
+}
+
+// RateLimiterMethodConfig defines limits for a specific method/service.
+type RateLimiterMethodConfig struct {
+	Name string `json:"name"` // Key used for matching (e.g., "grpc://UserService/GetUser")
+	RateLimiterLimitConfig
+}
+
+var defaultRateLimiterConfig = RateLimiterLimitConfig{
+	Limit: -1,
+	Burst: -1,
+}
+
+func init() {
+	client.AddInterceptor(RateLimiterInterceptorName, NewRateLimiter)
+}
+
+// NewRateLimiter initializes the interceptor and starts watching for config changes.
+func NewRateLimiter() (client.ClientInterceptor, error) {
+	rateLimiter := &RateLimiter{
+		limiters: make(map[string]*rate.Limiter),
+		configs:  make(map[string]RateLimiterLimitConfig),
+	}
+	if err := rateLimiter.loadAndWatch(); err != nil {
+		return nil, err
+	}
+	return rateLimiter, nil
+}
+
+// Name returns the interceptor's registration name.
+func (rl *RateLimiter) Name() string {
+	return RateLimiterInterceptorName
+}
+
+// Interceptor returns the middleware that throttles outgoing requests.
+func (rl *RateLimiter) Interceptor() client.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc client.ClientConnInterface, invoker client.UnaryInvoker) error {
+		if err := rl.take(ctx, cc.Protocol(), cc.ServiceName(), method); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc)
+	}
+}
+
+// StreamInterceptor throttles the creation of streams.
+// Messages within an established stream are not limited.
+func (rl *RateLimiter) StreamInterceptor() client.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc client.ClientConnInterface, method string, streamer client.Streamer) (grpc.ClientStream, error) {
+		if err := rl.take(ctx, cc.Protocol(), cc.ServiceName(), method); err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method)
+	}
+}
+
+// take waits for or takes a token of the limiter matching the call.
+func (rl *RateLimiter) take(ctx context.Context, protocol, service, method string) error {
+	rl.lm.RLock()
+	enabled := rl.enabled
+	rl.lm.RUnlock()
+	if !enabled {
+		return nil
+	}
+
+	name := rl.match(protocol, service, method)
+	limiter, conf := rl.getLimiter(name, service)
+	if limiter.Limit() == rate.Inf {
+		return nil
+	}
+
+	if !conf.Wait {
+		if limiter.Allow() {
+			return nil
+		}
+		logger.L(ctx).Warn("client rate limited", "name", name, "service", service, "method", method)
+		return status.Error(codes.ResourceExhausted, "client rate limited")
+	}
+
+	waitCtx := ctx
+	if conf.MaxWait.Duration > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, conf.MaxWait.Duration)
+		defer cancel()
+	}
+	if err := limiter.Wait(waitCtx); err != nil {
+		logger.L(ctx).Warn("client rate limited", "name", name, "service", service, "method", method, "err", err)
+		return status.Error(codes.ResourceExhausted, "client rate limited")
+	}
+	return nil
+}
+
+// match identifies which configuration should be applied to the request.
+func (rl *RateLimiter) match(protocol, service, method string) string {
+	priorities := matchPriorities(prioritiesPool.Get().([]string)[:0], protocol, service, method)
+	defer prioritiesPool.Put(priorities)
+	fullName := priorities[0]
+	if name, ok := rl.cache.Load(fullName); ok {
+		return name.(string)
+	}
+
+	rl.lm.RLock()
+	defer rl.lm.RUnlock()
+	for _, name := range priorities {
+		if _, ok := rl.configs[name]; ok {
+			rl.cache.Store(fullName, name)
+			return name
+		}
+	}
+	rl.cache.Store(fullName, DefaultCommandConfigName)
+	return DefaultCommandConfigName
+}
+
+// getLimiter returns the limiter of name, the default limiter is created per target service.
+func (rl *RateLimiter) getLimiter(name, service string) (*rate.Limiter, RateLimiterLimitConfig) {
+	key := name
+	if name == DefaultCommandConfigName {
+		key = buildKey(DefaultCommandConfigName, "//", service)
+	}
+
+	rl.lm.RLock()
+	limiter, ok := rl.limiters[key]
+	conf := rl.configs[name]
+	rl.lm.RUnlock()
+	if ok {
+		return limiter, conf
+	}
+
+	rl.lm.Lock()
+	defer rl.lm.Unlock()
+	if limiter, ok := rl.limiters[key]; ok {
+		return limiter, conf
+	}
+	limiter = rate.NewLimiter(conf.limits())
+	rl.limiters[key] = limiter
+	return limiter, conf
+}
+
+// limits converts the configuration to the limiter parameters.
+func (c RateLimiterLimitConfig) limits() (rate.Limit, int) {
+	limit := rate.Limit(c.Limit)
+	if c.Limit <= 0 {
+		limit = rate.Inf
+	}
+	burst := c.Burst
+	if burst < 0 {
+		burst = int(c.Limit)
+	}
+	// At least one request must pass, otherwise a limit below 1 would reject everything.
+	if burst < 1 {
+		burst = 1
+	}
+	return limit, burst
+}
+
+// loadAndWatch initializes the config and attaches a prefix listener for dynamic updates.
+func (rl *RateLimiter) loadAndWatch() error {
+	if err := rl.load(); err != nil {
+		return err
+	}
+	config.AddPrefixListener(constant.ConfigInterceptorClientRateLimiterPrefix, rl.watch)
+	return nil
+}
+
+// load fetches the current configuration and updates the limiters
+// in place, so that the tokens of unchanged limiters are kept.
+func (rl *RateLimiter) load() error {
+	conf := RateLimiterConfig{
+		RateLimiterLimitConfig: defaultRateLimiterConfig,
+	}
+	if err := config.GetWithUnmarshal(constant.ConfigInterceptorClientRateLimiterPrefix, &conf); err != nil {
+		return err
+	}
+
+	// Method configurations inherit the global configuration,
+	// read them again over a copy of it to keep the fields they don't set.
+	configs := make(map[string]RateLimiterLimitConfig)
+	configs[DefaultCommandConfigName] = conf.RateLimiterLimitConfig
+	for idx, method := range conf.Methods {
+		mc := RateLimiterMethodConfig{
+			Name:                   method.Name,
+			RateLimiterLimitConfig: conf.RateLimiterLimitConfig,
+		}
+		if err := config.GetWithUnmarshal(fmt.Sprintf("%s.methods[%d]", constant.ConfigInterceptorClientRateLimiterPrefix, idx), &mc); err != nil {
+			return err
+		}
+		configs[method.Name] = mc.RateLimiterLimitConfig
+	}
+
+	rl.lm.Lock()
+	limiters := make(map[string]*rate.Limiter, len(rl.limiters))
+	for key, limiter := range rl.limiters {
+		name := key
+		if strings.HasPrefix(key, DefaultCommandConfigName+"//") {
+			name = DefaultCommandConfigName
+		}
+		itemConf, ok := configs[name]
+		if !ok {
+			continue
+		}
+		limit, burst := itemConf.limits()
+		limiter.SetLimit(limit)
+		limiter.SetBurst(burst)
+		limiters[key] = limiter
+	}
+	rl.limiters = limiters
+	rl.configs = configs
+	rl.enabled = conf.Enabled
+	rl.lm.Unlock()
+
+	// Clear the match cache so that the next request matches the priorities again.
+	rl.cache.Clear()
+	return nil
+}
+
+func (rl *RateLimiter) watch(_ *config.Event) {
+	if err := rl.load(); err != nil {
+		logger.Error("load client ratelimiter config fail", "err", err)
+	}
+}
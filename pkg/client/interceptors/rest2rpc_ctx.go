@@ -2,6 +2,7 @@ package interceptors
 
 import (
 	"context"
+	"strings"
 	"sync"
 
 	"github.com/asjard/asjard/core/client"
@@ -9,6 +10,7 @@ import (
 	"github.com/asjard/asjard/core/constant"
 	"github.com/asjard/asjard/core/logger"
 	"github.com/asjard/asjard/pkg/client/grpc"
+	crest "github.com/asjard/asjard/pkg/client/rest"
 	"github.com/asjard/asjard/pkg/server/rest"
 	"github.com/asjard/asjard/utils"
 	"github.com/valyala/fasthttp"
@@ -42,6 +44,7 @@ var defaultRest2RpcContextConfig = rest2RpcContextConfig{
 		"x-request-az",
 		"x-request-id",
 		"x-request-instance",
+		client.HeaderRequestRoute,
 		"Traceparent", // W3C Trace Context
 		fasthttp.HeaderXForwardedFor,
 		fasthttp.HeaderAuthorization,
@@ -55,8 +58,8 @@ func (r rest2RpcContextConfig) complete() rest2RpcContextConfig {
 }
 
 func init() {
-	// Register the interceptor specifically for gRPC client protocols.
-	client.AddInterceptor(Rest2RpcContextInterceptorName, NewRest2RpcContext, grpc.Protocol)
+	// Register the interceptor for gRPC and rest client protocols.
+	client.AddInterceptor(Rest2RpcContextInterceptorName, NewRest2RpcContext, grpc.Protocol, crest.Protocol)
 }
 
 // NewRest2RpcContext initializes the interceptor and starts a configuration watcher.
@@ -98,7 +101,10 @@ func (r *Rest2RpcContext) Interceptor() client.UnaryClientInterceptor {
 		// Map allowed HTTP headers/params to gRPC metadata.
 		for _, k := range r.cfg.AllowHeaders {
 			// First, check standard HTTP headers.
-			md[k] = rtx.GetHeaderParam(k)
+			// The route header is only forwarded from the instances of the same app, see client.TrustedRouteApp.
+			if !strings.EqualFold(k, client.HeaderRequestRoute) || r.trustedRoute(rtx) {
+				md[k] = rtx.GetHeaderParam(k)
+			}
 			// Then, check user-defined context parameters (overrides header if present).
 			v := rtx.GetUserParam(k)
 			if len(v) != 0 {
@@ -114,6 +120,12 @@ func (r *Rest2RpcContext) Interceptor() client.UnaryClientInterceptor {
 	}
 }
 
+// trustedRoute reports whether the route header of the rest request is trusted.
+func (r *Rest2RpcContext) trustedRoute(rtx *rest.Context) bool {
+	apps := rtx.GetHeaderParam(client.HeaderRequestApp)
+	return len(apps) != 0 && client.TrustedRouteApp(apps[0])
+}
+
 // watch handles dynamic configuration updates (e.g., adding a new allowed header at runtime).
 func (r *Rest2RpcContext) watch(event *config.Event) {
 	conf := defaultRest2RpcContextConfig
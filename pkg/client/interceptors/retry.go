@@ -0,0 +1,551 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asjard/asjard/core/client"
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/utils"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	// RetryInterceptorName is the unique identifier for this interceptor.
+	RetryInterceptorName = "retry"
+
+	// retryBudgetBuckets is the number of buckets of the retry budget window.
+	retryBudgetBuckets = 10
+	// hedgeLatencySamples is the number of latencies the hedging delay is computed from.
+	hedgeLatencySamples = 128
+	// hedgeMinLatencySamples is the number of latencies required to use the percentile.
+	hedgeMinLatencySamples = 20
+)
+
+// Retry retries the calls failing with a retryable error, with an exponential backoff.
+// Policies are matched with the same priorities as the circuit breaker.
+//
+// Retries of a target service are limited by a budget, a ratio of its requests,
+// so that a failing service does not receive a retry storm.
+// The budget is counted by every client process on its own requests, it is not shared
+// across the instances of a service: N clients may send up to N times the budget.
+// Retries and hedged requests are sent to another instance when one is available.
+// Only the idempotent methods are hedged, the others are retried.
+type Retry struct {
+	configs map[string]RetryPolicyConfig
+	budget  RetryBudgetConfig
+	// budgets stores the retry budget of a target service by protocol://service.
+	budgets map[string]*retryBudget
+	// latencies stores the latencies of a call by protocol://service/method, used by hedging.
+	latencies map[string]*latencyWindow
+	rm        sync.RWMutex
+	cache     sync.Map
+	// idempotents caches whether a method is declared idempotent by its proto definition.
+	idempotents sync.Map
+
+	enabled bool
+}
+
+// RetryConfig represents the global and method-specific configuration.
+type RetryConfig struct {
+	Enabled bool `json:"enabled"`
+	RetryPolicyConfig
+	Budget  RetryBudgetConfig   `json:"budget"`
+	Methods []RetryMethodConfig `json:"methods"`
+}
+
+// RetryPolicyConfig defines when and how a call is retried.
+type RetryPolicyConfig struct {
+	// MaxAttempts is the maximum number of attempts including the first one, <=1 disables retries.
+	MaxAttempts int `json:"maxAttempts"`
+	// Statuses are the retryable HTTP statuses of the errors, e.g., 503.
+	Statuses []uint32 `json:"statuses"`
+	// ErrCodes are the retryable business codes of the errors.
+	ErrCodes []uint32 `json:"errCodes"`
+	// InitialBackoff is the backoff before the first retry.
+	InitialBackoff utils.JSONDuration `json:"initialBackoff"`
+	// MaxBackoff bounds the backoff.
+	MaxBackoff utils.JSONDuration `json:"maxBackoff"`
+	// BackoffMultiplier multiplies the backoff after every retry.
+	BackoffMultiplier float64 `json:"backoffMultiplier"`
+	// Jitter randomizes the backoff by +/- Jitter of its value, 0-1.
+	Jitter float64 `json:"jitter"`
+	// Hedge sends the attempts without waiting for the previous ones to fail.
+	Hedge RetryHedgeConfig `json:"hedge"`
+}
+
+// RetryHedgeConfig defines the hedging of a call, only for idempotent methods.
+type RetryHedgeConfig struct {
+	Enabled bool `json:"enabled"`
+	// Idempotent declares the methods of the policy idempotent. Without it only the methods
+	// with the NO_SIDE_EFFECTS or IDEMPOTENT idempotency_level option are hedged.
+	Idempotent bool `json:"idempotent"`
+	// Percentile of the latency of the call after which another attempt is sent, 0-100.
+	Percentile float64 `json:"percentile"`
+	// Delay is the delay used until enough latencies are collected.
+	Delay utils.JSONDuration `json:"delay"`
+}
+
+// RetryBudgetConfig limits the retries of a target service.
+type RetryBudgetConfig struct {
+	// Ratio is the maximum ratio of retries to requests.
+	Ratio float64 `json:"ratio"`
+	// MinRetriesPerSecond is the number of retries per second allowed regardless of the ratio.
+	MinRetriesPerSecond float64 `json:"minRetriesPerSecond"`
+	// Window is the duration the requests and retries are counted over.
+	Window utils.JSONDuration `json:"window"`
+}
+
+// RetryMethodConfig defines the policy of a specific method/service.
+type RetryMethodConfig struct {
+	Name string `json:"name"` // Key used for matching (e.g., "grpc://UserService/GetUser")
+	RetryPolicyConfig
+}
+
+var (
+	defaultRetryPolicyConfig = RetryPolicyConfig{
+		MaxAttempts:       3,
+		Statuses:          []uint32{503},
+		InitialBackoff:    utils.JSONDuration{Duration: 25 * time.Millisecond},
+		MaxBackoff:        utils.JSONDuration{Duration: time.Second},
+		BackoffMultiplier: 2,
+		Jitter:            0.2,
+		Hedge: RetryHedgeConfig{
+			Percentile: 95,
+			Delay:      utils.JSONDuration{Duration: 100 * time.Millisecond},
+		},
+	}
+	defaultRetryBudgetConfig = RetryBudgetConfig{
+		Ratio:               0.2,
+		MinRetriesPerSecond: 10,
+		Window:              utils.JSONDuration{Duration: 10 * time.Second},
+	}
+)
+
+func init() {
+	client.AddInterceptor(RetryInterceptorName, NewRetry)
+}
+
+// NewRetry initializes the interceptor and starts watching for config changes.
+func NewRetry() (client.ClientInterceptor, error) {
+	retry := &Retry{
+		configs:   make(map[string]RetryPolicyConfig),
+		budgets:   make(map[string]*retryBudget),
+		latencies: make(map[string]*latencyWindow),
+	}
+	if err := retry.loadAndWatch(); err != nil {
+		return nil, err
+	}
+	return retry, nil
+}
+
+// Name returns the interceptor's registration name.
+func (r *Retry) Name() string {
+	return RetryInterceptorName
+}
+
+// Interceptor returns the middleware retrying the failed calls.
+// Streams are not retried.
+func (r *Retry) Interceptor() client.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc client.ClientConnInterface, invoker client.UnaryInvoker) error {
+		r.rm.RLock()
+		enabled := r.enabled
+		r.rm.RUnlock()
+		if !enabled {
+			return invoker(ctx, method, req, reply, cc)
+		}
+
+		name := r.match(cc.Protocol(), cc.ServiceName(), method)
+		r.rm.RLock()
+		policy := r.configs[name]
+		r.rm.RUnlock()
+		if policy.MaxAttempts <= 1 {
+			return invoker(ctx, method, req, reply, cc)
+		}
+
+		budget := r.getBudget(cc.Protocol(), cc.ServiceName())
+		budget.request(r.budgetConfig())
+		ctx = client.WithPickedInstances(ctx)
+		if policy.Hedge.Enabled && canCopyReply(reply) && (policy.Hedge.Idempotent || r.isIdempotent(method)) {
+			return r.hedge(ctx, policy, budget, method, req, reply, cc, invoker)
+		}
+		return r.retry(ctx, policy, budget, method, req, reply, cc, invoker)
+	}
+}
+
+// retry sends the attempts one after another, with a backoff between them.
+func (r *Retry) retry(ctx context.Context, policy RetryPolicyConfig, budget *retryBudget,
+	method string, req, reply any, cc client.ClientConnInterface, invoker client.UnaryInvoker) error {
+	for attempt := 1; ; attempt++ {
+		err := invoker(ctx, method, req, reply, cc)
+		if err == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil || !policy.retryable(err) {
+			return err
+		}
+		backoff := policy.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			return err
+		}
+		if !budget.withdraw(r.budgetConfig()) {
+			logger.L(ctx).Warn("client retry budget exhausted", "service", cc.ServiceName(), "method", method, "err", err)
+			return err
+		}
+		logger.L(ctx).Warn("client call retry", "service", cc.ServiceName(), "method", method,
+			"attempt", attempt, "backoff", backoff.String(), "err", err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		if message, ok := reply.(proto.Message); ok {
+			proto.Reset(message)
+		}
+	}
+}
+
+type hedgeResult struct {
+	reply any
+	err   error
+}
+
+// hedge sends another attempt when the previous ones take longer than the hedging delay
+// or fail with a retryable error, the first successful attempt wins.
+func (r *Retry) hedge(ctx context.Context, policy RetryPolicyConfig, budget *retryBudget,
+	method string, req, reply any, cc client.ClientConnInterface, invoker client.UnaryInvoker) error {
+	latencies := r.getLatencies(cc.Protocol(), cc.ServiceName(), method)
+	delay := latencies.percentile(policy.Hedge.Percentile, policy.Hedge.Delay.Duration)
+
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan hedgeResult, policy.MaxAttempts)
+	send := func() {
+		attemptReply := reflect.New(reflect.TypeOf(reply).Elem()).Interface()
+		go func() {
+			start := time.Now()
+			err := invoker(hedgeCtx, method, req, attemptReply, cc)
+			if err == nil {
+				latencies.add(time.Since(start))
+			}
+			results <- hedgeResult{reply: attemptReply, err: err}
+		}()
+	}
+
+	send()
+	sent, pending := 1, 1
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	var err error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if sent < policy.MaxAttempts && budget.withdraw(r.budgetConfig()) {
+				send()
+				sent++
+				pending++
+				timer.Reset(delay)
+			}
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				copyReply(reply, result.reply)
+				return nil
+			}
+			err = result.err
+			if ctx.Err() != nil || !policy.retryable(err) {
+				return err
+			}
+			if sent < policy.MaxAttempts && budget.withdraw(r.budgetConfig()) {
+				logger.L(ctx).Warn("client call hedge", "service", cc.ServiceName(), "method", method,
+					"attempt", sent, "err", err)
+				send()
+				sent++
+				pending++
+				timer.Reset(delay)
+			}
+		}
+	}
+	return err
+}
+
+// isIdempotent reports whether the method, e.g. /api.v1.Server/Get, has the NO_SIDE_EFFECTS
+// or IDEMPOTENT idempotency_level option in its proto definition.
+func (r *Retry) isIdempotent(method string) bool {
+	if idempotent, ok := r.idempotents.Load(method); ok {
+		return idempotent.(bool)
+	}
+	idempotent := false
+	name := protoreflect.FullName(strings.ReplaceAll(strings.TrimPrefix(method, "/"), "/", "."))
+	if desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name); err == nil {
+		if md, ok := desc.(protoreflect.MethodDescriptor); ok {
+			if options, ok := md.Options().(*descriptorpb.MethodOptions); ok {
+				idempotent = options.GetIdempotencyLevel() != descriptorpb.MethodOptions_IDEMPOTENCY_UNKNOWN
+			}
+		}
+	}
+	r.idempotents.Store(method, idempotent)
+	return idempotent
+}
+
+// canCopyReply reports whether replies can be created for the concurrent attempts of hedging.
+func canCopyReply(reply any) bool {
+	t := reflect.TypeOf(reply)
+	return t != nil && t.Kind() == reflect.Pointer
+}
+
+// copyReply copies the reply of the winning attempt.
+func copyReply(dst, src any) {
+	if message, ok := dst.(proto.Message); ok {
+		proto.Reset(message)
+		proto.Merge(message, src.(proto.Message))
+		return
+	}
+	reflect.ValueOf(dst).Elem().Set(reflect.ValueOf(src).Elem())
+}
+
+// retryable reports whether err has a retryable HTTP status or business code.
+func (c RetryPolicyConfig) retryable(err error) bool {
+	if _, ok := grpcstatus.FromError(err); !ok {
+		return false
+	}
+	st := status.FromError(err)
+	return slices.Contains(c.Statuses, st.Status) || slices.Contains(c.ErrCodes, st.ErrCode)
+}
+
+// backoff returns the delay before the retry following the attempt.
+func (c RetryPolicyConfig) backoff(attempt int) time.Duration {
+	backoff := float64(c.InitialBackoff.Duration) * math.Pow(c.BackoffMultiplier, float64(attempt-1))
+	if c.MaxBackoff.Duration > 0 && backoff > float64(c.MaxBackoff.Duration) {
+		backoff = float64(c.MaxBackoff.Duration)
+	}
+	if c.Jitter > 0 {
+		backoff *= 1 + c.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(backoff)
+}
+
+// match identifies which configuration should be applied to the request.
+func (r *Retry) match(protocol, service, method string) string {
+	priorities := matchPriorities(prioritiesPool.Get().([]string)[:0], protocol, service, method)
+	defer prioritiesPool.Put(priorities)
+	fullName := priorities[0]
+	if name, ok := r.cache.Load(fullName); ok {
+		return name.(string)
+	}
+
+	r.rm.RLock()
+	defer r.rm.RUnlock()
+	for _, name := range priorities {
+		if _, ok := r.configs[name]; ok {
+			r.cache.Store(fullName, name)
+			return name
+		}
+	}
+	r.cache.Store(fullName, DefaultCommandConfigName)
+	return DefaultCommandConfigName
+}
+
+func (r *Retry) budgetConfig() RetryBudgetConfig {
+	r.rm.RLock()
+	defer r.rm.RUnlock()
+	return r.budget
+}
+
+// getBudget returns the retry budget of a target service.
+func (r *Retry) getBudget(protocol, service string) *retryBudget {
+	key := buildKey(protocol, "://", service)
+	r.rm.RLock()
+	budget, ok := r.budgets[key]
+	r.rm.RUnlock()
+	if ok {
+		return budget
+	}
+
+	r.rm.Lock()
+	defer r.rm.Unlock()
+	if budget, ok := r.budgets[key]; ok {
+		return budget
+	}
+	budget = &retryBudget{}
+	r.budgets[key] = budget
+	return budget
+}
+
+// getLatencies returns the latencies of a call.
+func (r *Retry) getLatencies(protocol, service, method string) *latencyWindow {
+	key := buildKey(protocol, "://", service, method)
+	r.rm.RLock()
+	latencies, ok := r.latencies[key]
+	r.rm.RUnlock()
+	if ok {
+		return latencies
+	}
+
+	r.rm.Lock()
+	defer r.rm.Unlock()
+	if latencies, ok := r.latencies[key]; ok {
+		return latencies
+	}
+	latencies = &latencyWindow{}
+	r.latencies[key] = latencies
+	return latencies
+}
+
+// loadAndWatch initializes the config and attaches a prefix listener for dynamic updates.
+func (r *Retry) loadAndWatch() error {
+	if err := r.load(); err != nil {
+		return err
+	}
+	config.AddPrefixListener(constant.ConfigInterceptorClientRetryPrefix, r.watch)
+	return nil
+}
+
+// load fetches the current configuration, the budgets and latencies are kept.
+func (r *Retry) load() error {
+	conf := RetryConfig{
+		RetryPolicyConfig: defaultRetryPolicyConfig,
+		Budget:            defaultRetryBudgetConfig,
+	}
+	if err := config.GetWithUnmarshal(constant.ConfigInterceptorClientRetryPrefix, &conf); err != nil {
+		return err
+	}
+
+	// Method configurations inherit the global configuration,
+	// decode them again over a copy of it to keep the fields they don't set.
+	var rawConf struct {
+		Methods []json.RawMessage `json:"methods"`
+	}
+	if err := config.GetWithUnmarshal(constant.ConfigInterceptorClientRetryPrefix, &rawConf); err != nil {
+		return err
+	}
+	configs := make(map[string]RetryPolicyConfig)
+	configs[DefaultCommandConfigName] = conf.RetryPolicyConfig
+	for _, raw := range rawConf.Methods {
+		mc := RetryMethodConfig{
+			RetryPolicyConfig: conf.RetryPolicyConfig,
+		}
+		if err := json.Unmarshal(raw, &mc); err != nil {
+			return err
+		}
+		configs[mc.Name] = mc.RetryPolicyConfig
+	}
+
+	r.rm.Lock()
+	r.enabled = conf.Enabled
+	r.configs = configs
+	r.budget = conf.Budget
+	r.rm.Unlock()
+
+	// 清空匹配缓存，让下一次请求重新执行优先级匹配
+	r.cache.Clear()
+	return nil
+}
+
+func (r *Retry) watch(_ *config.Event) {
+	if err := r.load(); err != nil {
+		logger.Error("load retry config fail", "err", err)
+	}
+}
+
+// retryBudget counts the requests and retries of a target service over a sliding window.
+type retryBudget struct {
+	mu      sync.Mutex
+	buckets [retryBudgetBuckets]retryBudgetBucket
+}
+
+type retryBudgetBucket struct {
+	// index is the number of the bucket since the epoch.
+	index    int64
+	requests float64
+	retries  float64
+}
+
+// request records a request.
+func (b *retryBudget) request(conf RetryBudgetConfig) {
+	b.mu.Lock()
+	b.bucket(time.Now(), conf.window()).requests++
+	b.mu.Unlock()
+}
+
+// withdraw records a retry if the budget allows it.
+func (b *retryBudget) withdraw(conf RetryBudgetConfig) bool {
+	window := conf.window()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current := b.bucket(time.Now(), window)
+	var requests, retries float64
+	for _, bucket := range b.buckets {
+		if bucket.index > current.index-retryBudgetBuckets {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+	if retries+1 > requests*conf.Ratio+conf.MinRetriesPerSecond*window.Seconds() {
+		return false
+	}
+	current.retries++
+	return true
+}
+
+// window returns the budget window, at least one nanosecond per bucket.
+func (c RetryBudgetConfig) window() time.Duration {
+	if c.Window.Duration < retryBudgetBuckets {
+		return defaultRetryBudgetConfig.Window.Duration
+	}
+	return c.Window.Duration
+}
+
+// bucket returns the bucket of now, reset if it belongs to a previous window.
+func (b *retryBudget) bucket(now time.Time, window time.Duration) *retryBudgetBucket {
+	index := now.UnixNano() / int64(window/retryBudgetBuckets)
+	bucket := &b.buckets[index%retryBudgetBuckets]
+	if bucket.index != index {
+		*bucket = retryBudgetBucket{index: index}
+	}
+	return bucket
+}
+
+// latencyWindow keeps the latest latencies of a call.
+type latencyWindow struct {
+	mu        sync.Mutex
+	latencies [hedgeLatencySamples]time.Duration
+	count     int
+}
+
+func (w *latencyWindow) add(latency time.Duration) {
+	w.mu.Lock()
+	w.latencies[w.count%hedgeLatencySamples] = latency
+	w.count++
+	w.mu.Unlock()
+}
+
+// percentile returns the percentile of the latencies,
+// or defaultValue if not enough latencies are collected.
+func (w *latencyWindow) percentile(percentile float64, defaultValue time.Duration) time.Duration {
+	w.mu.Lock()
+	n := min(w.count, hedgeLatencySamples)
+	if n < hedgeMinLatencySamples {
+		w.mu.Unlock()
+		return defaultValue
+	}
+	latencies := slices.Clone(w.latencies[:n])
+	w.mu.Unlock()
+	slices.Sort(latencies)
+	i := int(math.Ceil(percentile/100*float64(n))) - 1
+	return latencies[max(0, min(i, n-1))]
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/asjard/asjard/core/constant"
 	"github.com/asjard/asjard/core/logger"
 	"github.com/asjard/asjard/utils"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -91,6 +92,25 @@ func (s *SlowLogInterceptor) Interceptor() client.UnaryClientInterceptor {
 	}
 }
 
+// StreamInterceptor logs streams that take longer than the threshold to be established.
+// The lifetime of a stream is not measured as streams are usually long lived.
+func (s *SlowLogInterceptor) StreamInterceptor() client.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc client.ClientConnInterface, method string, streamer client.Streamer) (grpc.ClientStream, error) {
+		start := time.Now()
+		defer func() {
+			duration := time.Since(start)
+			if s.isSlow(duration, method) {
+				logger.L(ctx).Warn("slowstream",
+					"protocol", cc.Protocol(),
+					"to", cc.ServiceName(),
+					"method", method,
+					"duration", duration.String())
+			}
+		}()
+		return streamer(ctx, desc, cc, method)
+	}
+}
+
 // isSlow checks if the duration exceeds the threshold and ensures the method isn't skipped.
 func (s *SlowLogInterceptor) isSlow(duration time.Duration, method string) bool {
 	s.cfgMutex.RLock()
@@ -0,0 +1,122 @@
+package interceptors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/asjard/asjard/core/client"
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/pkg/client/grpc"
+	crest "github.com/asjard/asjard/pkg/client/rest"
+	"github.com/asjard/asjard/pkg/server/rest"
+	"github.com/asjard/asjard/utils"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// TimeoutInterceptorName is the unique identifier for this interceptor.
+	TimeoutInterceptorName = "timeout"
+)
+
+// Timeout applies the timeout of the target service and method to the calls,
+// read from asjard.clients.{protocol}.{serviceName}.timeout and methods[].timeout.
+// A deadline of the incoming request is kept if it is shorter, so that
+// the deadline shrinks along the request chain.
+// Calls with less than the minimum remaining time are rejected before being sent.
+type Timeout struct {
+	conf *config.Binding[TimeoutConfig]
+	// unbind stops the binding of conf and removeListener stops watching the client configurations.
+	unbind         func()
+	removeListener func()
+	// services caches the configuration of the target services by protocol://service.
+	services sync.Map
+}
+
+// TimeoutConfig defines the behavior of the timeout interceptor.
+type TimeoutConfig struct {
+	// MinRemaining is the minimum remaining time of a call, the calls with less are rejected.
+	MinRemaining utils.JSONDuration `json:"minRemaining"`
+}
+
+var defaultTimeoutConfig = TimeoutConfig{}
+
+func init() {
+	client.AddInterceptor(TimeoutInterceptorName, NewTimeout, grpc.Protocol, crest.Protocol)
+}
+
+// NewTimeout initializes the interceptor and starts watching for config changes.
+func NewTimeout() (client.ClientInterceptor, error) {
+	conf, unbind, err := config.Bind(constant.ConfigInterceptorClientTimeoutPrefix, defaultTimeoutConfig)
+	if err != nil {
+		return nil, err
+	}
+	timeout := &Timeout{conf: conf, unbind: unbind}
+	timeout.removeListener = config.AddPrefixListener(constant.ConfigClientPrefix, timeout.watchClients)
+	return timeout, nil
+}
+
+// Name returns the interceptor's registration name.
+func (t *Timeout) Name() string {
+	return TimeoutInterceptorName
+}
+
+// Close stops watching the configuration once the interceptor is removed from the chain.
+func (t *Timeout) Close() error {
+	t.unbind()
+	t.removeListener()
+	return nil
+}
+
+// Interceptor returns the middleware setting the deadline of the calls.
+// Streams have no deadline.
+func (t *Timeout) Interceptor() client.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc client.ClientConnInterface, invoker client.UnaryInvoker) error {
+		deadline, ok := incomingDeadline(ctx)
+		if timeout := t.methodTimeout(cc.Protocol(), cc.ServiceName(), method); timeout > 0 {
+			if d := time.Now().Add(timeout); !ok || d.Before(deadline) {
+				deadline, ok = d, true
+			}
+		}
+		if !ok {
+			return invoker(ctx, method, req, reply, cc)
+		}
+
+		if remaining := time.Until(deadline); remaining <= t.conf.Load().MinRemaining.Duration {
+			logger.L(ctx).Warn("client call rejected, deadline too short",
+				"service", cc.ServiceName(), "method", method, "remaining", remaining.String())
+			return status.Error(codes.DeadlineExceeded, "request deadline exceeded")
+		}
+
+		ctx, cancel := context.WithDeadline(ctx, deadline)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc)
+	}
+}
+
+// incomingDeadline returns the deadline of the request,
+// rest requests derive it from the x-request-timeout header.
+func incomingDeadline(ctx context.Context) (time.Time, bool) {
+	if rtx, ok := ctx.(*rest.Context); ok {
+		return rtx.Context().Deadline()
+	}
+	return ctx.Deadline()
+}
+
+// methodTimeout returns the timeout of a method of a target service.
+func (t *Timeout) methodTimeout(protocol, service, method string) time.Duration {
+	key := buildKey(protocol, "://", service)
+	conf, ok := t.services.Load(key)
+	if !ok {
+		conf, _ = t.services.LoadOrStore(key, client.GetConfigWithService(protocol, service))
+	}
+	return conf.(client.Config).MethodTimeout(method)
+}
+
+// watchClients clears the cached configurations of the target services.
+func (t *Timeout) watchClients(_ *config.Event) {
+	t.services.Clear()
+}
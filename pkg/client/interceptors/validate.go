@@ -5,6 +5,7 @@ import (
 
 	"github.com/asjard/asjard/core/client"
 	"github.com/asjard/asjard/pkg/client/grpc"
+	crest "github.com/asjard/asjard/pkg/client/rest"
 	"github.com/asjard/asjard/pkg/protobuf/validatepb"
 )
 
@@ -17,8 +18,8 @@ const (
 type Validate struct{}
 
 func init() {
-	// Register the validation interceptor specifically for the gRPC protocol.
-	client.AddInterceptor(ValidateInterceptorName, NewValidateInterceptor, grpc.Protocol)
+	// Register the validation interceptor for the gRPC and rest protocols.
+	client.AddInterceptor(ValidateInterceptorName, NewValidateInterceptor, grpc.Protocol, crest.Protocol)
 }
 
 // Name returns the interceptor's registration name.
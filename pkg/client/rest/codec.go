@@ -0,0 +1,230 @@
+package rest
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/pkg/protobuf/statuspb"
+	"github.com/valyala/fasthttp"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// bodylessMethods are sent without a body, the remaining fields are encoded as query params.
+var bodylessMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodDelete:  {},
+	http.MethodHead:    {},
+	http.MethodOptions: {},
+}
+
+// encodeRequest builds the HTTP request of route for args.
+func encodeRequest(req *fasthttp.Request, scheme, addr string, r route, args any) error {
+	msg, ok := args.(proto.Message)
+	if !ok {
+		return status.Error(codes.InvalidArgument, "rest client request must be proto.Message")
+	}
+	path, used, err := buildPath(r.path, msg.ProtoReflect())
+	if err != nil {
+		return err
+	}
+
+	req.Header.SetMethod(r.method)
+	req.SetRequestURI(scheme + "://" + addr + path)
+	// Keep the escaped path params, e.g., %2F, as they are.
+	req.URI().DisablePathNormalizing = true
+
+	if _, ok := bodylessMethods[r.method]; ok {
+		args := req.URI().QueryArgs()
+		msg.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+			if _, ok := used[fd.TextName()]; ok || fd.IsMap() || fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+				return true
+			}
+			if fd.IsList() {
+				list := v.List()
+				for i := 0; i < list.Len(); i++ {
+					args.Add(fd.TextName(), fieldValueString(fd, list.Get(i)))
+				}
+				return true
+			}
+			args.Add(fd.TextName(), fieldValueString(fd, v))
+			return true
+		})
+		return nil
+	}
+
+	body, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "marshal request fail: %v", err)
+	}
+	req.Header.SetContentType("application/json")
+	req.SetBody(body)
+	return nil
+}
+
+// buildPath replaces the {name}, {name?} and {name:*} parameters in path
+// with the values of the corresponding message fields.
+func buildPath(path string, msg protoreflect.Message) (string, map[string]struct{}, error) {
+	used := make(map[string]struct{})
+	if !strings.Contains(path, "{") {
+		return path, used, nil
+	}
+	fields := msg.Descriptor().Fields()
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+		optional := strings.HasSuffix(name, "?")
+		name = strings.TrimSuffix(name, "?")
+		if idx := strings.Index(name, ":"); idx >= 0 {
+			name = name[:idx]
+		}
+		fd := fields.ByTextName(name)
+		if fd == nil || fd.IsList() || fd.IsMap() || fd.Kind() == protoreflect.MessageKind {
+			if optional {
+				segments[i] = ""
+				continue
+			}
+			return "", nil, status.Errorf(codes.InvalidArgument, "path param %s not found in request", name)
+		}
+		used[name] = struct{}{}
+		// Values such as "a/b" or "a?b" must stay in their segment.
+		segments[i] = url.PathEscape(fieldValueString(fd, msg.Get(fd)))
+	}
+	return strings.TrimRight(strings.Join(segments, "/"), "/"), used, nil
+}
+
+// fieldValueString formats a scalar field value the way the rest server parses it.
+func fieldValueString(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	default:
+		return v.String()
+	}
+}
+
+// replyResolver resolves the type of the status envelope data to the reply message.
+type replyResolver struct {
+	*protoregistry.Types
+	reply proto.Message
+}
+
+// FindMessageByURL returns the reply type if url points to it, otherwise looks up the global registry.
+func (r replyResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	name := url
+	if idx := strings.LastIndex(url, "/"); idx >= 0 {
+		name = url[idx+1:]
+	}
+	if r.reply != nil && string(r.reply.ProtoReflect().Descriptor().FullName()) == name {
+		return r.reply.ProtoReflect().Type(), nil
+	}
+	return protoregistry.GlobalTypes.FindMessageByURL(url)
+}
+
+// decodeResponse decodes the response written by the rest server DefaultWriter,
+// a statuspb.Status envelope, into reply or an error.
+// Responses without the envelope are decoded directly into reply.
+func decodeResponse(resp *fasthttp.Response, reply any) error {
+	msg, _ := reply.(proto.Message)
+	body := resp.Body()
+
+	st := &statuspb.Status{}
+	if err := (protojson.UnmarshalOptions{
+		Resolver: replyResolver{Types: protoregistry.GlobalTypes, reply: msg},
+	}).Unmarshal(body, st); err == nil && (st.Success || st.Code != 0) {
+		if !st.Success {
+			return envelopeError(st)
+		}
+		if msg == nil || st.Data == nil {
+			return nil
+		}
+		if err := st.Data.UnmarshalTo(msg); err != nil {
+			return status.Errorf(codes.Internal, "unmarshal response data fail: %v", err)
+		}
+		return nil
+	}
+
+	if resp.StatusCode() >= http.StatusBadRequest {
+		return status.Error(httpStatusToCode(resp.StatusCode()), string(body))
+	}
+	if msg == nil || len(body) == 0 {
+		return nil
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, msg); err != nil {
+		return status.Errorf(codes.Internal, "unmarshal response fail: %v", err)
+	}
+	return nil
+}
+
+// envelopeError restores the error returned by the server, including the prompt and doc.
+func envelopeError(st *statuspb.Status) error {
+	s := &spb.Status{
+		Code:    int32(st.Code),
+		Message: st.Message,
+	}
+	if st.Doc != "" || st.Prompt != "" {
+		detail, _ := anypb.New(&statuspb.Status{
+			Doc:    st.Doc,
+			Prompt: st.Prompt,
+		})
+		s.Details = []*anypb.Any{detail}
+	}
+	return grpcstatus.ErrorProto(s)
+}
+
+// transportError converts a fasthttp error to a status error.
+func transportError(err error) error {
+	if errors.Is(err, fasthttp.ErrTimeout) || errors.Is(err, fasthttp.ErrDialTimeout) {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+// httpStatusToCode maps a HTTP status code to the nearest grpc code.
+func httpStatusToCode(statusCode int) codes.Code {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499:
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusInternalServerError:
+		return codes.Internal
+	default:
+		return codes.Unknown
+	}
+}
@@ -0,0 +1,54 @@
+package rest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asjard/asjard/core/client"
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/utils"
+)
+
+// Config represents the complete configuration for a rest client.
+// It embeds the common client.Config and adds HTTP-specific options.
+type Config struct {
+	client.Config
+	// Options contains specific HTTP connection parameters.
+	Options OptionsConfig `json:"options"`
+}
+
+// OptionsConfig holds the HTTP-specific connection tuning parameters.
+type OptionsConfig struct {
+	// MaxConnsPerHost limits the number of connections per instance.
+	MaxConnsPerHost int `json:"maxConnsPerHost"`
+	// MaxIdleConnDuration closes idle keep-alive connections after this duration.
+	MaxIdleConnDuration utils.JSONDuration `json:"maxIdleConnDuration"`
+	// ReadTimeout is the maximum duration for full response reading.
+	ReadTimeout utils.JSONDuration `json:"readTimeout"`
+	// WriteTimeout is the maximum duration for full request writing.
+	WriteTimeout utils.JSONDuration `json:"writeTimeout"`
+	// MaxResponseBodySize is the maximum response body size, 0 means unlimited.
+	MaxResponseBodySize int `json:"maxResponseBodySize"`
+}
+
+// defaultConfig returns the baseline settings for any rest client created by the framework.
+func defaultConfig() Config {
+	return Config{
+		Config: client.GetConfigWithProtocol(Protocol),
+		Options: OptionsConfig{
+			MaxConnsPerHost:     512,
+			MaxIdleConnDuration: utils.JSONDuration{Duration: 10 * time.Second},
+		},
+	}
+}
+
+// serverConfig merges global rest defaults with service-specific overrides.
+// It uses a configuration chain: Default -> Global rest -> Specific Service.
+func serverConfig(serviceName string) Config {
+	conf := defaultConfig()
+	config.GetWithUnmarshal(fmt.Sprintf(constant.ConfigClientWithProtocolPrefix, Protocol),
+		&conf,
+		config.WithChain([]string{fmt.Sprintf(constant.ConfigClientWithSevicePrefix, Protocol, serviceName)}))
+	return conf
+}
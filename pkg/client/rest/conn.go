@@ -0,0 +1,253 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/asjard/asjard/core/client"
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/status"
+	srest "github.com/asjard/asjard/pkg/server/rest"
+	"github.com/asjard/asjard/utils"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+)
+
+// conn holds the resolved instances of a target service and the picker built from them.
+// It implements resolver.ClientConn so that rest services are discovered using the
+// same resolver as gRPC clients.
+type conn struct {
+	serviceName string
+	newPicker   client.NewBalancerPicker
+	conf        Config
+	scheme      string
+	client      *fasthttp.Client
+	resolver    resolver.Resolver
+
+	picker balancer.Picker
+	pm     sync.RWMutex
+}
+
+// Verify that conn satisfies the gRPC resolver.ClientConn interface at compile time.
+var _ resolver.ClientConn = &conn{}
+
+// ClientConn is a connection to a rest service.
+// It satisfies the framework's ClientConnInterface.
+type ClientConn struct {
+	*conn
+	interceptor client.UnaryClientInterceptor
+}
+
+// Verify that ClientConn satisfies the framework ClientConnInterface at compile time.
+var _ client.ClientConnInterface = &ClientConn{}
+
+// subConn identifies a resolved address for the pickers.
+// Rest requests are sent with a shared fasthttp.Client,
+// so no real sub connection is managed here.
+type subConn struct {
+	balancer.SubConn
+	address resolver.Address
+}
+
+// newConn creates a connection to serviceName with the service specific configuration
+// and the pickers created by newPicker.
+func newConn(serviceName string, newPicker client.NewBalancerPicker) (*conn, error) {
+	conf := serverConfig(serviceName)
+	c := &conn{
+		serviceName: serviceName,
+		newPicker:   newPicker,
+		conf:        conf,
+		scheme:      "http",
+		picker:      base.NewErrPicker(status.Error(codes.Unavailable, "no instance available")),
+	}
+	httpClient := &fasthttp.Client{
+		MaxConnsPerHost:     conf.Options.MaxConnsPerHost,
+		MaxIdleConnDuration: conf.Options.MaxIdleConnDuration.Duration,
+		ReadTimeout:         conf.Options.ReadTimeout.Duration,
+		WriteTimeout:        conf.Options.WriteTimeout.Duration,
+		MaxResponseBodySize: conf.Options.MaxResponseBodySize,
+	}
+	httpClient.Name = serviceName
+	// Configure Security (TLS vs plain HTTP)
+	if conf.CertFile != "" {
+		caCert, err := os.ReadFile(filepath.Join(utils.GetCertDir(), conf.CertFile))
+		if err != nil {
+			return nil, err
+		}
+		certPool := x509.NewCertPool()
+		certPool.AppendCertsFromPEM(caCert)
+		httpClient.TLSConfig = &tls.Config{RootCAs: certPool, ServerName: serviceName}
+		c.scheme = "https"
+	}
+	c.client = httpClient
+	return c, nil
+}
+
+// UpdateState is called by the resolver when the list of available
+// instances changes. It rebuilds the picker from the new addresses.
+func (c *conn) UpdateState(state resolver.State) error {
+	scs := make(map[balancer.SubConn]base.SubConnInfo, len(state.Addresses))
+	for _, address := range state.Addresses {
+		scs[&subConn{address: address}] = base.SubConnInfo{Address: address}
+	}
+	var picker balancer.Picker
+	if len(scs) == 0 {
+		picker = base.NewErrPicker(status.Error(codes.Unavailable, "no instance available"))
+	} else {
+		picker = client.NewPicker(c.newPicker, scs)
+	}
+	c.pm.Lock()
+	c.picker = picker
+	c.pm.Unlock()
+	return nil
+}
+
+// ReportError allows the resolver to notify the client of discovery failures.
+func (c *conn) ReportError(err error) {
+	logger.Error("rest client resolve fail", "service", c.serviceName, "err", err)
+}
+
+// NewAddress is the deprecated form of UpdateState.
+func (c *conn) NewAddress(addresses []resolver.Address) {
+	c.UpdateState(resolver.State{Addresses: addresses})
+}
+
+// ParseServiceConfig is not supported, rest connections are configured
+// through the framework configuration instead.
+func (c *conn) ParseServiceConfig(serviceConfigJSON string) *serviceconfig.ParseResult {
+	return &serviceconfig.ParseResult{}
+}
+
+// Close stops watching the target service.
+func (c *conn) Close() {
+	if c.resolver != nil {
+		c.resolver.Close()
+	}
+}
+
+// pick selects an instance for method with the configured load balancer.
+func (c *conn) pick(ctx context.Context, method string) (*subConn, balancer.PickResult, error) {
+	c.pm.RLock()
+	picker := c.picker
+	c.pm.RUnlock()
+	result, err := picker.Pick(balancer.PickInfo{FullMethodName: method, Ctx: ctx})
+	if err != nil {
+		return nil, result, err
+	}
+	sc, ok := result.SubConn.(*subConn)
+	if !ok {
+		return nil, result, status.Error(codes.Internal, "invalid sub connection")
+	}
+	return sc, result, nil
+}
+
+// ServiceName returns the name of the target service for this connection.
+func (c *ClientConn) ServiceName() string {
+	return c.serviceName
+}
+
+// Protocol returns "rest".
+func (c *ClientConn) Protocol() string {
+	return Protocol
+}
+
+// Conn returns the connection itself, requests are always sent through Invoke.
+func (c *ClientConn) Conn() grpc.ClientConnInterface {
+	return c
+}
+
+// Invoke sends a request through the interceptor chain.
+// method is either a full method registered with AddRoute/AddServiceDesc,
+// an explicit route such as "GET /api/v1/users/{id}", or a path which is sent with POST.
+func (c *ClientConn) Invoke(ctx context.Context, method string, args, reply any, _ ...grpc.CallOption) error {
+	if c.interceptor != nil {
+		return c.interceptor(ctx, method, args, reply, c, c.invoke)
+	}
+	return c.invoke(ctx, method, args, reply, c)
+}
+
+// NewStream is not supported by the rest protocol.
+func (c *ClientConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, status.Error(codes.Unimplemented, "rest client does not support stream")
+}
+
+// invoke picks an instance and performs the HTTP exchange.
+func (c *ClientConn) invoke(ctx context.Context, method string, args, reply any, _ client.ClientConnInterface) error {
+	route := getRoute(method)
+	sc, result, err := c.pick(ctx, method)
+	if err != nil {
+		return err
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer func() {
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+	}()
+
+	if err = encodeRequest(req, c.scheme, sc.address.Addr, route, args); err != nil {
+		c.done(result, err)
+		return err
+	}
+
+	// Outgoing metadata set by interceptors and the picker is sent as headers.
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		setHeaders(req, md)
+	}
+	setHeaders(req, result.Metadata)
+
+	deadline, ok := ctx.Deadline()
+	if !ok && c.conf.Timeout.Duration > 0 {
+		deadline = time.Now().Add(c.conf.Timeout.Duration)
+	}
+	// The remaining time is sent so that the server shortens its own deadline.
+	if !deadline.IsZero() {
+		req.Header.Set(srest.HeaderRequestTimeout, srest.FormatRequestTimeout(time.Until(deadline)))
+	}
+	if deadline.IsZero() {
+		err = c.client.Do(req, resp)
+	} else {
+		err = c.client.DoDeadline(req, resp, deadline)
+	}
+	if err != nil {
+		err = transportError(err)
+		c.done(result, err)
+		return err
+	}
+
+	err = decodeResponse(resp, reply)
+	c.done(result, err)
+	return err
+}
+
+// done reports the result of a request to the picker.
+func (c *ClientConn) done(result balancer.PickResult, err error) {
+	if result.Done != nil {
+		result.Done(balancer.DoneInfo{Err: err})
+	}
+}
+
+// setHeaders copies metadata into the request headers.
+func setHeaders(req *fasthttp.Request, md metadata.MD) {
+	for k, values := range md {
+		for idx, v := range values {
+			if idx == 0 {
+				req.Header.Set(k, v)
+			} else {
+				req.Header.Add(k, v)
+			}
+		}
+	}
+}
@@ -1,60 +1,103 @@
 /*
-Package rest provides a built-in HTTP client implementation.
-Note: The full client functionality is currently a work in progress (WIP).
-It aims to provide a high-performance REST client that integrates with the
-framework's service discovery and load-balancing abstractions.
+Package rest implements the built-in HTTP client, fulfilling the core/client/ClientInterface.
+It resolves services through the framework's service discovery, picks instances
+with the registered load balancers and exchanges protobuf messages using the same
+JSON conventions and status envelope as the rest server.
 */
 package rest
 
 import (
-	"context"
+	"net/url"
+	"strings"
+	"sync"
 
-	"github.com/valyala/fasthttp"
+	"github.com/asjard/asjard/core/client"
 	"google.golang.org/grpc/resolver"
-	"google.golang.org/grpc/serviceconfig"
 )
 
-// ClientConnInterface defines the contract for invoking HTTP requests.
-// It abstracts the underlying transport to allow for interceptors and
-// standardized request handling.
-type ClientConnInterface interface {
-	// Invoke executes an HTTP request.
-	// method: HTTP verb (GET, POST, etc.)
-	// path: The resource path on the target service.
-	Invoke(ctx context.Context, method, path string)
-}
+const (
+	// Protocol defines the identifier for this client implementation.
+	Protocol = "rest"
+)
 
-// Client is a wrapper around the fasthttp.Client.
-// It implements gRPC's resolver.ClientConn to allow HTTP services
-// to be discovered using the same naming resolvers (like ETCD or Consul)
-// used by gRPC clients.
+// Client handles the creation of rest connections and maintains global settings
+// like the interceptors.
+// The load balancing strategy of every target service is read from its configuration.
 type Client struct {
-	*fasthttp.Client
+	resolver resolver.Builder
+	// Global interceptor for all connections created by this client.
+	interceptor client.UnaryClientInterceptor
+
+	// conns caches the resolved connections by target,
+	// so that every target is watched by a single resolver.
+	conns map[string]*conn
+	cm    sync.Mutex
 }
 
-// Verify that Client satisfies the gRPC resolver.ClientConn interface at compile time.
-var _ resolver.ClientConn = &Client{}
+func init() {
+	// Automatically register the rest client factory into the framework's client manager.
+	client.AddClient(Protocol, NewClient)
+}
 
-// New creates and returns a new REST client instance.
-func New() *Client {
-	return &Client{}
+// NewClient initializes a Client instance with the resolver and interceptor provided.
+func NewClient(options *client.ClientOptions) client.ClientInterface {
+	c := &Client{
+		resolver:    options.Resolver,
+		interceptor: options.Interceptor,
+		conns:       make(map[string]*conn),
+	}
+	if c.resolver == nil {
+		c.resolver = &client.ClientBuilder{}
+	}
+	return c
 }
 
-// NewAddress is called by the resolver when the list of available
-// service addresses changes. (WIP)
-func (c *Client) NewAddress(addresses []resolver.Address) {}
+// NewConn returns a rest connection to a target.
+// target format: asjard://rest/{ServerName}
+func (c *Client) NewConn(target string, opts ...client.ConnOption) (client.ClientConnInterface, error) {
+	connOptions := &client.ConnOptions{}
+	for _, opt := range opts {
+		opt(connOptions)
+	}
+
+	cn, err := c.getConn(target)
+	if err != nil {
+		return nil, err
+	}
 
-// ParseServiceConfig parses service-level configurations such as
-// load balancing policies or timeouts. (WIP)
-func (c *Client) ParseServiceConfig(serviceConfigJSON string) *serviceconfig.ParseResult {
-	return &serviceconfig.ParseResult{}
+	interceptor := c.interceptor
+	if connOptions.Interceptor != nil {
+		interceptor = client.ChainUnaryInterceptors(c.interceptor, connOptions.Interceptor)
+	}
+	return &ClientConn{
+		conn:        cn,
+		interceptor: interceptor,
+	}, nil
 }
 
-// ReportError allows the resolver to notify the client of discovery failures. (WIP)
-func (c *Client) ReportError(err error) {}
+// getConn returns the cached connection of target or builds a new one.
+func (c *Client) getConn(target string) (*conn, error) {
+	c.cm.Lock()
+	defer c.cm.Unlock()
+	if cn, ok := c.conns[target]; ok {
+		return cn, nil
+	}
 
-// UpdateState updates the client's internal connection state based
-// on information from the resolver. (WIP)
-func (c *Client) UpdateState(state resolver.State) error {
-	return nil
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	serviceName := strings.Trim(u.Path, "/")
+	cn, err := newConn(serviceName, client.GetServiceBalancer(Protocol, serviceName))
+	if err != nil {
+		return nil, err
+	}
+	// Build the resolver last, it pushes the initial instances through UpdateState.
+	r, err := c.resolver.Build(resolver.Target{URL: *u}, cn, resolver.BuildOptions{})
+	if err != nil {
+		return nil, err
+	}
+	cn.resolver = r
+	c.conns[target] = cn
+	return cn, nil
 }
@@ -1,18 +1,151 @@
 package rest
 
 import (
-	"errors"
+	"context"
+	"net"
+	"net/http"
+	"os"
 	"testing"
+	"time"
 
+	"github.com/asjard/asjard/core/client"
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/registry"
+	"github.com/asjard/asjard/core/runtime"
+	"github.com/asjard/asjard/core/server"
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/pkg/protobuf/statuspb"
+	srest "github.com/asjard/asjard/pkg/server/rest"
 	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/resolver"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
-func TestClientResolverContract(t *testing.T) {
-	c := New()
-	require.NotNil(t, c)
-	require.NotNil(t, c.ParseServiceConfig(`{"loadBalancingPolicy":"round_robin"}`))
-	require.NoError(t, c.UpdateState(resolver.State{}))
-	c.NewAddress(nil)
-	c.ReportError(errors.New("resolver error"))
+func TestMain(m *testing.M) {
+	if err := config.Load(-1); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestRoutes(t *testing.T) {
+	AddRoute("/api.v1.Test/Get", "get", "/api/v1/test/{value}")
+	require.Equal(t, route{method: http.MethodGet, path: "/api/v1/test/{value}"}, getRoute("/api.v1.Test/Get"))
+	require.Equal(t, route{method: http.MethodDelete, path: "/api/v1/test"}, getRoute("DELETE /api/v1/test"))
+	require.Equal(t, route{method: http.MethodPost, path: "/api.v1.Test/Create"}, getRoute("/api.v1.Test/Create"))
+}
+
+func TestEncodeRequest(t *testing.T) {
+	t.Run("path and query", func(t *testing.T) {
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		require.NoError(t, encodeRequest(req, "http", "127.0.0.1:80",
+			route{method: http.MethodGet, path: "/users/{value}/{id?}"}, wrapperspb.String("bob")))
+		require.Equal(t, "http://127.0.0.1:80/users/bob", req.URI().String())
+
+		require.NoError(t, encodeRequest(req, "http", "127.0.0.1:80",
+			route{method: http.MethodGet, path: "/users"}, wrapperspb.Bytes([]byte("bob"))))
+		require.Equal(t, "Ym9i", string(req.URI().QueryArgs().Peek("value")))
+	})
+	t.Run("body", func(t *testing.T) {
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		require.NoError(t, encodeRequest(req, "http", "127.0.0.1:80",
+			route{method: http.MethodPost, path: "/users"}, wrapperspb.String("bob")))
+		require.Equal(t, `"bob"`, string(req.Body()))
+		require.Equal(t, "application/json", string(req.Header.ContentType()))
+	})
+	t.Run("escaped path param", func(t *testing.T) {
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		for value, escaped := range map[string]string{
+			"a/b":   "a%2Fb",
+			"a?b=1": "a%3Fb=1",
+			"a#b":   "a%23b",
+			"100%":  "100%25",
+			"a b":   "a%20b",
+		} {
+			require.NoError(t, encodeRequest(req, "http", "127.0.0.1:80",
+				route{method: http.MethodGet, path: "/users/{value}"}, wrapperspb.String(value)))
+			require.Equal(t, "http://127.0.0.1:80/users/"+escaped, req.URI().String())
+			require.Contains(t, req.String(), "GET /users/"+escaped+" HTTP/1.1")
+			require.Empty(t, req.URI().QueryString())
+			require.Empty(t, req.URI().Hash())
+			require.Equal(t, "/users/"+value, string(req.URI().Path()))
+		}
+	})
+	t.Run("missing path param", func(t *testing.T) {
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		require.Error(t, encodeRequest(req, "http", "127.0.0.1:80",
+			route{method: http.MethodGet, path: "/users/{name}"}, wrapperspb.String("bob")))
+	})
+}
+
+func TestInvoke(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &fasthttp.Server{
+		Handler: func(ctx *fasthttp.RequestCtx) {
+			st := &statuspb.Status{Success: true, Status: http.StatusOK}
+			switch string(ctx.Path()) {
+			case "/users/bob":
+				st.Data, _ = anypb.New(wrapperspb.String("hello " + string(ctx.Request.Header.Peek("x-request-dest"))))
+			case "/timeout":
+				st.Data, _ = anypb.New(wrapperspb.String(string(ctx.Request.Header.Peek(srest.HeaderRequestTimeout))))
+			default:
+				st = status.FromError(status.Error(codes.NotFound, "not found"))
+				st.Prompt = "user not found"
+			}
+			b, _ := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(st)
+			ctx.SetContentType("application/json")
+			ctx.SetBody(b)
+		},
+	}
+	go srv.Serve(ln)
+	defer srv.Shutdown()
+
+	cn, err := newConn("test", client.GetBalancer(client.DefaultBalanceRoundRobin))
+	require.NoError(t, err)
+	cc := &ClientConn{conn: cn}
+	require.Equal(t, "test", cc.ServiceName())
+	require.Equal(t, Protocol, cc.Protocol())
+
+	// no instances
+	reply := &wrapperspb.StringValue{}
+	err = cc.Invoke(context.Background(), "GET /users/{value}", wrapperspb.String("bob"), reply)
+	require.Equal(t, uint32(http.StatusServiceUnavailable), status.FromError(err).Status)
+
+	app := runtime.GetAPP()
+	app.Instance.Name = "test"
+	instance := &registry.Instance{Service: &server.Service{APP: app}}
+	require.NoError(t, cn.UpdateState(resolver.State{Addresses: []resolver.Address{{
+		Addr:       ln.Addr().String(),
+		Attributes: attributes.New(client.AddressAttrKey{}, instance).WithValue(client.ListenAddressKey{}, true),
+	}}}))
+
+	cc.interceptor = func(ctx context.Context, method string, req, reply any, cc client.ClientConnInterface, invoker client.UnaryInvoker) error {
+		return invoker(ctx, method, req, reply, cc)
+	}
+	require.NoError(t, cc.Invoke(context.Background(), "GET /users/{value}", wrapperspb.String("bob"), reply))
+	require.Equal(t, "hello test", reply.GetValue())
+
+	// The remaining time is sent to the server.
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, cc.Invoke(timeoutCtx, "GET /timeout", wrapperspb.String("bob"), reply))
+	remaining, err := srest.ParseRequestTimeout(reply.GetValue())
+	require.NoError(t, err)
+	require.InDelta(t, 10*time.Second, remaining, float64(time.Second))
+
+	err = cc.Invoke(context.Background(), "GET /users/{value}", wrapperspb.String("alice"), reply)
+	require.Error(t, err)
+	st := status.FromError(err)
+	require.Equal(t, uint32(http.StatusNotFound), st.Status)
+	require.Equal(t, "user not found", st.Prompt)
 }
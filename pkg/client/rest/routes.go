@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	srest "github.com/asjard/asjard/pkg/server/rest"
+)
+
+// route is the HTTP method and path pattern a full method is sent to.
+type route struct {
+	method string
+	path   string
+}
+
+var (
+	routes = make(map[string]route)
+	rm     sync.RWMutex
+)
+
+// AddRoute maps a full method, e.g. /api.v1.User/Get, to an HTTP method and path.
+// path may contain parameters, e.g. /api/v1/users/{username}, which are filled
+// from the request message fields with the same name.
+func AddRoute(fullMethod, httpMethod, path string) {
+	rm.Lock()
+	routes[fullMethod] = route{method: strings.ToUpper(httpMethod), path: path}
+	rm.Unlock()
+}
+
+// AddServiceDesc registers the routes of a generated rest service description,
+// so that its methods can be invoked with their full method names.
+// Only the first route of a method is used.
+func AddServiceDesc(desc *srest.ServiceDesc) {
+	rm.Lock()
+	defer rm.Unlock()
+	for _, method := range desc.Methods {
+		fullMethod := "/" + desc.ServiceName + "/" + method.MethodName
+		if _, ok := routes[fullMethod]; ok {
+			continue
+		}
+		routes[fullMethod] = route{method: strings.ToUpper(method.Method), path: method.Path}
+	}
+}
+
+// getRoute resolves the route of method.
+// Registered full methods take priority, then explicit routes
+// in the "GET /path" form, otherwise method is sent with POST as the path.
+func getRoute(method string) route {
+	rm.RLock()
+	r, ok := routes[method]
+	rm.RUnlock()
+	if ok {
+		return r
+	}
+	if httpMethod, path, ok := strings.Cut(method, " "); ok {
+		return route{method: strings.ToUpper(httpMethod), path: strings.TrimSpace(path)}
+	}
+	return route{method: http.MethodPost, path: method}
+}
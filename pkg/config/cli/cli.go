@@ -10,17 +10,207 @@ This is essential for:
  2. Environment Injection: Passing secrets or runtime parameters in
     containerized environments (Docker/K8s).
 
-The implementation typically maps flags to configuration keys, supporting
-the framework's unified configuration access pattern.
+Supported arguments:
+
+	--asjard.servers.rest.addresses.listen=:8080  set a key
+	-c asjard.app=example                          set a key
+	--asjard.servers.grpc.options.methods[0]=x     set a list item, same as the file source
+	--config-file=extra.yaml                       read all keys of a file, can be repeated
+	--print-config                                 print the effective configuration and exit
+
+Other arguments are ignored, so the application may parse its own flags,
+e.g. -c without a key=value argument is left to the application.
 */
 package cli
 
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/asjard/asjard/core/config"
+)
+
 const (
 	// Name is the unique identifier for this configuration source.
 	Name = "cli"
 
-	// Priority defines the precedence of this source.
-	// A value of 1 usually indicates a very high priority, ensuring that
-	// CLI arguments override values found in YAML files or environment variables.
+	// Priority defines the loading order of this source.
+	// It is loaded before the file source, so command-line values are
+	// available to the components initialized right after the local files.
 	Priority = 1
+
+	// OverridePriority is the precedence of the command-line values,
+	// they override every source except the memory source.
+	OverridePriority = 98
+
+	// PrintConfigFlag prints the effective configuration and exits.
+	PrintConfigFlag = "--print-config"
+	// ConfigFileFlag reads the keys of a configuration file.
+	ConfigFileFlag = "--config-file"
+	// ConfigFlag sets a single key=value.
+	ConfigFlag = "-c"
 )
+
+// Cli holds the configurations parsed from the command-line arguments.
+type Cli struct {
+	options *config.SourceOptions
+	configs map[string]*config.Value
+}
+
+var printConfig bool
+
+func init() {
+	config.AddSource(Name, Priority, New)
+}
+
+// New parses the command-line arguments of the process.
+func New(options *config.SourceOptions) (config.Sourcer, error) {
+	return newCli(options, os.Args[1:])
+}
+
+func newCli(options *config.SourceOptions, args []string) (*Cli, error) {
+	c := &Cli{
+		options: options,
+		configs: make(map[string]*config.Value),
+	}
+	if err := c.parse(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetAll returns all configurations set from the command line.
+func (c *Cli) GetAll() map[string]*config.Value {
+	configs := make(map[string]*config.Value, len(c.configs))
+	for key, value := range c.configs {
+		configs[key] = value
+	}
+	return configs
+}
+
+// Set is not supported, command-line arguments are read only.
+func (c *Cli) Set(key string, value any) error {
+	return nil
+}
+
+// Disconnect is a no-op for command-line arguments.
+func (c *Cli) Disconnect() {}
+
+// Priority returns the precedence of the command-line values.
+func (c *Cli) Priority() int {
+	return OverridePriority
+}
+
+// Name returns "cli".
+func (c *Cli) Name() string {
+	return Name
+}
+
+// parse reads the arguments, values of a later argument override the earlier ones.
+func (c *Cli) parse(args []string) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			// Everything after "--" belongs to the application.
+			return nil
+		case arg == PrintConfigFlag:
+			printConfig = true
+		case arg == ConfigFlag:
+			// -c may be a flag of the application, e.g. -c app.yaml,
+			// only its key=value arguments are configurations.
+			if i+1 < len(args) && isKeyValue(args[i+1]) {
+				i++
+				c.setKeyValue(args[i])
+			}
+		case arg == ConfigFileFlag:
+			if i+1 >= len(args) {
+				return fmt.Errorf("flag '%s' needs an argument", arg)
+			}
+			i++
+			if err := c.readFile(args[i]); err != nil {
+				return err
+			}
+		case strings.HasPrefix(arg, ConfigFileFlag+"="):
+			if err := c.readFile(strings.TrimPrefix(arg, ConfigFileFlag+"=")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(arg, "--") && strings.Contains(arg, "="):
+			c.setKeyValue(strings.TrimPrefix(arg, "--"))
+		}
+	}
+	return nil
+}
+
+// isKeyValue reports whether an argument is a key=value with a non empty key.
+func isKeyValue(arg string) bool {
+	key, _, ok := strings.Cut(arg, "=")
+	return ok && strings.TrimSpace(key) != "" && !strings.HasPrefix(arg, "-")
+}
+
+func (c *Cli) setKeyValue(keyValue string) {
+	key, value, _ := strings.Cut(keyValue, "=")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return
+	}
+	c.configs[key] = &config.Value{
+		Sourcer: c,
+		Value:   value,
+	}
+}
+
+// readFile reads a configuration file in any format supported by the file source.
+func (c *Cli) readFile(file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	props, err := config.ConvertToProperties(filepath.Ext(file), content)
+	if err != nil {
+		return err
+	}
+	for key, value := range props {
+		c.configs[key] = &config.Value{
+			Sourcer: c,
+			Value:   value,
+			Ref:     file,
+		}
+	}
+	return nil
+}
+
+// PrintConfigEnabled reports whether --print-config is present in the arguments.
+func PrintConfigEnabled() bool {
+	return printConfig
+}
+
+// PrintConfig writes the effective configuration sorted by key,
+// every value is followed by the source it comes from.
+// The encrypted and the sensitive values are masked like the config introspection.
+func PrintConfig(w io.Writer) error {
+	values := config.GetValues()
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := values[key]
+		source := ""
+		if value.Sourcer != nil {
+			source = value.Sourcer.Name()
+		}
+		if value.Ref != "" {
+			source += ":" + value.Ref
+		}
+		if _, err := fmt.Fprintf(w, "%s=%v\t# %s\n", key, config.MaskValue(value), source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
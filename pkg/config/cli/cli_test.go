@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/asjard/asjard/core/config"
+	_ "github.com/asjard/asjard/pkg/config/mem"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	args := os.Args
+	os.Args = append(os.Args[:len(args):len(args)],
+		"--test.cli.list[0]=a", "--test.cli.list[1]=b",
+		"-c", "test.cli.key=value",
+		"--test.cli.override=cli")
+	if err := config.Load(-1); err != nil {
+		panic(err)
+	}
+	// The testing flags are parsed from os.Args.
+	os.Args = args
+	os.Exit(m.Run())
+}
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "extra.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("a:\n  b: file\n  c:\n    - x\n    - y\n"), 0o644))
+
+	c, err := newCli(&config.SourceOptions{}, []string{
+		"--a.b=flag", "--config-file=" + file, "-c", "a.d=1=2", "--ignored", "-test.v=true",
+		"-c", "app.yaml", "-c", "-x=1", "-c",
+		"--", "--a.e=after",
+	})
+	require.NoError(t, err)
+	require.Equal(t, Name, c.Name())
+	require.Equal(t, OverridePriority, c.Priority())
+
+	configs := c.GetAll()
+	require.Equal(t, "file", configs["a.b"].Value, "later arguments override earlier ones")
+	require.Equal(t, file, configs["a.b"].Ref)
+	require.Equal(t, "x", configs["a.c[0]"].Value)
+	require.Equal(t, "1=2", configs["a.d"].Value)
+	require.NotContains(t, configs, "ignored")
+	require.NotContains(t, configs, "a.e")
+	require.Len(t, configs, 4, "the arguments of the application are ignored")
+
+	for _, args := range [][]string{
+		{"--config-file"},
+		{"--config-file=" + filepath.Join(dir, "missing.yaml")},
+		{"--config-file", filepath.Join(dir, "extra.unknown")},
+	} {
+		_, err := newCli(&config.SourceOptions{}, args)
+		require.Error(t, err, args)
+	}
+}
+
+func TestSource(t *testing.T) {
+	require.Equal(t, "value", config.GetString("test.cli.key", ""))
+
+	var conf struct {
+		List []string `json:"list"`
+	}
+	require.NoError(t, config.GetWithUnmarshal("test.cli", &conf))
+	require.Equal(t, []string{"a", "b"}, conf.List)
+
+	require.NoError(t, config.Set("test.cli.override", "mem"))
+	require.Equal(t, "mem", config.GetString("test.cli.override", ""), "mem source overrides cli")
+}
+
+func TestPrintConfig(t *testing.T) {
+	require.False(t, PrintConfigEnabled())
+	var buf bytes.Buffer
+	require.NoError(t, PrintConfig(&buf))
+	require.Contains(t, buf.String(), "test.cli.key=value\t# cli\n")
+	require.Contains(t, buf.String(), "test.cli.list[0]=a\t# cli\n")
+
+	require.NoError(t, config.Set("test.cli.secret", config.ValueEncryptFlag+"base64:c2VjcmV0"))
+	require.Eventually(t, func() bool {
+		return config.Exist("test.cli.secret")
+	}, time.Second, 10*time.Millisecond)
+	buf.Reset()
+	require.NoError(t, PrintConfig(&buf))
+	require.Contains(t, buf.String(), "test.cli.secret="+config.MaskedValue+"\t# mem\n")
+}
@@ -71,11 +71,54 @@ func (s *Consul) GetAll() map[string]*config.Value {
 	return map[string]*config.Value{}
 }
 
-// Set is a placeholder; currently, this source is read-only for the application.
+// Set writes a configuration into the global prefix of the application.
 func (s *Consul) Set(key string, value any) error {
+	return s.SetWithOptions(key, value, &config.SetOptions{Scope: config.ScopeGlobal})
+}
+
+// SetWithOptions writes a configuration into the prefix of a scope,
+// with check-and-set on the ModifyIndex of the key if opts.CAS is set.
+func (s *Consul) SetWithOptions(key string, value any, opts *config.SetOptions) error {
+	consulKey, err := s.scopeKey(key, opts.Scope)
+	if err != nil {
+		return err
+	}
+	v, err := config.MarshalValue(value, opts)
+	if err != nil {
+		return err
+	}
+	pair := &api.KVPair{Key: consulKey, Value: v}
+	if !opts.CAS {
+		_, err = s.client.KV().Put(pair, nil)
+		return err
+	}
+	pair.ModifyIndex = uint64(opts.Revision)
+	ok, _, err := s.client.KV().CAS(pair, nil)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: key '%s' modify index is not %d", config.ErrRevisionConflict, consulKey, opts.Revision)
+	}
 	return nil
 }
 
+// Revision returns the ModifyIndex of a key in the prefix of a scope.
+func (s *Consul) Revision(key, scope string) (int64, error) {
+	consulKey, err := s.scopeKey(key, scope)
+	if err != nil {
+		return 0, err
+	}
+	pair, _, err := s.client.KV().Get(consulKey, nil)
+	if err != nil {
+		return 0, err
+	}
+	if pair == nil {
+		return 0, nil
+	}
+	return int64(pair.ModifyIndex), nil
+}
+
 // Disconnect cleans up any resources if the source is closed.
 func (s *Consul) Disconnect() {}
 
@@ -130,6 +173,16 @@ func (s *Consul) prefix() string {
 	return strings.Join([]string{s.app.App, "configs"}, s.conf.Delimiter)
 }
 
+// scopeKey returns the consul key of a configuration in the prefix of a scope.
+func (s *Consul) scopeKey(key, scope string) (string, error) {
+	paths, err := s.app.ConfigScopePaths(scope)
+	if err != nil {
+		return "", err
+	}
+	paths = append(append([]string{s.prefix()}, paths...), strings.ReplaceAll(key, constant.ConfigDelimiter, s.conf.Delimiter))
+	return strings.Join(paths, s.conf.Delimiter), nil
+}
+
 // configKey converts a Consul KV path back into a standard internal framework config key.
 func (s *Consul) configKey(prefix string, key string) string {
 	return strings.ReplaceAll(strings.TrimPrefix(key, prefix), s.conf.Delimiter, constant.ConfigDelimiter)
@@ -220,7 +273,7 @@ func (w *configWatch) updateConfig(configs map[string]any, modifyIndex uint64) {
 			w.configs[key] = modifyIndex
 			w.s.options.Callback(&config.Event{
 				Type: config.EventTypeCreate,
-				Key:  strings.TrimPrefix(key, w.prefix),
+				Key:  w.s.configKey(w.prefix, key),
 				Value: &config.Value{
 					Sourcer:  w.s,
 					Value:    value,
@@ -279,7 +332,7 @@ func (w *configWatch) handler(_ uint64, data any) {
 			if !exist {
 				w.s.options.Callback(&config.Event{
 					Type: config.EventTypeDelete,
-					Key:  strings.TrimPrefix(key, w.prefix),
+					Key:  w.s.configKey(w.prefix, key),
 					Value: &config.Value{
 						Sourcer:  w.s,
 						Priority: w.priority,
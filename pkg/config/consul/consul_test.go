@@ -1,10 +1,19 @@
 package consul
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/asjard/asjard/core/config"
 	"github.com/asjard/asjard/core/runtime"
+	"github.com/hashicorp/consul/api"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,9 +28,89 @@ func TestConsulSourceMetadataAndKeys(t *testing.T) {
 	require.Equal(t, Name, s.Name())
 	require.Equal(t, Priority, s.Priority())
 	require.Empty(t, s.GetAll())
-	require.NoError(t, s.Set("key", "value"))
 	require.Equal(t, "shop/configs", s.prefix())
 	require.Equal(t, "name", s.configKey("shop/configs/", "shop/configs/name"))
 	require.Len(t, s.prefixs(), 15)
 	s.Disconnect()
 }
+
+// fakeKV serves the consul KV get and put API.
+type fakeKV struct {
+	mu    sync.Mutex
+	index uint64
+	pairs map[string]*api.KVPair
+}
+
+func (f *fakeKV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		pair, ok := f.pairs[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]*api.KVPair{pair})
+	case http.MethodPut:
+		if cas := r.URL.Query().Get("cas"); cas != "" {
+			index, _ := strconv.ParseUint(cas, 10, 64)
+			var current uint64
+			if pair, ok := f.pairs[key]; ok {
+				current = pair.ModifyIndex
+			}
+			if index != current {
+				w.Write([]byte("false"))
+				return
+			}
+		}
+		value, _ := io.ReadAll(r.Body)
+		f.index++
+		f.pairs[key] = &api.KVPair{Key: key, Value: value, ModifyIndex: f.index}
+		w.Write([]byte("true"))
+	}
+}
+
+func TestConsulSet(t *testing.T) {
+	kv := &fakeKV{pairs: make(map[string]*api.KVPair)}
+	srv := httptest.NewServer(kv)
+	defer srv.Close()
+	client, err := api.NewClient(&api.Config{Address: strings.TrimPrefix(srv.URL, "http://")})
+	require.NoError(t, err)
+	s := &Consul{
+		options: &config.SourceOptions{},
+		conf:    &Config{Client: "default", Delimiter: "/"},
+		client:  client,
+		app: runtime.APP{App: "shop", Environment: "prod", Region: "cn", AZ: "a", Instance: runtime.Instance{
+			ID: "id", Name: "api", Group: "service",
+		}},
+	}
+
+	require.NoError(t, s.Set("feature.enabled", true))
+	require.Equal(t, "true", string(kv.pairs["shop/configs/feature/enabled"].Value))
+
+	require.NoError(t, s.SetWithOptions("feature.name", "a", &config.SetOptions{Scope: config.ScopeEnvService, Cipher: "default"}))
+	require.Equal(t, "encrypted_default:a", string(kv.pairs["shop/configs/prod/service/api/feature/name"].Value))
+
+	require.NoError(t, s.SetWithOptions("feature.group", "b", &config.SetOptions{Scope: config.ScopeGroup}))
+	require.Equal(t, "b", string(kv.pairs["shop/configs/service/service/feature/group"].Value))
+
+	_, err = s.scopeKey("key", "unknown")
+	require.Error(t, err)
+
+	t.Run("CAS", func(t *testing.T) {
+		revision, err := s.Revision("feature.cas", config.ScopeService)
+		require.NoError(t, err)
+		require.Zero(t, revision)
+		require.NoError(t, s.SetWithOptions("feature.cas", "1", &config.SetOptions{Scope: config.ScopeService, CAS: true}))
+		err = s.SetWithOptions("feature.cas", "2", &config.SetOptions{Scope: config.ScopeService, CAS: true})
+		require.True(t, errors.Is(err, config.ErrRevisionConflict))
+
+		revision, err = s.Revision("feature.cas", config.ScopeService)
+		require.NoError(t, err)
+		require.NotZero(t, revision)
+		require.NoError(t, s.SetWithOptions("feature.cas", "2", &config.SetOptions{Scope: config.ScopeService, CAS: true, Revision: revision}))
+		require.Equal(t, "2", string(kv.pairs["shop/configs/service/api/feature/cas"].Value))
+	})
+}
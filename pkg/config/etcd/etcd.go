@@ -6,6 +6,7 @@ package etcd
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -109,11 +110,59 @@ func (s *Etcd) GetByKey(key string) any {
 	return nil
 }
 
-// Set is currently a placeholder for programmatic config updates.
+// Set writes a configuration into the global prefix of the application.
 func (s *Etcd) Set(key string, value any) error {
+	return s.SetWithOptions(key, value, &config.SetOptions{Scope: config.ScopeGlobal})
+}
+
+// SetWithOptions writes a configuration into the prefix of a scope,
+// with compare-and-swap on the modification revision of the key if opts.CAS is set.
+func (s *Etcd) SetWithOptions(key string, value any, opts *config.SetOptions) error {
+	etcdKey, err := s.scopeKey(key, opts.Scope)
+	if err != nil {
+		return err
+	}
+	v, err := config.MarshalValue(value, opts)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 3*time.Second)
+	defer cancel()
+	if !opts.CAS {
+		_, err = s.client.Put(ctx, etcdKey, string(v))
+		return err
+	}
+	resp, err := s.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(etcdKey), "=", opts.Revision)).
+		Then(clientv3.OpPut(etcdKey, string(v))).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return fmt.Errorf("%w: key '%s' revision is not %d", config.ErrRevisionConflict, etcdKey, opts.Revision)
+	}
 	return nil
 }
 
+// Revision returns the modification revision of a key in the prefix of a scope.
+func (s *Etcd) Revision(key, scope string) (int64, error) {
+	etcdKey, err := s.scopeKey(key, scope)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 3*time.Second)
+	defer cancel()
+	resp, err := s.client.Get(ctx, etcdKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return 0, nil
+	}
+	return resp.Kvs[0].ModRevision, nil
+}
+
 func (s *Etcd) Priority() int { return Priority }
 func (s *Etcd) Name() string  { return Name }
 func (s *Etcd) Disconnect()   {}
@@ -317,6 +366,16 @@ func (s *Etcd) prefix() string {
 	return strings.Join([]string{"", s.app.App, "configs"}, s.conf.Delimiter)
 }
 
+// scopeKey returns the etcd key of a configuration in the prefix of a scope.
+func (s *Etcd) scopeKey(key, scope string) (string, error) {
+	paths, err := s.app.ConfigScopePaths(scope)
+	if err != nil {
+		return "", err
+	}
+	paths = append(append([]string{s.prefix()}, paths...), strings.ReplaceAll(key, constant.ConfigDelimiter, s.conf.Delimiter))
+	return strings.Join(paths, s.conf.Delimiter), nil
+}
+
 // configKey cleans the etcd key by removing the prefix and normalizing the delimiter.
 func (s *Etcd) configKey(prefix string, key []byte) string {
 	return strings.ReplaceAll(strings.TrimPrefix(string(key), prefix), s.conf.Delimiter, constant.ConfigDelimiter)
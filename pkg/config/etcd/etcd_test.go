@@ -1,9 +1,13 @@
 package etcd
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/runtime"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMain(m *testing.M) {
@@ -20,3 +24,40 @@ func TestPrefix(t *testing.T) {
 	t.Log(source.prefix())
 	t.Log(source.prefixs())
 }
+
+func TestScopeKey(t *testing.T) {
+	source := &Etcd{
+		conf: &defaultConfig,
+		app: runtime.APP{App: "shop", Environment: "prod", Region: "cn", AZ: "a", Instance: runtime.Instance{
+			ID: "id", Name: "api", Group: "grp",
+		}},
+	}
+	for scope, want := range map[string]string{
+		"":                         "/shop/configs/a/b",
+		config.ScopeGlobal:         "/shop/configs/a/b",
+		config.ScopeEnv:            "/shop/configs/prod/a/b",
+		config.ScopeService:        "/shop/configs/service/api/a/b",
+		config.ScopeRegion:         "/shop/configs/service/api/cn/a/b",
+		config.ScopeAZ:             "/shop/configs/service/api/cn/a/a/b",
+		config.ScopeEnvService:     "/shop/configs/prod/service/api/a/b",
+		config.ScopeEnvRegion:      "/shop/configs/prod/service/api/cn/a/b",
+		config.ScopeEnvAZ:          "/shop/configs/prod/service/api/cn/a/a/b",
+		config.ScopeGroup:          "/shop/configs/service/grp/a/b",
+		config.ScopeGroupRegion:    "/shop/configs/service/grp/cn/a/b",
+		config.ScopeGroupAZ:        "/shop/configs/service/grp/cn/a/a/b",
+		config.ScopeEnvGroup:       "/shop/configs/prod/service/grp/a/b",
+		config.ScopeEnvGroupRegion: "/shop/configs/prod/service/grp/cn/a/b",
+		config.ScopeEnvGroupAZ:     "/shop/configs/prod/service/grp/cn/a/a/b",
+		config.ScopeInstance:       "/shop/configs/runtime/id/a/b",
+	} {
+		key, err := source.scopeKey("a.b", scope)
+		require.NoError(t, err)
+		require.Equal(t, want, key, scope)
+		// The key is watched.
+		require.True(t, slices.ContainsFunc(source.prefixs(), func(prefix string) bool {
+			return strings.HasPrefix(key, prefix) && source.configKey(prefix, []byte(key)) == "a.b"
+		}), scope)
+	}
+	_, err := source.scopeKey("a.b", "unknown")
+	require.Error(t, err)
+}
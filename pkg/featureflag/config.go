@@ -0,0 +1,41 @@
+package featureflag
+
+import (
+	"github.com/asjard/asjard/utils"
+)
+
+// Config defines the feature flags.
+type Config struct {
+	// UserKey is the request header or metadata carrying the user ID.
+	UserKey string `json:"userKey"`
+	// Flags are the feature flags by name.
+	Flags map[string]*FlagConfig `json:"flags"`
+}
+
+// FlagConfig defines a feature flag.
+// A flag is turned off unless it is enabled, an enabled flag without rules is turned on
+// for every request, otherwise for the requests matching one of its rules.
+type FlagConfig struct {
+	Enabled bool          `json:"enabled"`
+	Rules   []*RuleConfig `json:"rules"`
+}
+
+// RuleConfig matches the requests meeting all of its conditions, empty conditions match every request.
+type RuleConfig struct {
+	// Apps are the caller applications, from x-request-app.
+	Apps utils.JSONStrings `json:"apps"`
+	// Regions are the regions of the request, from x-request-region.
+	Regions utils.JSONStrings `json:"regions"`
+	// Azs are the availability zones of the request, from x-request-az.
+	Azs utils.JSONStrings `json:"azs"`
+	// Users are the user IDs, from the UserKey header or metadata.
+	Users utils.JSONStrings `json:"users"`
+	// Percent is the percentage of the users the rule is rolled out to, nil means all of them
+	// and 0 none of them.
+	// A user always gets the same result, the caller application is used if the user is unknown.
+	Percent *float64 `json:"percent"`
+}
+
+var defaultConfig = Config{
+	UserKey: "x-user-id",
+}
@@ -0,0 +1,15 @@
+/*
+Package featureflag evaluates feature flags defined in the configuration under asjard.featureFlags.
+
+A flag is turned on for a request by its rules, which match the caller application,
+the region and availability zone of the request, the user ID, or a stable percentage
+of the users. Flag definitions are reloaded when the configuration changes.
+
+	if featureflag.Enabled(ctx, "newCheckout") {
+		// ...
+	}
+
+The featureFlag server interceptor attaches the flags of the request to its context,
+so a flag is evaluated once per request and keeps its value for the whole request.
+*/
+package featureflag
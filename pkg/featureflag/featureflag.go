@@ -0,0 +1,238 @@
+package featureflag
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+
+	"github.com/asjard/asjard/core/client"
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/metrics"
+	"github.com/asjard/asjard/pkg/server/rest"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// EvaluationsMetricName is the counter of the flag evaluations by flag and result.
+	EvaluationsMetricName = "feature_flag_evaluations_total"
+
+	// percentScale is the number of buckets of the percentage rollouts, 0.01% each.
+	percentScale = 10000
+)
+
+// Target is the request a flag is evaluated for.
+type Target struct {
+	App    string
+	Region string
+	Az     string
+	User   string
+}
+
+// Flags are the feature flags of a request, every flag is evaluated the first time it is read
+// and keeps its value for the rest of the request.
+type Flags struct {
+	target Target
+
+	mu     sync.Mutex
+	values map[string]bool
+}
+
+type flagsKey struct{}
+
+// evaluator evaluates the flags with the current configuration.
+type evaluator struct {
+	conf        *config.Binding[Config]
+	evaluations *prometheus.CounterVec
+}
+
+var (
+	defaultEvaluator *evaluator
+	evaluatorOnce    sync.Once
+)
+
+// getEvaluator loads and watches the flag configuration on first use.
+func getEvaluator() *evaluator {
+	evaluatorOnce.Do(func() {
+		e := &evaluator{
+			evaluations: metrics.RegisterCounter(EvaluationsMetricName,
+				"The total number of feature flag evaluations",
+				[]string{"flag", "result"}),
+		}
+		conf, _, err := config.Bind(constant.ConfigFeatureFlagPrefix, defaultConfig)
+		if err != nil {
+			logger.Error("load feature flags fail, every flag is off", "err", err)
+		}
+		e.conf = conf
+		defaultEvaluator = e
+	})
+	return defaultEvaluator
+}
+
+// Enabled reports whether a flag is on for the request of ctx.
+// The flags attached by the featureFlag interceptor are used if any.
+func Enabled(ctx context.Context, name string) bool {
+	if flags := FromContext(ctx); flags != nil {
+		return flags.Enabled(name)
+	}
+	return Evaluate(name, TargetFromContext(ctx))
+}
+
+// Evaluate reports whether a flag is on for a target.
+func Evaluate(name string, target Target) bool {
+	e := getEvaluator()
+	enabled := e.config().evaluate(name, target)
+	if e.evaluations != nil {
+		e.evaluations.With(prometheus.Labels{
+			"flag":   name,
+			"result": strconv.FormatBool(enabled),
+		}).Inc()
+	}
+	return enabled
+}
+
+// TargetFromContext reads the target from the headers of a rest request
+// or the incoming metadata of other protocols.
+func TargetFromContext(ctx context.Context) Target {
+	return Target{
+		App:    requestMetadata(ctx, client.HeaderRequestApp),
+		Region: requestMetadata(ctx, client.HeaderRequestRegion),
+		Az:     requestMetadata(ctx, client.HeaderRequestAz),
+		User:   requestMetadata(ctx, getEvaluator().config().UserKey),
+	}
+}
+
+// NewFlags returns the flags of a target.
+func NewFlags(target Target) *Flags {
+	return &Flags{
+		target: target,
+		values: make(map[string]bool),
+	}
+}
+
+// NewContext returns a context carrying the flags.
+// The flags are attached to the rest context itself, as well as to its Context().
+func NewContext(ctx context.Context, flags *Flags) context.Context {
+	if rtx, ok := ctx.(*rest.Context); ok {
+		rtx.SetUserValue(flagsKey{}, flags)
+		rtx.SetContext(context.WithValue(rtx.Context(), flagsKey{}, flags))
+		return rtx
+	}
+	return context.WithValue(ctx, flagsKey{}, flags)
+}
+
+// FromContext returns the flags attached to ctx, nil if there are none.
+func FromContext(ctx context.Context) *Flags {
+	flags, _ := ctx.Value(flagsKey{}).(*Flags)
+	return flags
+}
+
+// Target returns the target the flags are evaluated for.
+func (f *Flags) Target() Target {
+	return f.target
+}
+
+// Enabled reports whether a flag is on.
+func (f *Flags) Enabled(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	enabled, ok := f.values[name]
+	if !ok {
+		enabled = Evaluate(name, f.target)
+		f.values[name] = enabled
+	}
+	return enabled
+}
+
+// All evaluates every configured flag.
+func (f *Flags) All() map[string]bool {
+	all := make(map[string]bool)
+	for name := range getEvaluator().config().Flags {
+		all[name] = f.Enabled(name)
+	}
+	return all
+}
+
+// config returns the current configuration, the default one if it failed to load.
+func (e *evaluator) config() *Config {
+	if e.conf == nil {
+		return &defaultConfig
+	}
+	return e.conf.Load()
+}
+
+func (c *Config) evaluate(name string, target Target) bool {
+	flag, ok := c.Flags[name]
+	if !ok || flag == nil || !flag.Enabled {
+		return false
+	}
+	if len(flag.Rules) == 0 {
+		return true
+	}
+	for _, rule := range flag.Rules {
+		if rule != nil && rule.match(name, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RuleConfig) match(name string, target Target) bool {
+	if len(r.Apps) != 0 && !r.Apps.Contains(target.App) {
+		return false
+	}
+	if len(r.Regions) != 0 && !r.Regions.Contains(target.Region) {
+		return false
+	}
+	if len(r.Azs) != 0 && !r.Azs.Contains(target.Az) {
+		return false
+	}
+	if len(r.Users) != 0 && !r.Users.Contains(target.User) {
+		return false
+	}
+	if r.Percent == nil || *r.Percent >= 100 {
+		return true
+	}
+	if *r.Percent <= 0 {
+		return false
+	}
+	key := target.User
+	if key == "" {
+		key = target.App
+	}
+	if key == "" {
+		// Without a user or an application the result would not be stable.
+		return false
+	}
+	return bucket(name, key) < uint32(*r.Percent*percentScale/100)
+}
+
+// bucket returns the stable bucket of a key for a flag,
+// the flag name is part of the hash so the flags are rolled out to different users.
+func bucket(name, key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(name + ":" + key))
+	return h.Sum32() % percentScale
+}
+
+// requestMetadata reads a header of a rest request or the incoming metadata of other protocols.
+func requestMetadata(ctx context.Context, key string) string {
+	if key == "" {
+		return ""
+	}
+	if rtx, ok := ctx.(*rest.Context); ok {
+		if values := rtx.GetHeaderParam(key); len(values) != 0 {
+			return values[0]
+		}
+		return ""
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) != 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
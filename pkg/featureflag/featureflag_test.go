@@ -0,0 +1,116 @@
+package featureflag
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/server"
+	_ "github.com/asjard/asjard/pkg/config/mem"
+	"github.com/asjard/asjard/utils"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestMain(m *testing.M) {
+	if err := config.Load(-1); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestRuleMatch(t *testing.T) {
+	conf := &Config{
+		Flags: map[string]*FlagConfig{
+			"off":  {Rules: []*RuleConfig{{}}},
+			"all":  {Enabled: true},
+			"apps": {Enabled: true, Rules: []*RuleConfig{{Apps: utils.JSONStrings{"web"}}}},
+			"zone": {Enabled: true, Rules: []*RuleConfig{
+				{Regions: utils.JSONStrings{"east"}, Azs: utils.JSONStrings{"az-1"}},
+				{Users: utils.JSONStrings{"u1"}},
+			}},
+		},
+	}
+	target := Target{App: "web", Region: "east", Az: "az-2", User: "u2"}
+	require.False(t, conf.evaluate("off", target))
+	require.False(t, conf.evaluate("missing", target))
+	require.True(t, conf.evaluate("all", target))
+	require.True(t, conf.evaluate("apps", target))
+	require.False(t, conf.evaluate("apps", Target{App: "mobile"}))
+	require.False(t, conf.evaluate("zone", target))
+	require.True(t, conf.evaluate("zone", Target{Region: "east", Az: "az-1"}))
+	require.True(t, conf.evaluate("zone", Target{User: "u1"}))
+}
+
+func TestPercentRollout(t *testing.T) {
+	percent := func(p float64) *float64 { return &p }
+	rule := &RuleConfig{Percent: percent(30)}
+	matched := 0
+	for i := range 10000 {
+		target := Target{User: "user-" + strconv.Itoa(i)}
+		result := rule.match("rollout", target)
+		// The result of a user is stable.
+		require.Equal(t, result, rule.match("rollout", target))
+		if result {
+			matched++
+		}
+	}
+	require.InDelta(t, 3000, matched, 300)
+
+	require.False(t, rule.match("rollout", Target{}), "partial rollouts need a user or an application")
+	require.True(t, (&RuleConfig{Percent: percent(100)}).match("rollout", Target{}))
+	require.True(t, (&RuleConfig{}).match("rollout", Target{}))
+	// A rule rolled back to 0 matches nobody.
+	require.False(t, (&RuleConfig{Percent: percent(0)}).match("rollout", Target{User: "u1"}))
+}
+
+func TestEnabledWithConfig(t *testing.T) {
+	name := "flag" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	prefix := fmt.Sprintf("%s.flags.%s", constant.ConfigFeatureFlagPrefix, name)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-request-app", "web",
+		"x-user-id", "u1",
+	))
+	require.False(t, Enabled(ctx, name))
+
+	require.NoError(t, config.Set(prefix+".enabled", true))
+	require.NoError(t, config.Set(prefix+".rules", []map[string]any{{"apps": []string{"web"}}}))
+	require.Eventually(t, func() bool {
+		return Enabled(ctx, name)
+	}, time.Second, 10*time.Millisecond)
+	require.False(t, Enabled(context.Background(), name))
+	require.Equal(t, Target{App: "web", User: "u1"}, TargetFromContext(ctx))
+}
+
+func TestInterceptor(t *testing.T) {
+	name := "flag" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	prefix := fmt.Sprintf("%s.flags.%s", constant.ConfigFeatureFlagPrefix, name)
+	require.NoError(t, config.Set(prefix+".enabled", true))
+	require.Eventually(t, func() bool {
+		return Evaluate(name, Target{})
+	}, time.Second, 10*time.Millisecond)
+
+	interceptor, err := NewInterceptor()
+	require.NoError(t, err)
+	require.Equal(t, InterceptorName, interceptor.Name())
+	_, err = interceptor.Interceptor()(context.Background(), nil, &server.UnaryServerInfo{FullMethod: "/test"}, func(ctx context.Context, req any) (any, error) {
+		flags := FromContext(ctx)
+		require.NotNil(t, flags)
+		require.True(t, flags.Enabled(name))
+
+		// The flag keeps its value for the whole request.
+		require.NoError(t, config.Set(prefix+".enabled", false))
+		require.Eventually(t, func() bool {
+			return !Evaluate(name, Target{})
+		}, time.Second, 10*time.Millisecond)
+		require.True(t, Enabled(ctx, name))
+		require.True(t, flags.All()[name])
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,48 @@
+package featureflag
+
+import (
+	"context"
+
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/server"
+)
+
+const (
+	// InterceptorName is the name of the server interceptor attaching the flags to the requests.
+	InterceptorName = "featureFlag"
+)
+
+// Interceptor attaches the flags of the request to its context, read with Enabled or FromContext.
+type Interceptor struct{}
+
+func init() {
+	server.AddInterceptor(InterceptorName, NewInterceptor)
+}
+
+// NewInterceptor creates the feature flag interceptor and loads the flags.
+func NewInterceptor() (server.ServerInterceptor, error) {
+	getEvaluator()
+	return &Interceptor{}, nil
+}
+
+// Name returns the interceptor's unique name.
+func (*Interceptor) Name() string {
+	return InterceptorName
+}
+
+// Interceptor returns the middleware attaching the flags to unary requests.
+func (i *Interceptor) Interceptor() server.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *server.UnaryServerInfo, handler server.UnaryHandler) (resp any, err error) {
+		logger.L(ctx).Debug("start server interceptor", "interceptor", i.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+		return handler(NewContext(ctx, NewFlags(TargetFromContext(ctx))), req)
+	}
+}
+
+// StreamInterceptor returns the middleware attaching the flags to streams.
+func (i *Interceptor) StreamInterceptor() server.StreamServerInterceptor {
+	return func(srv any, ss server.ServerStream, info *server.StreamServerInfo, handler server.StreamHandler) error {
+		ctx := ss.Context()
+		logger.L(ctx).Debug("start server stream interceptor", "interceptor", i.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+		return handler(srv, server.WrapServerStream(ss, NewContext(ctx, NewFlags(TargetFromContext(ctx)))))
+	}
+}
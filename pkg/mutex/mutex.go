@@ -2,7 +2,6 @@ package mutex
 
 import (
 	"context"
-	"math/rand"
 	"time"
 
 	"github.com/asjard/asjard/core/runtime"
@@ -23,6 +22,17 @@ type Locker interface {
 	KeepAlive(ctx context.Context, key, threadId string, expiresIn time.Duration) bool
 }
 
+// FencingLocker is optionally implemented by a Locker able to provide fencing tokens.
+//
+// A fencing token increases on every acquisition of a lock, a storage receiving
+// the token with every write can reject the writes of a holder whose lock expired
+// and was acquired by someone else in the meantime.
+type FencingLocker interface {
+	Locker
+	// FencingToken returns the token of the lock held by threadId.
+	FencingToken(ctx context.Context, key, threadId string) (int64, bool)
+}
+
 // noCopy is a sentinel used to prevent the Mutex struct from being copied by value.
 // Copying a mutex can lead to logical errors where two different instances
 // think they are controlling the same lock state.
@@ -40,14 +50,12 @@ type Mutex struct {
 
 // Lock manually acquires a lock for a specific duration.
 func (m *Mutex) Lock(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
-	key = m.resourceKey(key)
-	return m.Locker.Lock(ctx, key, threadId, expiresIn)
+	return m.Locker.Lock(ctx, resourceKey(key), threadId, expiresIn)
 }
 
 // Unlock manually releases a lock.
 func (m *Mutex) Unlock(ctx context.Context, key, threadId string) bool {
-	key = m.resourceKey(key)
-	return m.Locker.Unlock(ctx, key, threadId)
+	return m.Locker.Unlock(ctx, resourceKey(key), threadId)
 }
 
 // TryLock executes the 'do' function if the lock is successfully acquired.
@@ -57,17 +65,33 @@ func (m *Mutex) TryLock(ctx context.Context, key string, do func() error, opts .
 	if m.Locker == nil {
 		return status.Error(codes.Internal, "locker is required")
 	}
+	var fencingToken func(ctx context.Context, key, threadId string) (int64, bool)
+	if locker, ok := m.Locker.(FencingLocker); ok {
+		fencingToken = locker.FencingToken
+	}
+	return tryLock(ctx, resourceKey(key), do, m.Locker.Lock, m.Locker.Unlock, m.Locker.KeepAlive, fencingToken, opts...)
+}
 
-	options := m.defaultLockOptions()
+// tryLock acquires a lock through lock with jittered retries, keeps it alive while 'do' is running
+// and releases it through unlock. It is shared by the exclusive and the shared locks.
+func tryLock(ctx context.Context, key string, do func() error,
+	lock func(ctx context.Context, key, threadId string, expiresIn time.Duration) bool,
+	unlock func(ctx context.Context, key, threadId string) bool,
+	keepAlive func(ctx context.Context, key, threadId string, expiresIn time.Duration) bool,
+	fencingToken func(ctx context.Context, key, threadId string) (int64, bool),
+	opts ...LockOption) error {
+	options := defaultLockOptions()
 	for _, opt := range opts {
 		opt(options)
 	}
+	if options.fencingToken != nil && fencingToken == nil {
+		return status.Error(codes.Internal, "locker does not support fencing tokens")
+	}
 
-	key = m.resourceKey(key)
 	for i := 0; i < options.maxRetries; i++ {
-		if m.Locker.Lock(ctx, key, options.threadId, options.expiresIn.Duration) {
+		if lock(ctx, key, options.threadId, options.expiresIn.Duration) {
 			// Ensure the lock is released when 'do' completes or the function exits.
-			defer m.Locker.Unlock(ctx, key, options.threadId)
+			defer unlock(ctx, key, options.threadId)
 
 			// Watchdog: Start a goroutine to keep the lock alive.
 			exit := make(chan struct{})
@@ -79,11 +103,19 @@ func (m *Mutex) TryLock(ctx context.Context, key string, do func() error, opts .
 						return
 					// Renew the lock when 2/3 of its TTL has elapsed.
 					case <-time.After(options.expiresIn.Duration - (options.expiresIn.Duration / 3)):
-						m.Locker.KeepAlive(ctx, key, options.threadId, options.expiresIn.Duration)
+						keepAlive(ctx, key, options.threadId, options.expiresIn.Duration)
 					}
 				}
 			}()
 
+			if options.fencingToken != nil {
+				token, ok := fencingToken(ctx, key, options.threadId)
+				if !ok {
+					return status.Errorf(status.GetLockFailCode, "failed to get fencing token")
+				}
+				*options.fencingToken = token
+			}
+
 			// Execute the protected business logic.
 			return do()
 		}
@@ -93,21 +125,25 @@ func (m *Mutex) TryLock(ctx context.Context, key string, do func() error, opts .
 		}
 
 		// Backoff with jitter to prevent "thundering herd" effect on the locker storage.
-		time.Sleep(time.Duration(rand.Int63n(int64(options.maxRetryDelayDuration-options.minRetryDelayDuration))) + options.minRetryDelayDuration)
+		select {
+		case <-ctx.Done():
+			return status.Errorf(status.GetLockFailCode, "failed to acquire lock: %v", ctx.Err())
+		case <-time.After(options.retryDelay()):
+		}
 	}
 
 	return status.Errorf(status.GetLockFailCode, "failed to acquire lock after %d retries", options.maxRetries)
 }
 
 // resourceKey generates a standardized, namespaced key (e.g., app:lock:my_key).
-func (m *Mutex) resourceKey(key string) string {
+func resourceKey(key string) string {
 	return runtime.GetAPP().ResourceKey("lock", key,
 		runtime.WithoutAz(true),
 		runtime.WithDelimiter(":"))
 }
 
 // defaultLockOptions provides sensible defaults for lock behavior.
-func (m *Mutex) defaultLockOptions() *LockOptions {
+func defaultLockOptions() *LockOptions {
 	return &LockOptions{
 		expiresIn:             utils.JSONDuration{Duration: 5 * time.Minute},
 		maxRetries:            1,
@@ -12,6 +12,7 @@ import (
 
 type testLock struct {
 	val uint64
+	mu  sync.Mutex
 }
 
 // Lock 加锁
@@ -62,3 +63,134 @@ func TestLock(t *testing.T) {
 		}
 	})
 }
+
+type testRWLock struct {
+	testLock
+	readers int64
+}
+
+func (l *testRWLock) RLock(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if atomic.LoadUint64(&l.val) != 0 {
+		return false
+	}
+	l.readers++
+	return true
+}
+
+func (l *testRWLock) RUnlock(ctx context.Context, key, threadId string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.readers--
+	return true
+}
+
+func (l *testRWLock) RKeepAlive(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	return true
+}
+
+func (l *testRWLock) Lock(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.readers == 0 && l.testLock.Lock(ctx, key, threadId, expiresIn)
+}
+
+func TestRWLock(t *testing.T) {
+	m := RWMutex{RWLocker: &testRWLock{}}
+	key := "test_do_with_rwlock"
+
+	t.Run("shared", func(t *testing.T) {
+		var readers, maxReaders int64
+		wg := sync.WaitGroup{}
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := m.TryRLock(context.Background(), key, func() error {
+					current := atomic.AddInt64(&readers, 1)
+					for {
+						max := atomic.LoadInt64(&maxReaders)
+						if current <= max || atomic.CompareAndSwapInt64(&maxReaders, max, current) {
+							break
+						}
+					}
+					time.Sleep(50 * time.Millisecond)
+					atomic.AddInt64(&readers, -1)
+					return nil
+				}); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+		if maxReaders < 2 {
+			t.Error("readers not shared")
+		}
+	})
+
+	t.Run("exclusive", func(t *testing.T) {
+		threadId := "writer"
+		if !m.Lock(context.Background(), key, threadId, time.Second) {
+			t.Fatal("lock fail")
+		}
+		if err := m.TryRLock(context.Background(), key, func() error { return nil }, WithMaxRetries(2)); err == nil {
+			t.Error("read while writing")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		if err := m.TryLock(ctx, key, func() error { return nil }, WithMaxRetries(-1)); err == nil {
+			t.Error("lock while writing")
+		}
+		if !m.Unlock(context.Background(), key, threadId) {
+			t.Fatal("unlock fail")
+		}
+		if err := m.TryLock(context.Background(), key, func() error { return nil }); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("no locker", func(t *testing.T) {
+		if err := (&RWMutex{}).TryRLock(context.Background(), key, func() error { return nil }); err == nil {
+			t.Error("want error without locker")
+		}
+	})
+}
+
+type testFencingLock struct {
+	testLock
+	token int64
+}
+
+func (l *testFencingLock) Lock(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	if l.testLock.Lock(ctx, key, threadId, expiresIn) {
+		atomic.AddInt64(&l.token, 1)
+		return true
+	}
+	return false
+}
+
+func (l *testFencingLock) FencingToken(ctx context.Context, key, threadId string) (int64, bool) {
+	return atomic.LoadInt64(&l.token), true
+}
+
+func TestFencingToken(t *testing.T) {
+	m := Mutex{Locker: &testFencingLock{}}
+	for i := int64(1); i <= 3; i++ {
+		var token int64
+		if err := m.TryLock(context.Background(), "test_fencing_token", func() error {
+			if token != i {
+				t.Errorf("fencing token %d, want %d", token, i)
+			}
+			return nil
+		}, WithFencingToken(&token)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	var token int64
+	if err := (&Mutex{Locker: &testLock{}}).TryLock(context.Background(), "test_fencing_token",
+		func() error { return nil }, WithFencingToken(&token)); err == nil {
+		t.Error("want error without fencing token support")
+	}
+}
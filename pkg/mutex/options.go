@@ -2,6 +2,7 @@ package mutex
 
 import (
 	"math"
+	"math/rand"
 	"time"
 
 	"github.com/asjard/asjard/utils"
@@ -25,6 +26,9 @@ type LockOptions struct {
 	// threadId uniquely identifies the owner of the lock.
 	// Only the holder of this ID can extend or release the lock.
 	threadId string
+
+	// fencingToken receives the fencing token of the acquired lock.
+	fencingToken *int64
 }
 
 // LockOption is a function type used to modify LockOptions.
@@ -64,3 +68,35 @@ func WithMaxRetryDelayDuration(duration time.Duration) LockOption {
 		options.maxRetryDelayDuration = duration
 	}
 }
+
+// WithThreadId sets the owner of the lock, by default a random one is generated per call.
+// Calls sharing a threadId are treated as the same owner,
+// e.g. a read lock can be acquired again by its owner while a writer is waiting.
+func WithThreadId(threadId string) LockOption {
+	return func(options *LockOptions) {
+		options.threadId = threadId
+	}
+}
+
+// WithFencingToken stores the fencing token of the acquired lock in token before 'do' is executed.
+// The locker must implement FencingLocker, otherwise TryLock fails.
+//
+// Example:
+//
+//	var token int64
+//	m.TryLock(ctx, key, func() error {
+//		return store.Write(ctx, value, token)
+//	}, mutex.WithFencingToken(&token))
+func WithFencingToken(token *int64) LockOption {
+	return func(options *LockOptions) {
+		options.fencingToken = token
+	}
+}
+
+// retryDelay returns a random delay between the min and max retry delay durations.
+func (o *LockOptions) retryDelay() time.Duration {
+	if o.maxRetryDelayDuration <= o.minRetryDelayDuration {
+		return o.minRetryDelayDuration
+	}
+	return time.Duration(rand.Int63n(int64(o.maxRetryDelayDuration-o.minRetryDelayDuration))) + o.minRetryDelayDuration
+}
@@ -1,24 +1,83 @@
 package mutex
 
+import (
+	"context"
+	"time"
+
+	"github.com/asjard/asjard/core/status"
+	"google.golang.org/grpc/codes"
+)
+
+// WriterIntentExpiresIn is how long a writer waiting for the readers to leave
+// keeps new readers out after its last attempt.
+// Writers retrying within this duration are not starved by a continuous flow of readers.
+const WriterIntentExpiresIn = time.Second
+
 // RWLocker defines the contract for a distributed read-write lock implementation.
 // It extends the basic Locker interface, adding specialized methods for
 // shared (read) access.
+//
+// Implementations must give preference to writers: a Lock failing because of
+// active readers records the intent of the writer for WriterIntentExpiresIn,
+// during which RLock fails for new readers.
 type RWLocker interface {
 	// Locker provides the standard Lock, Unlock, and KeepAlive methods for exclusive writing.
 	Locker
 
 	// RLock attempts to acquire a shared read lock.
 	// Multiple callers can hold a read lock simultaneously, provided no exclusive
-	// write lock is active.
-	RLock()
+	// write lock is active or waiting.
+	RLock(ctx context.Context, key, threadId string, expiresIn time.Duration) bool
 
 	// RUnlock releases a previously acquired shared read lock.
-	RUnlock()
+	RUnlock(ctx context.Context, key, threadId string) bool
+
+	// RKeepAlive extends the expiration time of a shared read lock.
+	RKeepAlive(ctx context.Context, key, threadId string, expiresIn time.Duration) bool
 }
 
 // RWMutex is a high-level wrapper that provides the distributed read-write locking logic.
 // It uses an underlying RWLocker implementation (e.g., backed by Redis or ETCD).
 type RWMutex struct {
+	noCopy noCopy
 	// RWLocker is the concrete implementation of the distributed locking primitives.
 	RWLocker RWLocker
 }
+
+// Lock manually acquires the exclusive lock for a specific duration.
+func (m *RWMutex) Lock(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	return m.RWLocker.Lock(ctx, resourceKey(key), threadId, expiresIn)
+}
+
+// Unlock manually releases the exclusive lock.
+func (m *RWMutex) Unlock(ctx context.Context, key, threadId string) bool {
+	return m.RWLocker.Unlock(ctx, resourceKey(key), threadId)
+}
+
+// RLock manually acquires a shared lock for a specific duration.
+func (m *RWMutex) RLock(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	return m.RWLocker.RLock(ctx, resourceKey(key), threadId, expiresIn)
+}
+
+// RUnlock manually releases a shared lock.
+func (m *RWMutex) RUnlock(ctx context.Context, key, threadId string) bool {
+	return m.RWLocker.RUnlock(ctx, resourceKey(key), threadId)
+}
+
+// TryLock executes the 'do' function while holding the exclusive lock,
+// with the same retries and watchdog as Mutex.TryLock.
+func (m *RWMutex) TryLock(ctx context.Context, key string, do func() error, opts ...LockOption) error {
+	if m.RWLocker == nil {
+		return status.Error(codes.Internal, "rwlocker is required")
+	}
+	return tryLock(ctx, resourceKey(key), do, m.RWLocker.Lock, m.RWLocker.Unlock, m.RWLocker.KeepAlive, nil, opts...)
+}
+
+// TryRLock executes the 'do' function while holding a shared lock,
+// with the same retries and watchdog as Mutex.TryLock.
+func (m *RWMutex) TryRLock(ctx context.Context, key string, do func() error, opts ...LockOption) error {
+	if m.RWLocker == nil {
+		return status.Error(codes.Internal, "rwlocker is required")
+	}
+	return tryLock(ctx, resourceKey(key), do, m.RWLocker.RLock, m.RWLocker.RUnlock, m.RWLocker.RKeepAlive, nil, opts...)
+}
@@ -0,0 +1,59 @@
+package authzpb
+
+import (
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// methods caches the authorization options by full method name, nil if the method has none.
+var methods sync.Map
+
+// AddMethodAuthz sets the authorization option of a method,
+// the rest server adds the options generated into its MethodDesc.
+func AddMethodAuthz(fullMethod string, authz *Authz) {
+	methods.Store(fullMethod, authz)
+}
+
+// GetMethodAuthz returns the authorization option of a method, e.g. /api.v1.User/Delete.
+// If it was not added by AddMethodAuthz, it is read from the method descriptor
+// registered by the generated code, which covers the gRPC methods.
+// It returns nil if the method has no option.
+func GetMethodAuthz(fullMethod string) *Authz {
+	if authz, ok := methods.Load(fullMethod); ok {
+		return authz.(*Authz)
+	}
+	authz := lookupMethodAuthz(fullMethod)
+	methods.Store(fullMethod, authz)
+	return authz
+}
+
+// lookupMethodAuthz reads the option of a method from the global registry.
+func lookupMethodAuthz(fullMethod string) *Authz {
+	serviceName, methodName, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return nil
+	}
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil
+	}
+	options, ok := method.Options().(*descriptorpb.MethodOptions)
+	if !ok || options == nil {
+		return nil
+	}
+	authz, _ := proto.GetExtension(options, E_Authz).(*Authz)
+	return authz
+}
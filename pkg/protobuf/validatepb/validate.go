@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/asjard/asjard/core/config"
 	"github.com/asjard/asjard/core/status"
 	"github.com/go-playground/validator/v10"
 	"google.golang.org/grpc/codes"
@@ -34,6 +35,8 @@ func init() {
 	if err := DefaultValidator.RegisterValidation("country_code", isCountryCode); err != nil {
 		panic(err)
 	}
+	// Validate the configurations bound by config.Bind.
+	config.SetDefaultValidator(DefaultValidator)
 }
 
 func isSortField(fl validator.FieldLevel) bool {
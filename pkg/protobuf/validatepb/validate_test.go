@@ -38,3 +38,15 @@ func TestCountryCodeValidation(t *testing.T) {
 	}
 	require.Error(t, DefaultValidator.Struct(invalidMode{Code: "CN"}))
 }
+
+func TestBindValidation(t *testing.T) {
+	type conf struct {
+		Size int `json:"size" validate:"gte=1"`
+	}
+	_, _, err := config.Bind("test_bind_validation", conf{})
+	require.Error(t, err)
+	binding, cancel, err := config.Bind("test_bind_validation", conf{Size: 1})
+	require.NoError(t, err)
+	defer cancel()
+	require.Equal(t, 1, binding.Load().Size)
+}
@@ -0,0 +1,8 @@
+/*
+Package quota defines the stores keeping the counters of cluster-wide quotas,
+shared by all instances of a service, e.g. the quota server interceptor.
+
+The stores are registered by the store packages, e.g. xredis registers the "redis" store,
+so the quota users and the stores do not depend on each other.
+*/
+package quota
@@ -0,0 +1,56 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Store keeps the quota counters shared by all instances.
+type Store interface {
+	// Take consumes a request from the bucket key, which allows a request every interval
+	// and up to burst requests at once.
+	// It returns whether the request is allowed, the remaining requests
+	// and how long to wait before retrying when rejected.
+	Take(ctx context.Context, key string, interval time.Duration, burst int) (allowed bool, remaining int, retryAfter time.Duration, err error)
+}
+
+// NewStoreFunc creates a quota store using the named client of the store.
+type NewStoreFunc func(clientName string) (Store, error)
+
+// ErrStoreNotFound is returned by NewStore if no store is registered with the name.
+var ErrStoreNotFound = errors.New("quota store not found")
+
+var (
+	stores = make(map[string]NewStoreFunc)
+	sm     sync.RWMutex
+)
+
+// AddStore registers a quota store, e.g. xredis registers the redis store.
+// This is typically called from an 'init' function.
+func AddStore(name string, newStore NewStoreFunc) {
+	sm.Lock()
+	stores[name] = newStore
+	sm.Unlock()
+}
+
+// HasStore reports whether a store is registered with the name.
+func HasStore(name string) bool {
+	sm.RLock()
+	_, ok := stores[name]
+	sm.RUnlock()
+	return ok
+}
+
+// NewStore creates the named quota store with the named client of the store.
+func NewStore(name, clientName string) (Store, error) {
+	sm.RLock()
+	newStore, ok := stores[name]
+	sm.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrStoreNotFound, name)
+	}
+	return newStore(clientName)
+}
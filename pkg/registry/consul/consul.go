@@ -6,6 +6,7 @@ endpoints and watching for other services.
 package consul
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -33,8 +34,18 @@ const (
 type Consul struct {
 	client           *api.Client
 	conf             *Config
-	exit             chan struct{}              // Used to stop the TTL heartbeat goroutine.
 	discoveryOptions *registry.DiscoveryOptions // Stores callback and filtering logic for discovery.
+
+	// exits stops the TTL heartbeat goroutine of every registered instance by its ID.
+	exits map[string]chan struct{}
+	em    sync.Mutex
+}
+
+// consulRegister is the Consul used for service registration,
+// its HealthCheck checks the TTL check of the current service on every registry heartbeat.
+// The discovered instances are not checked by the agent, they may be registered in other agents.
+type consulRegister struct {
+	*Consul
 }
 
 // Config defines the settings for the Consul registry.
@@ -46,8 +57,9 @@ type Config struct {
 
 var (
 	// Ensure the Consul struct satisfies the Registry and Discovery interfaces.
-	_ registry.Register  = &Consul{}
-	_ registry.Discovery = &Consul{}
+	_ registry.Register      = &Consul{}
+	_ registry.Discovery     = &Consul{}
+	_ registry.HealthChecker = &consulRegister{}
 
 	defaultConfig = Config{
 		Client:  consul.DefaultClientName,
@@ -66,7 +78,11 @@ func init() {
 
 // NewRegister initializes the Consul client for service registration.
 func NewRegister() (registry.Register, error) {
-	return New(nil)
+	c, err := New(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &consulRegister{Consul: c}, nil
 }
 
 // NewDiscovery initializes the Consul client and starts the background service watch.
@@ -87,7 +103,7 @@ func New(options *registry.DiscoveryOptions) (*Consul, error) {
 	var err error
 	newOnce.Do(func() {
 		consulRegistry := &Consul{
-			exit: make(chan struct{}),
+			exits: make(map[string]chan struct{}),
 		}
 		err = consulRegistry.loadConfig()
 		if err != nil {
@@ -110,7 +126,8 @@ func New(options *registry.DiscoveryOptions) (*Consul, error) {
 }
 
 // Registe publishes the service endpoints and metadata to Consul.
-// It also starts a background goroutine to maintain the TTL heartbeat.
+// It also starts a background goroutine to maintain the TTL heartbeat,
+// once per instance, registering the instance again keeps the running one.
 func (c *Consul) Registe(service *server.Service) error {
 	appDetail, err := json.Marshal(&service.APP)
 	if err != nil {
@@ -158,11 +175,22 @@ func (c *Consul) Registe(service *server.Service) error {
 		return err
 	}
 
+	c.em.Lock()
+	defer c.em.Unlock()
+	if c.exits == nil {
+		c.exits = make(map[string]chan struct{})
+	}
+	if _, ok := c.exits[service.Instance.ID]; ok {
+		return nil
+	}
+	exit := make(chan struct{})
+	c.exits[service.Instance.ID] = exit
+
 	// TTL Heartbeat Goroutine.
 	go func() {
 		for {
 			select {
-			case <-c.exit:
+			case <-exit:
 				return
 			case <-time.After(c.conf.Timeout.Duration):
 				if err := c.client.Agent().UpdateTTL(service.Instance.ID, "", "passing"); err != nil {
@@ -177,7 +205,12 @@ func (c *Consul) Registe(service *server.Service) error {
 
 // Remove stops the heartbeat and deregisters the service from the Consul agent.
 func (c *Consul) Remove(service *server.Service) {
-	c.exit <- struct{}{}
+	c.em.Lock()
+	if exit, ok := c.exits[service.Instance.ID]; ok {
+		close(exit)
+		delete(c.exits, service.Instance.ID)
+	}
+	c.em.Unlock()
 	if err := c.client.Agent().ServiceDeregister(service.Instance.ID); err != nil {
 		logger.Error("remove instance fail", "err", err)
 	}
@@ -185,6 +218,23 @@ func (c *Consul) Remove(service *server.Service) {
 
 func (c *Consul) Name() string { return NAME }
 
+// HealthCheck checks the TTL check of the registered service in the agent,
+// a missing or not passing check means the registration is lost or expiring.
+func (r *consulRegister) HealthCheck(ctx context.Context, service *server.Service) error {
+	checks, err := r.client.Agent().ChecksWithFilterOpts(fmt.Sprintf("CheckID == %q", service.Instance.ID), (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	check, ok := checks[service.Instance.ID]
+	if !ok {
+		return fmt.Errorf("instance '%s' check not found", service.Instance.ID)
+	}
+	if check.Status != api.HealthPassing {
+		return fmt.Errorf("instance '%s' check is %s", service.Instance.ID, check.Status)
+	}
+	return nil
+}
+
 // GetAll fetches all active instances currently known by the local Consul agent.
 func (c *Consul) GetAll() ([]*registry.Instance, error) {
 	serviceMap, err := c.getAgentServices()
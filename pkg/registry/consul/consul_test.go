@@ -1,9 +1,13 @@
 package consul
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -65,7 +69,7 @@ func TestConsulRegistryIntegration(t *testing.T) {
 		return err == nil
 	}, 30*time.Second, 500*time.Millisecond)
 
-	provider := &Consul{client: client, conf: &Config{Timeout: utils.JSONDuration{Duration: 5 * time.Second}}, exit: make(chan struct{})}
+	provider := &Consul{client: client, conf: &Config{Timeout: utils.JSONDuration{Duration: 5 * time.Second}}}
 	service := &server.Service{APP: runtime.GetAPP(), Endpoints: map[string]*server.Endpoint{"grpc": {Advertise: []string{"127.0.0.1:9000"}}}}
 	service.Instance.ID = fmt.Sprintf("asjard-test-%d", time.Now().UnixNano())
 	require.NoError(t, provider.Registe(service))
@@ -83,3 +87,37 @@ func TestConsulRegistryIntegration(t *testing.T) {
 		return false
 	}, 10*time.Second, 100*time.Millisecond)
 }
+
+func TestConsulRegisterHealthCheck(t *testing.T) {
+	checkStatus := api.HealthPassing
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/agent/checks":
+			checks := map[string]*api.AgentCheck{}
+			if checkStatus != "" {
+				checks["one"] = &api.AgentCheck{CheckID: "one", Status: checkStatus}
+			}
+			_ = json.NewEncoder(w).Encode(checks)
+		}
+	}))
+	t.Cleanup(agent.Close)
+	apiConfig := api.DefaultConfig()
+	apiConfig.Address = strings.TrimPrefix(agent.URL, "http://")
+	client, err := api.NewClient(apiConfig)
+	require.NoError(t, err)
+
+	r := &consulRegister{Consul: &Consul{client: client, conf: &Config{Timeout: utils.JSONDuration{Duration: time.Minute}}}}
+	service := &server.Service{APP: runtime.APP{Instance: runtime.Instance{ID: "one", Name: "api"}}}
+	require.NoError(t, r.Registe(service))
+	require.NoError(t, r.Registe(service))
+	require.Len(t, r.exits, 1, "one ttl heartbeat per instance")
+
+	require.NoError(t, r.HealthCheck(context.Background(), service))
+	checkStatus = api.HealthCritical
+	require.Error(t, r.HealthCheck(context.Background(), service))
+	checkStatus = ""
+	require.Error(t, r.HealthCheck(context.Background(), service))
+
+	r.Remove(service)
+	require.Empty(t, r.exits)
+}
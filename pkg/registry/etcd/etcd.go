@@ -34,6 +34,23 @@ type Etcd struct {
 	client           *clientv3.Client
 	conf             *Config
 	discoveryOptions *registry.DiscoveryOptions
+
+	// leases holds the lease of every registered instance by its key.
+	leases map[string]*etcdLease
+	lm     sync.Mutex
+}
+
+// etcdLease is the lease of a registered instance and the cancel of its keepalive.
+type etcdLease struct {
+	id     clientv3.LeaseID
+	cancel context.CancelFunc
+}
+
+// etcdRegister is the Etcd used for service registration,
+// its HealthCheck checks the lease of the current service on every registry heartbeat.
+// The discovered instances are not checked by their lease, only the registered ones have one here.
+type etcdRegister struct {
+	*Etcd
 }
 
 // Config holds the parameters for the ETCD client and operation timeouts.
@@ -44,8 +61,9 @@ type Config struct {
 
 var (
 	// Interface verification.
-	_ registry.Register  = &Etcd{}
-	_ registry.Discovery = &Etcd{}
+	_ registry.Register      = &Etcd{}
+	_ registry.Discovery     = &Etcd{}
+	_ registry.HealthChecker = &etcdRegister{}
 
 	// defaultConfig provides fallback values if no configuration is found.
 	defaultConfig = Config{
@@ -64,7 +82,11 @@ func init() {
 
 // NewRegister initializes the ETCD provider for service registration.
 func NewRegister() (registry.Register, error) {
-	return New(nil)
+	e, err := New(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &etcdRegister{Etcd: e}, nil
 }
 
 // NewDiscovery initializes the ETCD provider for service discovery and starts the watch loop.
@@ -82,7 +104,7 @@ func NewDiscovery(options *registry.DiscoveryOptions) (registry.Discovery, error
 func New(options *registry.DiscoveryOptions) (*Etcd, error) {
 	var err error
 	newOnce.Do(func() {
-		etcdRegistry := &Etcd{}
+		etcdRegistry := &Etcd{leases: make(map[string]*etcdLease)}
 		err = etcdRegistry.loadConfig()
 		if err != nil {
 			return
@@ -131,7 +153,8 @@ func (e *Etcd) Name() string {
 }
 
 // Registe uploads the service instance details to ETCD with a 5-second TTL lease.
-// It also manages the KeepAlive heartbeats to maintain the registration.
+// It also manages the KeepAlive heartbeats to maintain the registration,
+// registering the instance again replaces its lease and stops the keepalive of the previous one.
 func (e *Etcd) Registe(instance *server.Service) error {
 	logger.Debug("register instance into etcd", "instance", instance)
 	b, err := json.Marshal(instance)
@@ -143,34 +166,87 @@ func (e *Etcd) Registe(instance *server.Service) error {
 	defer cancel()
 
 	// Create a lease with 5 seconds of life.
-	lease := clientv3.NewLease(e.client)
-	grant, err := lease.Grant(ctx, 5)
+	grant, err := e.client.Grant(ctx, 5)
 	if err != nil {
 		return err
 	}
 
 	// Put the instance JSON into ETCD attached to the lease.
-	if _, err := e.client.Put(ctx,
-		e.registerKey(instance),
-		string(b),
-		clientv3.WithLease(grant.ID)); err != nil {
+	key := e.registerKey(instance)
+	if _, err := e.client.Put(ctx, key, string(b), clientv3.WithLease(grant.ID)); err != nil {
+		e.revoke(grant.ID)
 		return fmt.Errorf("register instance fail[%s]", err)
 	}
 
-	// Start automatic heartbeat.
-	leaseChan, err := lease.KeepAlive(context.Background(), grant.ID)
+	// Start automatic heartbeat, stopped when the instance is registered again or removed.
+	keepAliveCtx, keepAliveCancel := context.WithCancel(context.Background())
+	leaseChan, err := e.client.KeepAlive(keepAliveCtx, grant.ID)
 	if err != nil {
+		keepAliveCancel()
+		e.revoke(grant.ID)
 		return err
 	}
+	e.replaceLease(key, &etcdLease{id: grant.ID, cancel: keepAliveCancel})
 
 	// Monitoring goroutine for the lease status.
-	go e.watchLease(instance, leaseChan)
+	go e.watchLease(keepAliveCtx, instance, leaseChan)
+	return nil
+}
+
+// HealthCheck checks the lease of the registered service, an expired lease means the registration is lost.
+func (r *etcdRegister) HealthCheck(ctx context.Context, instance *server.Service) error {
+	r.lm.Lock()
+	lease, ok := r.leases[r.registerKey(instance)]
+	r.lm.Unlock()
+	if !ok {
+		return fmt.Errorf("instance '%s' not registered", instance.Instance.ID)
+	}
+	resp, err := r.client.TimeToLive(ctx, lease.id)
+	if err != nil {
+		return err
+	}
+	if resp.TTL <= 0 {
+		return fmt.Errorf("instance '%s' lease %x expired", instance.Instance.ID, lease.id)
+	}
 	return nil
 }
 
-func (e *Etcd) watchLease(instance *server.Service, leaseChan <-chan *clientv3.LeaseKeepAliveResponse) {
+// replaceLease stores the lease of key and releases the previous one.
+// The key is attached to the new lease, revoking the previous lease does not delete it.
+func (e *Etcd) replaceLease(key string, lease *etcdLease) {
+	e.lm.Lock()
+	if e.leases == nil {
+		e.leases = make(map[string]*etcdLease)
+	}
+	previous := e.leases[key]
+	if lease == nil {
+		delete(e.leases, key)
+	} else {
+		e.leases[key] = lease
+	}
+	e.lm.Unlock()
+	if previous != nil {
+		previous.cancel()
+		e.revoke(previous.id)
+	}
+}
+
+// revoke removes a lease, the errors are logged since the lease expires anyway.
+func (e *Etcd) revoke(id clientv3.LeaseID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := e.client.Revoke(ctx, id); err != nil {
+		logger.Debug("revoke lease fail", "lease", id, "err", err)
+	}
+}
+
+// watchLease registers the instance again once its keepalive is lost,
+// unless the keepalive was stopped by a new registration or the removal of the instance.
+func (e *Etcd) watchLease(ctx context.Context, instance *server.Service, leaseChan <-chan *clientv3.LeaseKeepAliveResponse) {
 	watchLease(leaseChan, func() {
-		e.reregiste(instance)
+		if ctx.Err() == nil {
+			e.reregiste(instance)
+		}
 	})
 }
 
@@ -200,11 +276,14 @@ func (e *Etcd) reregiste(instance *server.Service) {
 	}
 }
 
-// Remove manually deletes the instance key from ETCD (e.g., during graceful shutdown).
+// Remove manually deletes the instance key from ETCD (e.g., during graceful shutdown)
+// and stops the keepalive of its lease.
 func (e *Etcd) Remove(instance *server.Service) {
+	key := e.registerKey(instance)
+	e.replaceLease(key, nil)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if _, err := e.client.Delete(ctx, e.registerKey(instance)); err != nil {
+	if _, err := e.client.Delete(ctx, key); err != nil {
 		logger.Error("delete instance fail", "err", err)
 	}
 }
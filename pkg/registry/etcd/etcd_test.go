@@ -61,6 +61,21 @@ func TestWatchLeaseTriggersReregisteWhenResponseNil(t *testing.T) {
 	require.Len(t, triggered, 1)
 }
 
+func TestWatchLeaseStoppedNotReregiste(t *testing.T) {
+	leaseChan := make(chan *clientv3.LeaseKeepAliveResponse)
+	close(leaseChan)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// Registering again would fail without client.
+	(&Etcd{}).watchLease(ctx, &server.Service{}, leaseChan)
+}
+
+func TestEtcdRegisterHealthCheckNotRegistered(t *testing.T) {
+	r := &etcdRegister{Etcd: &Etcd{conf: &defaultConfig}}
+	service := &server.Service{APP: runtime.GetAPP()}
+	require.Error(t, r.HealthCheck(context.Background(), service))
+}
+
 func TestEtcdRegistryIntegration(t *testing.T) {
 	client, err := clientv3.New(clientv3.Config{Endpoints: []string{"127.0.0.1:2379"}, DialTimeout: 5 * time.Second})
 	require.NoError(t, err)
@@ -75,7 +90,11 @@ func TestEtcdRegistryIntegration(t *testing.T) {
 	provider := &Etcd{client: client, conf: &Config{Timeout: utils.JSONDuration{Duration: 5 * time.Second}}}
 	service := &server.Service{APP: runtime.GetAPP(), Endpoints: map[string]*server.Endpoint{"grpc": {Advertise: []string{"127.0.0.1:9000"}}}}
 	service.Instance.ID = fmt.Sprintf("asjard-test-%d", time.Now().UnixNano())
-	require.NoError(t, provider.Registe(service))
+	register := &etcdRegister{Etcd: provider}
+	require.NoError(t, register.Registe(service))
+	require.NoError(t, register.Registe(service))
+	require.Len(t, provider.leases, 1)
+	require.NoError(t, register.HealthCheck(context.Background(), service))
 	t.Cleanup(func() { provider.Remove(service) })
 	require.Eventually(t, func() bool {
 		instances, err := provider.GetAll()
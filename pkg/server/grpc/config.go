@@ -1,6 +1,10 @@
 package grpc
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/asjard/asjard/core/server"
@@ -11,6 +15,9 @@ import (
 // It embeds the base server configuration and adds gRPC-specific options.
 type Config struct {
 	server.Config
+	// ClientCaFile is the relative path to the CA certificates verifying the client certificates,
+	// the clients presenting no certificate are still accepted.
+	ClientCaFile string `json:"clientCaFile"`
 	// Options contains specific gRPC protocol settings like keepalive.
 	Options OptionsConfig `json:"options"`
 }
@@ -62,3 +69,28 @@ func defaultConfig() Config {
 		},
 	}
 }
+
+// tlsConfig returns the TLS configuration of the server,
+// verifying the client certificates if ClientCaFile is set.
+func (c Config) tlsConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+	if c.ClientCaFile != "" {
+		ca, err := os.ReadFile(c.ClientCaFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("grpc clientCaFile '%s' has no valid certificate", c.ClientCaFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
@@ -55,12 +55,13 @@ func MustNew(conf Config, options *server.ServerOptions) (server.Server, error)
 	var opts []grpc.ServerOption
 
 	// 1. Configure TLS Credentials if certificate and key files are provided.
+	// The client certificates are verified if a client CA file is provided.
 	if conf.CertFile != "" && conf.KeyFile != "" {
-		creds, err := credentials.NewServerTLSFromFile(conf.CertFile, conf.KeyFile)
+		tlsConfig, err := conf.tlsConfig()
 		if err != nil {
 			return nil, err
 		}
-		opts = append(opts, grpc.Creds(creds))
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
 	}
 
 	// 2. Apply Keepalive parameters to manage connection health and lifecycle.
@@ -85,12 +86,50 @@ func MustNew(conf Config, options *server.ServerOptions) (server.Server, error)
 		return handler(ctx, req)
 	}))
 
+	// 4. Register a Stream Interceptor so streaming RPCs pass through the same middleware.
+	opts = append(opts, grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if options.StreamInterceptor != nil {
+			return options.StreamInterceptor(srv, ss, &server.StreamServerInfo{
+				Server:         srv,
+				FullMethod:     info.FullMethod,
+				Protocol:       Protocol,
+				IsClientStream: info.IsClientStream,
+				IsServerStream: info.IsServerStream,
+			}, func(srv any, stream server.ServerStream) error {
+				return handler(srv, &serverStream{ServerStream: ss, stream: stream})
+			})
+		}
+		return handler(srv, ss)
+	}))
+
 	return &GrpcServer{
 		server: grpc.NewServer(opts...),
 		conf:   conf,
 	}, nil
 }
 
+// serverStream adapts a framework ServerStream, which may have been wrapped
+// by interceptors, back to a grpc.ServerStream for the generated handlers.
+type serverStream struct {
+	grpc.ServerStream
+	stream server.ServerStream
+}
+
+// Context returns the context of the (possibly wrapped) framework stream.
+func (s *serverStream) Context() context.Context {
+	return s.stream.Context()
+}
+
+// SendMsg sends the message through the (possibly wrapped) framework stream.
+func (s *serverStream) SendMsg(m any) error {
+	return s.stream.SendMsg(m)
+}
+
+// RecvMsg receives the message through the (possibly wrapped) framework stream.
+func (s *serverStream) RecvMsg(m any) error {
+	return s.stream.RecvMsg(m)
+}
+
 // New is the factory function called by the framework to create a gRPC server.
 func New(options *server.ServerOptions) (server.Server, error) {
 	conf := defaultConfig()
@@ -106,6 +145,9 @@ func New(options *server.ServerOptions) (server.Server, error) {
 	if conf.CertFile != "" {
 		conf.CertFile = filepath.Join(utils.GetCertDir(), conf.CertFile)
 	}
+	if conf.ClientCaFile != "" {
+		conf.ClientCaFile = filepath.Join(utils.GetCertDir(), conf.ClientCaFile)
+	}
 
 	return MustNew(conf, options)
 }
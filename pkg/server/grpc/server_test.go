@@ -1,10 +1,15 @@
 package grpc
 
 import (
+	"context"
+	"net"
 	"testing"
 
 	"github.com/asjard/asjard/core/server"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func TestGrpcServerContract(t *testing.T) {
@@ -25,3 +30,54 @@ func TestGrpcStartRequiresAddress(t *testing.T) {
 	require.Error(t, created.Start(make(chan error, 1)))
 	created.Stop()
 }
+
+type streamCtxKey struct{}
+
+func TestGrpcStreamInterceptor(t *testing.T) {
+	var gotInfo *server.StreamServerInfo
+	created, err := MustNew(Config{}, &server.ServerOptions{
+		StreamInterceptor: func(srv any, ss server.ServerStream, info *server.StreamServerInfo, handler server.StreamHandler) error {
+			gotInfo = info
+			return handler(srv, server.WrapServerStream(ss, context.WithValue(ss.Context(), streamCtxKey{}, "wrapped")))
+		},
+	})
+	require.NoError(t, err)
+	s := created.(*GrpcServer)
+	s.server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Stream",
+		HandlerType: (*any)(nil),
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "Watch",
+			ServerStreams: true,
+			Handler: func(_ any, stream grpc.ServerStream) error {
+				in := &wrapperspb.StringValue{}
+				if err := stream.RecvMsg(in); err != nil {
+					return err
+				}
+				return stream.SendMsg(wrapperspb.String(in.Value + ":" + stream.Context().Value(streamCtxKey{}).(string)))
+			},
+		}},
+	}, struct{}{})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go s.server.Serve(listener)
+	defer s.Stop()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	stream, err := conn.NewStream(context.Background(), &grpc.StreamDesc{ServerStreams: true}, "/test.Stream/Watch")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(wrapperspb.String("hello")))
+	require.NoError(t, stream.CloseSend())
+	out := &wrapperspb.StringValue{}
+	require.NoError(t, stream.RecvMsg(out))
+	require.Equal(t, "hello:wrapped", out.Value)
+
+	require.Equal(t, "/test.Stream/Watch", gotInfo.FullMethod)
+	require.Equal(t, Protocol, gotInfo.Protocol)
+	require.True(t, gotInfo.IsServerStream)
+	require.False(t, gotInfo.IsClientStream)
+}
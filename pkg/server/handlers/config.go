@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/server"
+	"github.com/asjard/asjard/core/server/handlers"
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/pkg/server/rest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// ConfigHandlerName is the name of the configuration introspection default handler.
+	ConfigHandlerName = "config"
+
+	Config_Snapshot_FullMethodName = "/api.v1.Config/Snapshot"
+	Config_Sources_FullMethodName  = "/api.v1.Config/Sources"
+	Config_History_FullMethodName  = "/api.v1.Config/History"
+
+	Config_Snapshot_RestPath = "/config"
+	Config_Sources_RestPath  = "/config/sources"
+	Config_History_RestPath  = "/config/history"
+)
+
+// ConfigServer is the server API of the configuration introspection.
+type ConfigServer interface {
+	// Snapshot returns the effective configuration, filtered by the prefix query parameter.
+	Snapshot(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+	// Sources returns the value of the key query parameter in every source.
+	Sources(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+	// History returns the changes of the effective configuration since startup,
+	// filtered by the key query parameter.
+	History(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+}
+
+// ConfigAPI shows the effective configuration and where every value comes from.
+// It is disabled unless asjard.config.introspection.enabled is true,
+// encrypted values and the sensitive values, e.g. of the encrypted files, are masked.
+type ConfigAPI struct{}
+
+func init() {
+	handlers.AddServerDefaultHandler(ConfigHandlerName, &ConfigAPI{}, rest.Protocol)
+}
+
+// Snapshot returns the effective value of every key with its source.
+func (api *ConfigAPI) Snapshot(ctx context.Context, in *emptypb.Empty) (*structpb.Struct, error) {
+	if err := configIntrospectionEnabled(); err != nil {
+		return nil, err
+	}
+	prefix := queryArg(ctx, "prefix")
+	configs := make(map[string]any)
+	for key, value := range config.GetValues() {
+		if strings.HasPrefix(key, prefix) {
+			configs[key] = configValue(value)
+		}
+	}
+	return structpb.NewStruct(map[string]any{"configs": configs})
+}
+
+// Sources returns the value of a key in every source, ordered by descending priority.
+func (api *ConfigAPI) Sources(ctx context.Context, in *emptypb.Empty) (*structpb.Struct, error) {
+	if err := configIntrospectionEnabled(); err != nil {
+		return nil, err
+	}
+	key := queryArg(ctx, "key")
+	if key == "" {
+		return nil, status.Error(codes.InvalidArgument, "key is required")
+	}
+	values := make([]any, 0)
+	for _, value := range config.GetSourceValues(key) {
+		values = append(values, configValue(value))
+	}
+	return structpb.NewStruct(map[string]any{
+		"key":    key,
+		"values": values,
+	})
+}
+
+// History returns the changes of the effective values since startup, oldest first.
+func (api *ConfigAPI) History(ctx context.Context, in *emptypb.Empty) (*structpb.Struct, error) {
+	if err := configIntrospectionEnabled(); err != nil {
+		return nil, err
+	}
+	changes := make([]any, 0)
+	for _, change := range config.GetChanges(queryArg(ctx, "key")) {
+		changes = append(changes, map[string]any{
+			"key":      change.Key,
+			"type":     change.Type.String(),
+			"previous": configValue(change.Previous),
+			"value":    configValue(change.Value),
+			"time":     change.Time.Format(time.RFC3339Nano),
+		})
+	}
+	return structpb.NewStruct(map[string]any{"changes": changes})
+}
+
+// RestServiceDesc returns the REST service description of the configuration introspection.
+func (api *ConfigAPI) RestServiceDesc() *rest.ServiceDesc {
+	return &ConfigRestServiceDesc
+}
+
+// ConfigRestServiceDesc is the REST service description of the configuration introspection.
+var ConfigRestServiceDesc = rest.ServiceDesc{
+	Name:        "Config",
+	ServiceName: "api.v1.Config",
+	Desc:        "Configuration introspection",
+	HandlerType: (*ConfigServer)(nil),
+	Methods: []rest.MethodDesc{
+		{
+			MethodName: "Snapshot",
+			Name:       "Effective configuration",
+			Desc:       "Return the effective configuration with the source of every value.",
+			Method:     "GET",
+			Path:       Config_Snapshot_RestPath,
+			Handler:    configRestHandler(Config_Snapshot_FullMethodName, ConfigServer.Snapshot),
+		},
+		{
+			MethodName: "Sources",
+			Name:       "Configuration sources",
+			Desc:       "Return the value of a key in every source.",
+			Method:     "GET",
+			Path:       Config_Sources_RestPath,
+			Handler:    configRestHandler(Config_Sources_FullMethodName, ConfigServer.Sources),
+		},
+		{
+			MethodName: "History",
+			Name:       "Configuration history",
+			Desc:       "Return the changes of the configuration since startup.",
+			Method:     "GET",
+			Path:       Config_History_RestPath,
+			Handler:    configRestHandler(Config_History_FullMethodName, ConfigServer.History),
+		},
+	},
+}
+
+// configRestHandler returns the REST handler of a method of ConfigServer.
+func configRestHandler(fullMethod string,
+	method func(ConfigServer, context.Context, *emptypb.Empty) (*structpb.Struct, error),
+) func(ctx *rest.Context, srv any, interceptor server.UnaryServerInterceptor) (any, error) {
+	return func(ctx *rest.Context, srv any, interceptor server.UnaryServerInterceptor) (any, error) {
+		in := new(emptypb.Empty)
+		if interceptor == nil {
+			return method(srv.(ConfigServer), ctx, in)
+		}
+		info := &server.UnaryServerInfo{
+			Server:     srv,
+			FullMethod: fullMethod,
+			Protocol:   rest.Protocol,
+		}
+		handler := func(ctx context.Context, req any) (any, error) {
+			return method(srv.(ConfigServer), ctx, in)
+		}
+		return interceptor(ctx, in, info, handler)
+	}
+}
+
+// configIntrospectionEnabled returns an error unless the introspection is enabled.
+func configIntrospectionEnabled() error {
+	if !config.GetBool(constant.ConfigIntrospectionEnabled, false) {
+		return status.Error(codes.PermissionDenied, "config introspection is disabled")
+	}
+	return nil
+}
+
+// configValue returns a value with its source, nil if value is nil.
+func configValue(value *config.Value) any {
+	if value == nil {
+		return nil
+	}
+	out := map[string]any{
+		"value": structValue(config.MaskValue(value)),
+		"ref":   value.Ref,
+	}
+	if value.Sourcer != nil {
+		out["source"] = value.Sourcer.Name()
+		out["priority"] = value.Sourcer.Priority()
+	}
+	return out
+}
+
+// structValue converts the values structpb does not support to strings.
+func structValue(value any) any {
+	switch v := value.(type) {
+	case []any:
+		values := make([]any, 0, len(v))
+		for _, item := range v {
+			values = append(values, structValue(item))
+		}
+		return values
+	case map[string]any:
+		values := make(map[string]any, len(v))
+		for key, item := range v {
+			values[key] = structValue(item)
+		}
+		return values
+	}
+	if _, err := structpb.NewValue(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return value
+}
+
+// queryArg returns a query parameter of a REST request.
+func queryArg(ctx context.Context, name string) string {
+	if rtx, ok := ctx.(*rest.Context); ok {
+		return string(rtx.QueryArgs().Peek(name))
+	}
+	return ""
+}
@@ -2,12 +2,50 @@ package handlers
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/asjard/asjard/core/config"
+	_ "github.com/asjard/asjard/core/config/sources/file"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/server"
+	_ "github.com/asjard/asjard/pkg/config/mem"
+	"github.com/asjard/asjard/pkg/protobuf/healthpb"
+	"github.com/asjard/asjard/pkg/server/rest"
+	"github.com/asjard/asjard/utils"
 	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	ggrpc "google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// testEncryptedKey is the key of the encrypted file loaded by the file source.
+const testEncryptedKey = "test_config_handler_encrypted"
+
+func TestMain(m *testing.M) {
+	confDir, err := os.MkdirTemp("", "handlers")
+	if err != nil {
+		panic(err)
+	}
+	content := base64.StdEncoding.EncodeToString([]byte(testEncryptedKey + ": plaintext_secret"))
+	if err := os.WriteFile(filepath.Join(confDir, "encrypted_base64_secret.yaml"), []byte(content), 0640); err != nil {
+		panic(err)
+	}
+	os.Setenv(utils.CONF_DIR_ENV_NAME, confDir)
+	if err := config.Load(-1); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	os.RemoveAll(confDir)
+	os.Exit(code)
+}
+
 func TestDefaultHandler(t *testing.T) {
 	api := &DefaultHandlersAPI{}
 	resp, err := api.Favicon(context.Background(), &emptypb.Empty{})
@@ -21,3 +59,114 @@ func TestHealthDescriptors(t *testing.T) {
 	require.NotNil(t, health.RestServiceDesc())
 	require.NotNil(t, health.GrpcServiceDesc())
 }
+
+func TestHealthProbes(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := ggrpc.NewServer()
+	healthpb.RegisterHealthServer(srv, &Health{})
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	instance := server.NewService()
+	instance.Instance.Name = "test_health_probes_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, GrpcHealthProbe(ctx, instance, ln.Addr().String()))
+	conn, err := grpcProbeConnection(instance.Instance.Name, ln.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, GrpcHealthProbe(ctx, instance, ln.Addr().String()))
+	again, err := grpcProbeConnection(instance.Instance.Name, ln.Addr().String())
+	require.NoError(t, err)
+	require.Same(t, conn, again, "the probe connection is reused")
+
+	require.Error(t, RestHealthProbe(ctx, instance, "127.0.0.1:1"))
+	require.Error(t, checkServingStatus(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}))
+
+	t.Run("TLS", func(t *testing.T) {
+		require.NoError(t, config.Set(fmt.Sprintf(constant.ConfigClientWithSevicePrefix, rest.Protocol, instance.Instance.Name)+".ccertFile", "missing.pem"))
+		require.Eventually(t, func() bool {
+			_, scheme, err := restProbeClient(instance.Instance.Name)
+			return err != nil && scheme == ""
+		}, time.Second, 10*time.Millisecond, "the certificate of the service is used")
+		_, scheme, err := restProbeClient("test_health_probes_plain")
+		require.NoError(t, err)
+		require.Equal(t, "http", scheme)
+	})
+}
+
+func TestConfigHandler(t *testing.T) {
+	api := &ConfigAPI{}
+	require.NotNil(t, api.RestServiceDesc())
+	key := "test_config_handler_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	newCtx := func(query string) *rest.Context {
+		rctx := &fasthttp.RequestCtx{}
+		rctx.Request.SetRequestURI(Config_Sources_RestPath + "?" + query)
+		return rest.NewContext(rctx)
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		require.NoError(t, config.Set(constant.ConfigIntrospectionEnabled, false))
+		require.Eventually(t, func() bool {
+			_, err := api.Snapshot(context.Background(), &emptypb.Empty{})
+			return err != nil
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	require.NoError(t, config.Set(constant.ConfigIntrospectionEnabled, true))
+	require.NoError(t, config.Set(key, "v1"))
+	require.NoError(t, config.Set(key+".secret", config.ValueEncryptFlag+"default:xxx"))
+	require.Eventually(t, func() bool {
+		return config.GetBool(constant.ConfigIntrospectionEnabled, false) && config.Exist(key+".secret")
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, config.Set(key, "v2"))
+	require.Eventually(t, func() bool {
+		return config.GetString(key, "") == "v2"
+	}, time.Second, 10*time.Millisecond)
+
+	t.Run("Snapshot", func(t *testing.T) {
+		out, err := api.Snapshot(newCtx("prefix="+key), &emptypb.Empty{})
+		require.NoError(t, err)
+		configs := out.AsMap()["configs"].(map[string]any)
+		require.Len(t, configs, 2)
+		value := configs[key].(map[string]any)
+		require.Equal(t, "v2", value["value"])
+		require.NotEmpty(t, value["source"])
+		require.Equal(t, config.MaskedValue, configs[key+".secret"].(map[string]any)["value"])
+	})
+
+	t.Run("Sources", func(t *testing.T) {
+		_, err := api.Sources(newCtx(""), &emptypb.Empty{})
+		require.Error(t, err)
+		out, err := api.Sources(newCtx("key="+key), &emptypb.Empty{})
+		require.NoError(t, err)
+		values := out.AsMap()["values"].([]any)
+		require.NotEmpty(t, values)
+		require.Equal(t, "v2", values[0].(map[string]any)["value"])
+	})
+
+	t.Run("History", func(t *testing.T) {
+		out, err := api.History(newCtx("key="+key), &emptypb.Empty{})
+		require.NoError(t, err)
+		changes := out.AsMap()["changes"].([]any)
+		require.Len(t, changes, 2)
+		last := changes[1].(map[string]any)
+		require.Equal(t, "v1", last["previous"].(map[string]any)["value"])
+		require.Equal(t, "v2", last["value"].(map[string]any)["value"])
+	})
+
+	t.Run("EncryptedFile", func(t *testing.T) {
+		require.Equal(t, "plaintext_secret", config.GetString(testEncryptedKey, ""))
+		out, err := api.Snapshot(newCtx("prefix="+testEncryptedKey), &emptypb.Empty{})
+		require.NoError(t, err)
+		value := out.AsMap()["configs"].(map[string]any)[testEncryptedKey].(map[string]any)
+		require.Equal(t, config.MaskedValue, value["value"])
+		require.Equal(t, "file", value["source"])
+
+		out, err = api.Sources(newCtx("key="+testEncryptedKey), &emptypb.Empty{})
+		require.NoError(t, err)
+		for _, value := range out.AsMap()["values"].([]any) {
+			require.Equal(t, config.MaskedValue, value.(map[string]any)["value"])
+		}
+	})
+}
@@ -2,17 +2,33 @@ package handlers
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/asjard/asjard/core/client"
 	"github.com/asjard/asjard/core/config"
 	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/registry"
 	"github.com/asjard/asjard/core/runtime"
+	"github.com/asjard/asjard/core/server"
 	"github.com/asjard/asjard/core/server/handlers"
 	_ "github.com/asjard/asjard/pkg/client/grpc" // Side-effect import to register gRPC client
 	"github.com/asjard/asjard/pkg/protobuf/healthpb"
+	"github.com/asjard/asjard/pkg/protobuf/statuspb"
 	"github.com/asjard/asjard/pkg/server/grpc"
 	"github.com/asjard/asjard/pkg/server/rest"
+	"github.com/asjard/asjard/utils"
+	"github.com/valyala/fasthttp"
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 // Health implements the healthpb.HealthServer interface.
@@ -24,6 +40,9 @@ func init() {
 	// Automatically register the health handler for both gRPC and REST protocols.
 	// This ensures the service is discoverable by load balancers and monitoring tools.
 	handlers.AddServerDefaultHandler("health", &Health{}, grpc.Protocol, rest.Protocol)
+	// Probe discovered instances through the same health check API.
+	registry.AddHealthProbe(grpc.Protocol, GrpcHealthProbe)
+	registry.AddHealthProbe(rest.Protocol, RestHealthProbe)
 }
 
 // Check performs a health check on the current service or a specified downstream service.
@@ -70,3 +89,143 @@ func (Health) RestServiceDesc() *rest.ServiceDesc {
 func (Health) GrpcServiceDesc() *grpc.ServiceDesc {
 	return &healthpb.Health_ServiceDesc
 }
+
+// probeConnIdleTimeout is the idle time after which a cached probe connection is closed.
+const probeConnIdleTimeout = 5 * time.Minute
+
+var (
+	// grpcProbeConns caches the gRPC connections of the probes by service and address.
+	grpcProbeConns = make(map[string]*grpcProbeConn)
+	gpcm           sync.Mutex
+	// restProbeClients caches the TLS clients of the probes by certificate and service,
+	// the plain HTTP probes share the default fasthttp client.
+	restProbeClients sync.Map
+	// defaultRestProbeClient is the client of the plain HTTP probes.
+	defaultRestProbeClient = &fasthttp.Client{}
+)
+
+// grpcProbeConn is a cached gRPC connection of the probes.
+type grpcProbeConn struct {
+	conn *ggrpc.ClientConn
+	// certFile is the certificate the connection was created with.
+	certFile string
+	lastUsed time.Time
+}
+
+// GrpcHealthProbe checks the instance listening on address through the gRPC health check API.
+// The connection uses the TLS certificate of the service client, asjard.clients.grpc.{service}.ccertFile,
+// and is reused by the next probes of the address.
+func GrpcHealthProbe(ctx context.Context, instance *server.Service, address string) error {
+	conn, err := grpcProbeConnection(instance.Instance.Name, address)
+	if err != nil {
+		return err
+	}
+	out, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	return checkServingStatus(out)
+}
+
+// grpcProbeConnection returns the cached connection of an address,
+// the connections idle for probeConnIdleTimeout are closed.
+func grpcProbeConnection(serviceName, address string) (*ggrpc.ClientConn, error) {
+	certFile := client.GetConfigWithService(grpc.Protocol, serviceName).CertFile
+	key := serviceName + "@" + address
+	now := time.Now()
+	gpcm.Lock()
+	defer gpcm.Unlock()
+	for k, c := range grpcProbeConns {
+		if now.Sub(c.lastUsed) > probeConnIdleTimeout || (k == key && c.certFile != certFile) {
+			c.conn.Close()
+			delete(grpcProbeConns, k)
+		}
+	}
+	if c, ok := grpcProbeConns[key]; ok {
+		c.lastUsed = now
+		return c.conn, nil
+	}
+	creds := insecure.NewCredentials()
+	if certFile != "" {
+		var err error
+		creds, err = credentials.NewClientTLSFromFile(filepath.Join(utils.GetCertDir(), certFile), serviceName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// The connection is established on the first call.
+	conn, err := ggrpc.NewClient(address, ggrpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	grpcProbeConns[key] = &grpcProbeConn{conn: conn, certFile: certFile, lastUsed: now}
+	return conn, nil
+}
+
+// RestHealthProbe checks the instance listening on address through the REST health check API.
+// It uses https with the TLS certificate of the service client, asjard.clients.rest.{service}.ccertFile,
+// or plain http without a certificate.
+func RestHealthProbe(ctx context.Context, instance *server.Service, address string) error {
+	httpClient, scheme, err := restProbeClient(instance.Instance.Name)
+	if err != nil {
+		return err
+	}
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.Header.SetMethod(http.MethodGet)
+	// Ask for the real status code, the writer responds 200 by default.
+	req.SetRequestURI(fmt.Sprintf("%s://%s%s?%s=1", scheme, address, healthpb.Health_Check_RestPath, rest.QueryParamNeedStatusCode))
+	if deadline, ok := ctx.Deadline(); ok {
+		err = httpClient.DoDeadline(req, resp, deadline)
+	} else {
+		err = httpClient.Do(req, resp)
+	}
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("health check response status code %d", resp.StatusCode())
+	}
+
+	st := &statuspb.Status{}
+	if err := protojson.Unmarshal(resp.Body(), st); err != nil {
+		return err
+	}
+	out := &healthpb.HealthCheckResponse{}
+	if err := st.GetData().UnmarshalTo(out); err != nil {
+		return err
+	}
+	return checkServingStatus(out)
+}
+
+// restProbeClient returns the client and the scheme of the probes of a service.
+func restProbeClient(serviceName string) (*fasthttp.Client, string, error) {
+	certFile := client.GetConfigWithService(rest.Protocol, serviceName).CertFile
+	if certFile == "" {
+		return defaultRestProbeClient, "http", nil
+	}
+	key := certFile + "@" + serviceName
+	if httpClient, ok := restProbeClients.Load(key); ok {
+		return httpClient.(*fasthttp.Client), "https", nil
+	}
+	caCert, err := os.ReadFile(filepath.Join(utils.GetCertDir(), certFile))
+	if err != nil {
+		return nil, "", err
+	}
+	certPool := x509.NewCertPool()
+	certPool.AppendCertsFromPEM(caCert)
+	httpClient, _ := restProbeClients.LoadOrStore(key, &fasthttp.Client{
+		TLSConfig: &tls.Config{RootCAs: certPool, ServerName: serviceName},
+	})
+	return httpClient.(*fasthttp.Client), "https", nil
+}
+
+func checkServingStatus(out *healthpb.HealthCheckResponse) error {
+	if out.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("health check status %s", out.GetStatus())
+	}
+	return nil
+}
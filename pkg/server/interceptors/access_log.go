@@ -26,6 +26,8 @@ type AccessLog struct {
 	cfg    *accessLogConfig
 	m      sync.RWMutex   // Protects the configuration during hot-reloads.
 	logger *logger.Logger // Localized logger instance specific to access logs.
+	// removeListener stops watching the configuration.
+	removeListener func()
 }
 
 // accessLogConfig defines the settings for request logging.
@@ -70,6 +72,14 @@ func (*AccessLog) Name() string {
 	return AccessLogInterceptorName
 }
 
+// Close stops watching the configuration once the interceptor is removed from the chain.
+func (al *AccessLog) Close() error {
+	if al.removeListener != nil {
+		al.removeListener()
+	}
+	return nil
+}
+
 // Interceptor returns the actual middleware function that wraps request execution.
 func (al *AccessLog) Interceptor() server.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *server.UnaryServerInfo, handler server.UnaryHandler) (resp any, err error) {
@@ -115,6 +125,37 @@ func (al *AccessLog) Interceptor() server.UnaryServerInterceptor {
 	}
 }
 
+// StreamInterceptor returns the middleware function that logs streaming RPCs once the stream is finished.
+func (al *AccessLog) StreamInterceptor() server.StreamServerInterceptor {
+	return func(srv any, ss server.ServerStream, info *server.StreamServerInfo, handler server.StreamHandler) error {
+		ctx := ss.Context()
+		logger.L(ctx).Debug("start server stream interceptor", "interceptor", al.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+
+		start := time.Now()
+		err := handler(srv, ss)
+
+		if al.skipped(info.Protocol, info.FullMethod) {
+			return err
+		}
+
+		fields := []any{
+			"protocol", info.Protocol,
+			"full_method", info.FullMethod,
+			"client_stream", info.IsClientStream,
+			"server_stream", info.IsServerStream,
+			"cost", time.Since(start).String(),
+			"success", err == nil,
+			"err", err,
+		}
+		if err != nil {
+			al.logger.L(ctx).Error("access log", fields...)
+		} else {
+			al.logger.L(ctx).Info("access log", fields...)
+		}
+		return err
+	}
+}
+
 // skipped checks if logging is disabled or if the current method is in the skip list.
 func (al *AccessLog) skipped(protocol, method string) bool {
 	al.m.RLock()
@@ -144,7 +185,7 @@ func (al *AccessLog) loadAndWatch() error {
 		return err
 	}
 	// Watch for configuration changes in real-time.
-	config.AddPatternListener("asjard.logger.accessLog.*", al.watch)
+	al.removeListener = config.AddPatternListener("asjard.logger.accessLog.*", al.watch)
 	return nil
 }
 
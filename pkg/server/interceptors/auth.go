@@ -0,0 +1,159 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/server"
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/pkg/auth"
+	"github.com/asjard/asjard/pkg/protobuf/healthpb"
+	"github.com/asjard/asjard/pkg/protobuf/metricspb"
+	"github.com/asjard/asjard/pkg/protobuf/requestpb"
+	"github.com/asjard/asjard/utils"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// AuthInterceptorName is the unique identifier for the authentication interceptor.
+	AuthInterceptorName = "auth"
+)
+
+// Auth authenticates the requests with the configured authenticators
+// and places the principal in the request context, read with auth.FromContext.
+type Auth struct {
+	conf   *config.Binding[AuthConfig]
+	cancel func()
+}
+
+// AuthConfig defines the authentication of incoming requests.
+type AuthConfig struct {
+	// Authenticators are tried in order, the first one finding its credentials
+	// in the request authenticates it.
+	Authenticators utils.JSONStrings `json:"authenticators"`
+	// SkipMethods are not authenticated, same as the quota names.
+	// e.g., "grpc:///pkg.Service/Method", "/pkg.Service/Method", "grpc" or "*".
+	SkipMethods utils.JSONStrings `json:"skipMethods"`
+	// BuiltInSkipMethods are the health, metrics and favicon methods.
+	BuiltInSkipMethods utils.JSONStrings `json:"builtInSkipMethods"`
+	// Rules are the requirements on the principal, every matching rule must be met.
+	Rules []*AuthRuleConfig `json:"rules"`
+}
+
+// AuthRuleConfig defines the requirements on the principal of some methods.
+type AuthRuleConfig struct {
+	// Methods selects the requests, same as SkipMethods.
+	Methods utils.JSONStrings `json:"methods"`
+	// Authenticators accepts only the principals authenticated by one of them.
+	Authenticators utils.JSONStrings `json:"authenticators"`
+	// Subjects accepts only the principals with one of the subjects.
+	Subjects utils.JSONStrings `json:"subjects"`
+	// Scopes accepts only the principals having all the scopes.
+	Scopes utils.JSONStrings `json:"scopes"`
+}
+
+var defaultAuthConfig = AuthConfig{
+	Authenticators: utils.JSONStrings{auth.JWTAuthenticatorName, auth.APIKeyAuthenticatorName, auth.MTLSAuthenticatorName},
+	BuiltInSkipMethods: utils.JSONStrings{
+		healthpb.Health_Check_FullMethodName,
+		metricspb.Metrics_Fetch_FullMethodName,
+		requestpb.DefaultHandlers_Favicon_FullMethodName,
+	},
+}
+
+func init() {
+	server.AddInterceptor(AuthInterceptorName, NewAuthInterceptor)
+}
+
+// NewAuthInterceptor initializes the authentication interceptor and watches its configuration.
+func NewAuthInterceptor() (server.ServerInterceptor, error) {
+	conf, cancel, err := config.Bind(constant.ConfigInterceptorServerAuthPrefix, defaultAuthConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &Auth{conf: conf, cancel: cancel}, nil
+}
+
+// Name returns the interceptor's unique name.
+func (*Auth) Name() string {
+	return AuthInterceptorName
+}
+
+// Close stops watching the configuration once the interceptor is removed from the chain.
+func (a *Auth) Close() error {
+	a.cancel()
+	return nil
+}
+
+// Interceptor returns the middleware authenticating unary requests.
+func (a *Auth) Interceptor() server.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *server.UnaryServerInfo, handler server.UnaryHandler) (resp any, err error) {
+		logger.L(ctx).Debug("start server interceptor", "interceptor", a.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+		ctx, err = a.authenticate(ctx, info.Protocol, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor returns the middleware authenticating streams when they are opened.
+func (a *Auth) StreamInterceptor() server.StreamServerInterceptor {
+	return func(srv any, ss server.ServerStream, info *server.StreamServerInfo, handler server.StreamHandler) error {
+		logger.L(ss.Context()).Debug("start server stream interceptor", "interceptor", a.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+		ctx, err := a.authenticate(ss.Context(), info.Protocol, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, server.WrapServerStream(ss, ctx))
+	}
+}
+
+// authenticate returns the context carrying the principal of the request.
+func (a *Auth) authenticate(ctx context.Context, protocol, method string) (context.Context, error) {
+	conf := a.conf.Load()
+	if auth.MatchMethods(conf.BuiltInSkipMethods, protocol, method) || auth.MatchMethods(conf.SkipMethods, protocol, method) {
+		return ctx, nil
+	}
+	var principal *auth.Principal
+	for _, name := range conf.Authenticators {
+		authenticator, err := auth.GetAuthenticator(name)
+		if err != nil {
+			logger.L(ctx).Error("get authenticator fail", "authenticator", name, "err", err)
+			return ctx, status.Error(codes.Internal, "authenticator unavailable")
+		}
+		p, err := authenticator.Authenticate(ctx)
+		if errors.Is(err, auth.ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			logger.L(ctx).Debug("authenticate fail", "authenticator", name, "full_method", method, "err", err)
+			return ctx, status.Error(codes.Unauthenticated, "invalid credentials")
+		}
+		principal = p
+		break
+	}
+	if principal == nil {
+		return ctx, status.Error(codes.Unauthenticated, "missing credentials")
+	}
+	for _, rule := range conf.Rules {
+		if rule != nil && auth.MatchMethods(rule.Methods, protocol, method) && !rule.allow(principal) {
+			return ctx, status.Error(codes.PermissionDenied, "permission denied")
+		}
+	}
+	return auth.NewContext(ctx, principal), nil
+}
+
+// allow reports whether the principal meets the requirements of the rule.
+func (r *AuthRuleConfig) allow(principal *auth.Principal) bool {
+	if len(r.Authenticators) != 0 && !r.Authenticators.Contains(principal.Authenticator) {
+		return false
+	}
+	if len(r.Subjects) != 0 && !r.Subjects.Contains(principal.Subject) {
+		return false
+	}
+	return principal.HasScopes(r.Scopes...)
+}
@@ -0,0 +1,100 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/server"
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/pkg/auth"
+	"github.com/asjard/asjard/pkg/authz"
+	"github.com/asjard/asjard/pkg/protobuf/authzpb"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// AuthzInterceptorName is the unique identifier for the authorization interceptor.
+	AuthzInterceptorName = "authz"
+)
+
+// Authz authorizes the principal of the requests against the authz option of the methods
+// with the configured policy engine. It should be placed after the auth interceptor.
+type Authz struct {
+	conf   *config.Binding[AuthzConfig]
+	cancel func()
+}
+
+// AuthzConfig defines the authorization of incoming requests.
+type AuthzConfig struct {
+	// Engine is the policy engine evaluating the requests.
+	Engine string `json:"engine"`
+}
+
+var defaultAuthzConfig = AuthzConfig{
+	Engine: authz.DefaultPolicyEngineName,
+}
+
+func init() {
+	server.AddInterceptor(AuthzInterceptorName, NewAuthzInterceptor)
+}
+
+// NewAuthzInterceptor initializes the authorization interceptor and watches its configuration.
+func NewAuthzInterceptor() (server.ServerInterceptor, error) {
+	conf, cancel, err := config.Bind(constant.ConfigInterceptorServerAuthzPrefix, defaultAuthzConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &Authz{conf: conf, cancel: cancel}, nil
+}
+
+// Name returns the interceptor's unique name.
+func (*Authz) Name() string {
+	return AuthzInterceptorName
+}
+
+// Close stops watching the configuration once the interceptor is removed from the chain.
+func (a *Authz) Close() error {
+	a.cancel()
+	return nil
+}
+
+// Interceptor returns the middleware authorizing unary requests.
+func (a *Authz) Interceptor() server.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *server.UnaryServerInfo, handler server.UnaryHandler) (resp any, err error) {
+		logger.L(ctx).Debug("start server interceptor", "interceptor", a.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+		if err := a.authorize(ctx, info.Protocol, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor returns the middleware authorizing streams when they are opened.
+func (a *Authz) StreamInterceptor() server.StreamServerInterceptor {
+	return func(srv any, ss server.ServerStream, info *server.StreamServerInfo, handler server.StreamHandler) error {
+		logger.L(ss.Context()).Debug("start server stream interceptor", "interceptor", a.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+		if err := a.authorize(ss.Context(), info.Protocol, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authorize evaluates the request with the policy engine.
+func (a *Authz) authorize(ctx context.Context, protocol, method string) error {
+	name := a.conf.Load().Engine
+	engine, err := authz.GetPolicyEngine(name)
+	if err != nil {
+		logger.L(ctx).Error("get policy engine fail", "engine", name, "err", err)
+		return status.Error(codes.Internal, "policy engine unavailable")
+	}
+	principal, _ := auth.FromContext(ctx)
+	return engine.Authorize(ctx, &authz.Request{
+		Protocol:   protocol,
+		FullMethod: method,
+		Principal:  principal,
+		Authz:      authzpb.GetMethodAuthz(method),
+	})
+}
@@ -3,13 +3,23 @@ package interceptors
 import (
 	"context"
 	"errors"
+	"net/http"
 	"os"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/asjard/asjard/core/config"
 	"github.com/asjard/asjard/core/server"
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/pkg/auth"
 	_ "github.com/asjard/asjard/pkg/config/mem"
+	"github.com/asjard/asjard/pkg/protobuf/authzpb"
+	"github.com/asjard/asjard/pkg/quota"
+	"github.com/asjard/asjard/utils"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/metadata"
 )
 
 func TestMain(m *testing.M) {
@@ -60,3 +70,194 @@ func TestBasicNamesAndMetricsPassThrough(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "ok", resp)
 }
+
+type testServerStream struct{ msg any }
+
+func (testServerStream) Context() context.Context { return context.Background() }
+func (testServerStream) SendMsg(any) error        { return nil }
+func (s testServerStream) RecvMsg(m any) error {
+	*(m.(*validatableRequest)) = s.msg.(validatableRequest)
+	return nil
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	info := &server.StreamServerInfo{FullMethod: "/test", Protocol: "grpc"}
+	err := (&Panic{}).StreamInterceptor()(nil, testServerStream{}, info, func(any, server.ServerStream) error {
+		panic("boom")
+	})
+	require.Error(t, err)
+
+	wantErr := errors.New("invalid")
+	err = (&Validate{}).StreamInterceptor()(nil, testServerStream{msg: validatableRequest{err: wantErr}}, info, func(_ any, ss server.ServerStream) error {
+		return ss.RecvMsg(&validatableRequest{})
+	})
+	require.ErrorIs(t, err, wantErr)
+}
+
+type fakeQuotaStore struct{ keys []string }
+
+func (s *fakeQuotaStore) Take(_ context.Context, key string, interval time.Duration, burst int) (bool, int, time.Duration, error) {
+	s.keys = append(s.keys, key)
+	return len(s.keys) <= burst, burst - len(s.keys), interval, nil
+}
+
+func TestQuotaInterceptor(t *testing.T) {
+	info := &server.UnaryServerInfo{FullMethod: "/api.v1.Test/Get", Protocol: "grpc"}
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-app", "caller", "x-tenant-id", "t1"))
+
+	t.Run("store", func(t *testing.T) {
+		store := &fakeQuotaStore{}
+		quota.AddStore("fake", func(string) (quota.Store, error) { return store, nil })
+		quota := &Quota{locals: make(map[string]*quotaLocal), conf: &QuotaConfig{
+			Enabled: true,
+			Store:   "fake",
+			Quotas: []*QuotaRuleConfig{
+				{Name: "grpc:///api.v1.Test/Get", App: "*", Key: "x-tenant-id", Limit: 1},
+				{Name: "*", App: "other", Limit: 1},
+			},
+		}}
+		_, err := quota.Interceptor()(ctx, nil, info, handler)
+		require.NoError(t, err)
+		_, err = quota.Interceptor()(ctx, nil, info, handler)
+		require.Error(t, err)
+		require.Len(t, store.keys, 2)
+		require.Contains(t, store.keys[0], "grpc:///api.v1.Test/Get:app=caller:x-tenant-id=t1")
+	})
+
+	t.Run("store created once", func(t *testing.T) {
+		created := 0
+		quota.AddStore("counted", func(string) (quota.Store, error) {
+			created++
+			return &fakeQuotaStore{}, nil
+		})
+		conf := &QuotaConfig{Enabled: true, Store: "counted", Quotas: []*QuotaRuleConfig{{Name: "*", Limit: 10}}}
+		quota := &Quota{locals: make(map[string]*quotaLocal), conf: conf}
+		for i := 0; i < 3; i++ {
+			_, err := quota.Interceptor()(ctx, nil, info, handler)
+			require.NoError(t, err)
+		}
+		require.Equal(t, 1, created)
+
+		// A new configuration creates the store again.
+		newConf := *conf
+		quota.conf = &newConf
+		_, err := quota.Interceptor()(ctx, nil, info, handler)
+		require.NoError(t, err)
+		require.Equal(t, 2, created)
+	})
+
+	t.Run("local limiters bounded", func(t *testing.T) {
+		quota := &Quota{locals: make(map[string]*quotaLocal)}
+		for i := 0; i < quotaMaxLocals+10; i++ {
+			quota.takeLocal(strconv.Itoa(i), time.Minute, 1, 1)
+		}
+		require.Len(t, quota.locals, quotaMaxLocals)
+		require.NotContains(t, quota.locals, "0")
+		require.Contains(t, quota.locals, strconv.Itoa(quotaMaxLocals+9))
+
+		// The refilled limiters are removed by the sweep.
+		quota.locals = map[string]*quotaLocal{"refilled": {limiter: rate.NewLimiter(rate.Every(time.Minute), 1), burst: 1}}
+		quota.lastSweep = time.Time{}
+		quota.takeLocal("new", time.Minute, 1, 1)
+		require.NotContains(t, quota.locals, "refilled")
+		require.Contains(t, quota.locals, "new")
+	})
+
+	t.Run("local fallback", func(t *testing.T) {
+		quota := &Quota{locals: make(map[string]*quotaLocal), conf: &QuotaConfig{
+			Enabled: true,
+			Store:   "notExist",
+			Quotas:  []*QuotaRuleConfig{{Name: "grpc", Limit: 2, Period: utils.JSONDuration{Duration: time.Minute}}},
+		}}
+		for i := 0; i < 2; i++ {
+			_, err := quota.Interceptor()(ctx, nil, info, handler)
+			require.NoError(t, err)
+		}
+		_, err := quota.Interceptor()(ctx, nil, info, handler)
+		require.Error(t, err)
+
+		_, err = quota.Interceptor()(ctx, nil, &server.UnaryServerInfo{FullMethod: "/api.v1.Test/Get", Protocol: "rest"}, handler)
+		require.NoError(t, err)
+	})
+}
+
+type fakeAuthenticator struct{}
+
+func (fakeAuthenticator) Authenticate(ctx context.Context) (*auth.Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	switch users := md.Get("x-fake-user"); {
+	case len(users) == 0:
+		return nil, auth.ErrNoCredentials
+	case users[0] == "invalid":
+		return nil, errors.New("invalid user")
+	default:
+		return &auth.Principal{Subject: users[0], Authenticator: "fake", Scopes: md.Get("x-fake-scope")}, nil
+	}
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	auth.AddAuthenticator("fake", func(string) (auth.Authenticator, error) { return fakeAuthenticator{}, nil })
+	require.NoError(t, config.Set("asjard.interceptors.server.auth.authenticators", "fake"))
+	require.NoError(t, config.Set("asjard.interceptors.server.auth.skipMethods", "/api.v1.Test/Public"))
+	require.NoError(t, config.Set("asjard.interceptors.server.auth.rules", []map[string]any{
+		{"methods": []string{"grpc:///api.v1.Test/Admin"}, "scopes": []string{"admin"}},
+	}))
+	interceptor, err := NewAuthInterceptor()
+	require.NoError(t, err)
+	require.Equal(t, AuthInterceptorName, interceptor.Name())
+
+	call := func(method string, kvs ...string) (*auth.Principal, error) {
+		var principal *auth.Principal
+		_, err := interceptor.Interceptor()(metadata.NewIncomingContext(context.Background(), metadata.Pairs(kvs...)), nil,
+			&server.UnaryServerInfo{FullMethod: method, Protocol: "grpc"},
+			func(ctx context.Context, _ any) (any, error) {
+				principal, _ = auth.FromContext(ctx)
+				return nil, nil
+			})
+		return principal, err
+	}
+
+	principal, err := call("/api.v1.Test/Get", "x-fake-user", "u1")
+	require.NoError(t, err)
+	require.Equal(t, "u1", principal.Subject)
+
+	_, err = call("/api.v1.Test/Get")
+	require.Equal(t, uint32(http.StatusUnauthorized), status.FromError(err).Status)
+	_, err = call("/api.v1.Test/Get", "x-fake-user", "invalid")
+	require.Equal(t, uint32(http.StatusUnauthorized), status.FromError(err).Status)
+
+	principal, err = call("/api.v1.Test/Public")
+	require.NoError(t, err)
+	require.Nil(t, principal)
+	_, err = call("/api.v1.Health/Check")
+	require.NoError(t, err)
+
+	_, err = call("/api.v1.Test/Admin", "x-fake-user", "u1")
+	require.Equal(t, uint32(http.StatusForbidden), status.FromError(err).Status)
+	_, err = call("/api.v1.Test/Admin", "x-fake-user", "u1", "x-fake-scope", "admin")
+	require.NoError(t, err)
+}
+
+func TestAuthzInterceptor(t *testing.T) {
+	authzpb.AddMethodAuthz("/api.v1.Authz/Delete", &authzpb.Authz{Permissions: []string{"user:delete"}})
+	interceptor, err := NewAuthzInterceptor()
+	require.NoError(t, err)
+	require.Equal(t, AuthzInterceptorName, interceptor.Name())
+
+	call := func(method string, principal *auth.Principal) error {
+		ctx := context.Background()
+		if principal != nil {
+			ctx = auth.NewContext(ctx, principal)
+		}
+		_, err := interceptor.Interceptor()(ctx, nil, &server.UnaryServerInfo{FullMethod: method, Protocol: "grpc"},
+			func(context.Context, any) (any, error) { return nil, nil })
+		return err
+	}
+
+	require.NoError(t, call("/api.v1.Authz/Get", nil))
+	require.Equal(t, uint32(http.StatusUnauthorized), status.FromError(call("/api.v1.Authz/Delete", nil)).Status)
+	require.Equal(t, uint32(http.StatusForbidden),
+		status.FromError(call("/api.v1.Authz/Delete", &auth.Principal{Subject: "u1", Scopes: []string{"user:read"}})).Status)
+	require.NoError(t, call("/api.v1.Authz/Delete", &auth.Principal{Subject: "u1", Scopes: []string{"user:*"}}))
+}
@@ -79,6 +79,22 @@ func (m Metrics) Interceptor() server.UnaryServerInterceptor {
 	}
 }
 
+// StreamInterceptor returns the middleware function that records telemetry data for streaming RPCs.
+// Latency is measured over the whole lifetime of the stream.
+func (m Metrics) StreamInterceptor() server.StreamServerInterceptor {
+	return func(srv any, ss server.ServerStream, info *server.StreamServerInfo, handler server.StreamHandler) error {
+		logger.L(ss.Context()).Debug("start server stream interceptor", "interceptor", m.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+
+		start := time.Now()
+		err := handler(srv, ss)
+
+		st := status.FromError(err)
+		m.requestTotal.Inc(strconv.Itoa(int(st.Code)), info.FullMethod, info.Protocol)
+		m.requestLatency.Observe(info.FullMethod, info.Protocol, time.Since(start).Seconds())
+		return err
+	}
+}
+
 // computeApproximateRequestSize calculates the total byte size of an HTTP request
 // including URI, Method, Protocol version, and Headers.
 func computeApproximateRequestSize(r *rest.Context) int {
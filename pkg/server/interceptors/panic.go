@@ -57,6 +57,25 @@ func (p *Panic) Interceptor() server.UnaryServerInterceptor {
 	}
 }
 
+// StreamInterceptor returns the middleware function that handles panic recovery for streaming RPCs.
+func (p *Panic) StreamInterceptor() server.StreamServerInterceptor {
+	return func(srv any, ss server.ServerStream, info *server.StreamServerInfo, handler server.StreamHandler) (err error) {
+		ctx := ss.Context()
+		logger.L(ctx).Debug("start server stream interceptor", "interceptor", p.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+		defer func() {
+			if rcv := recover(); rcv != nil {
+				logger.L(ctx).Error("stream panic",
+					"err", rcv,
+					"method", info.FullMethod,
+					"protocol", info.Protocol,
+					"stack", string(debug.Stack()))
+				err = status.InternalServerError()
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
 // Name returns the interceptor's unique name.
 func (*Panic) Name() string {
 	return PanicInterceptorName
@@ -4,12 +4,433 @@ The quota interceptor is responsible for traffic policing and rate limiting.
 */
 package interceptors
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/constant"
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/runtime"
+	"github.com/asjard/asjard/core/server"
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/pkg/quota"
+	"github.com/asjard/asjard/pkg/server/rest"
+	"github.com/asjard/asjard/utils"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/metadata"
+)
+
 const (
 	// QuotaInterceptorName is the unique identifier for the quota/rate-limiting interceptor.
 	QuotaInterceptorName = "quota"
+
+	// HeaderRateLimitLimit is the response header carrying the quota of the request.
+	HeaderRateLimitLimit = "X-RateLimit-Limit"
+	// HeaderRateLimitRemaining is the response header carrying the remaining quota.
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	// HeaderRetryAfter is the response header carrying the seconds to wait after a rejection.
+	HeaderRetryAfter = "Retry-After"
+
+	// quotaCallerApp is the metadata key identifying the caller application.
+	quotaCallerApp = "x-request-app"
+	// quotaAnyApp creates a separate quota for every caller application.
+	quotaAnyApp = "*"
+)
+
+// Quota enforces cluster-wide quotas shared by all instances of the service.
+// Counters are kept in a quota.Store, e.g. Redis with GCRA (generic cell rate algorithm),
+// if the store is unreachable every instance falls back to limiting locally.
+type Quota struct {
+	conf *QuotaConfig
+	cm   sync.RWMutex
+
+	// locals holds the fallback limiters used while the store is unreachable.
+	locals    map[string]*quotaLocal
+	lastSweep time.Time
+	lm        sync.Mutex
+
+	// store is the quota store of storeConf, resolved once per configuration,
+	// storeErr is retried after quotaStoreRetry unless the store is not registered.
+	store     quota.Store
+	storeErr  error
+	storeConf *QuotaConfig
+	storeAt   time.Time
+	sm        sync.Mutex
+
+	// storeDown records whether the last store call failed, used to log state changes once.
+	storeDown atomic.Bool
+	// removeListener stops watching the configuration.
+	removeListener func()
+}
+
+// QuotaConfig defines the quotas applied to incoming requests.
+type QuotaConfig struct {
+	Enabled bool `json:"enabled"`
+	// Store is the name of the quota store registered with quota.AddStore, e.g. redis.
+	Store string `json:"store"`
+	// Client is the client name of the store, e.g. a client in asjard.stores.redis.clients.
+	Client string `json:"client"`
+	// Quotas lists the quota rules, every matching rule must have quota left.
+	Quotas []*QuotaRuleConfig `json:"quotas"`
+}
+
+// QuotaRuleConfig defines a single quota.
+type QuotaRuleConfig struct {
+	// Name selects the requests, same as the ratelimiter.
+	// e.g., "grpc:///pkg.Service/Method", "/pkg.Service/Method", "grpc" or "*".
+	Name string `json:"name"`
+	// App limits the rule to a caller application (x-request-app).
+	// Empty matches every caller with a shared quota,
+	// "*" gives every caller application its own quota.
+	App string `json:"app"`
+	// Key is a header or metadata key, e.g. x-tenant-id.
+	// If set every value of the key gets its own quota.
+	Key string `json:"key"`
+	// Limit is the number of requests allowed in Period.
+	Limit int `json:"limit"`
+	// Burst is the number of requests allowed at once, defaults to Limit.
+	Burst int `json:"burst"`
+	// Period is the time window of Limit, defaults to 1s.
+	Period utils.JSONDuration `json:"period"`
+}
+
+// quotaLocal is a local fallback limiter of a bucket.
+type quotaLocal struct {
+	limiter  *rate.Limiter
+	burst    int
+	lastUsed time.Time
+}
+
+// quotaResult is the outcome of a quota check.
+type quotaResult struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	retryAfter time.Duration
+}
+
+var (
+	defaultQuotaConfig = QuotaConfig{
+		Store:  "redis",
+		Client: "default",
+	}
 )
 
-// Quota represents the rate-limiting interceptor component.
-// It is designed to protect the server from being overwhelmed by too many requests
-// (e.g., implementing Token Bucket or Leaky Bucket algorithms).
-type Quota struct{}
+const (
+	// quotaMaxLocals bounds the local fallback limiters,
+	// the least recently used one is evicted when full.
+	quotaMaxLocals = 10000
+	// quotaLocalSweep is the interval of removing the refilled local limiters.
+	quotaLocalSweep = time.Minute
+	// quotaStoreRetry is the interval of retrying a quota store which failed to be created.
+	quotaStoreRetry = 10 * time.Second
+)
+
+func init() {
+	// Register the quota interceptor for all server instances.
+	server.AddInterceptor(QuotaInterceptorName, NewQuotaInterceptor)
+}
+
+// NewQuotaInterceptor initializes the quota interceptor and starts the configuration watcher.
+func NewQuotaInterceptor() (server.ServerInterceptor, error) {
+	q := &Quota{
+		locals: make(map[string]*quotaLocal),
+	}
+	if err := q.loadAndWatch(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Name returns the interceptor's unique name.
+func (*Quota) Name() string {
+	return QuotaInterceptorName
+}
+
+// Close stops watching the configuration once the interceptor is removed from the chain.
+func (q *Quota) Close() error {
+	if q.removeListener != nil {
+		q.removeListener()
+	}
+	return nil
+}
+
+// Interceptor returns the middleware that enforces the quotas.
+func (q *Quota) Interceptor() server.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *server.UnaryServerInfo, handler server.UnaryHandler) (resp any, err error) {
+		logger.L(ctx).Debug("start server interceptor", "interceptor", q.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+		if err := q.check(ctx, info.Protocol, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor returns the middleware that enforces the quotas when a stream is opened.
+func (q *Quota) StreamInterceptor() server.StreamServerInterceptor {
+	return func(srv any, ss server.ServerStream, info *server.StreamServerInfo, handler server.StreamHandler) error {
+		logger.L(ss.Context()).Debug("start server stream interceptor", "interceptor", q.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+		if err := q.check(ss.Context(), info.Protocol, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// check takes a token of every matching quota and writes the rest quota headers.
+func (q *Quota) check(ctx context.Context, protocol, method string) error {
+	q.cm.RLock()
+	conf := q.conf
+	q.cm.RUnlock()
+	if !conf.Enabled {
+		return nil
+	}
+
+	callerApp := quotaMetadata(ctx, quotaCallerApp)
+	var result *quotaResult
+	for _, rule := range conf.Quotas {
+		if !rule.match(protocol, method, callerApp) {
+			continue
+		}
+		current := q.take(ctx, conf, rule, rule.bucket(ctx, callerApp))
+		// Report the most restrictive quota.
+		if result == nil || !current.allowed || (result.allowed && current.remaining < result.remaining) {
+			result = current
+		}
+		if !current.allowed {
+			break
+		}
+	}
+	if result == nil {
+		return nil
+	}
+
+	if rtx, ok := ctx.(*rest.Context); ok {
+		rtx.Response.Header.Set(HeaderRateLimitLimit, strconv.Itoa(result.limit))
+		rtx.Response.Header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.remaining))
+		if !result.allowed {
+			rtx.Response.Header.Set(HeaderRetryAfter, strconv.Itoa(int(math.Ceil(result.retryAfter.Seconds()))))
+		}
+	}
+	if !result.allowed {
+		logger.L(ctx).Debug("quota exceeded", "protocol", protocol, "method", method, "app", callerApp)
+		return status.TooManyRequest()
+	}
+	return nil
+}
+
+// take consumes a token from the bucket in the store, or the local limiter if the store is unreachable.
+func (q *Quota) take(ctx context.Context, conf *QuotaConfig, rule *QuotaRuleConfig, bucket string) *quotaResult {
+	limit, burst, period := rule.limits()
+	interval := period / time.Duration(limit)
+	if interval <= 0 {
+		interval = time.Microsecond
+	}
+
+	store, err := q.getStore(conf)
+	if err == nil {
+		var (
+			allowed    bool
+			remaining  int
+			retryAfter time.Duration
+		)
+		allowed, remaining, retryAfter, err = store.Take(ctx,
+			runtime.GetAPP().ResourceKey("quota", bucket,
+				runtime.WithDelimiter(":"),
+				runtime.WithoutRegion(true),
+				runtime.WithoutAz(true)),
+			interval, burst)
+		if err == nil {
+			if q.storeDown.CompareAndSwap(true, false) {
+				logger.Info("quota store recovered", "store", conf.Store, "client", conf.Client)
+			}
+			return &quotaResult{
+				allowed:    allowed,
+				limit:      limit,
+				remaining:  remaining,
+				retryAfter: retryAfter,
+			}
+		}
+	}
+	if q.storeDown.CompareAndSwap(false, true) {
+		logger.Warn("quota store unreachable, fallback to local limiter", "store", conf.Store, "client", conf.Client, "err", err)
+	}
+	return q.takeLocal(bucket, interval, limit, burst)
+}
+
+// getStore returns the quota store of the configuration, it is created once per configuration
+// and a failed creation is retried after quotaStoreRetry, a store which is not registered is not retried.
+func (q *Quota) getStore(conf *QuotaConfig) (quota.Store, error) {
+	q.sm.Lock()
+	defer q.sm.Unlock()
+	if q.storeConf == conf && (q.storeErr == nil ||
+		errors.Is(q.storeErr, quota.ErrStoreNotFound) ||
+		time.Since(q.storeAt) < quotaStoreRetry) {
+		return q.store, q.storeErr
+	}
+	q.store, q.storeErr = quota.NewStore(conf.Store, conf.Client)
+	q.storeConf = conf
+	q.storeAt = time.Now()
+	return q.store, q.storeErr
+}
+
+// takeLocal consumes a token from the local fallback limiter of bucket.
+func (q *Quota) takeLocal(bucket string, interval time.Duration, limit, burst int) *quotaResult {
+	now := time.Now()
+	q.lm.Lock()
+	local, ok := q.locals[bucket]
+	if !ok {
+		q.evictLocals(now)
+		local = &quotaLocal{
+			limiter: rate.NewLimiter(rate.Every(interval), burst),
+			burst:   burst,
+		}
+		q.locals[bucket] = local
+	}
+	local.lastUsed = now
+	limiter := local.limiter
+	q.lm.Unlock()
+
+	if limiter.AllowN(now, 1) {
+		return &quotaResult{
+			allowed:   true,
+			limit:     limit,
+			remaining: int(limiter.TokensAt(now)),
+		}
+	}
+	return &quotaResult{
+		limit:      limit,
+		retryAfter: time.Duration((1 - limiter.TokensAt(now)) * float64(interval)),
+	}
+}
+
+// evictLocals makes room for a new local limiter, the caller must hold lm.
+// The refilled limiters are removed every quotaLocalSweep since they are the same as new ones,
+// then the least recently used limiter is removed if there are still quotaMaxLocals limiters.
+func (q *Quota) evictLocals(now time.Time) {
+	if now.Sub(q.lastSweep) >= quotaLocalSweep || len(q.locals) >= quotaMaxLocals {
+		q.lastSweep = now
+		for bucket, local := range q.locals {
+			if local.limiter.TokensAt(now) >= float64(local.burst) {
+				delete(q.locals, bucket)
+			}
+		}
+	}
+	if len(q.locals) < quotaMaxLocals {
+		return
+	}
+	var (
+		oldest string
+		found  *quotaLocal
+	)
+	for bucket, local := range q.locals {
+		if found == nil || local.lastUsed.Before(found.lastUsed) {
+			oldest, found = bucket, local
+		}
+	}
+	delete(q.locals, oldest)
+}
+
+// loadAndWatch handles initial loading and dynamic hot-reloads of configuration.
+func (q *Quota) loadAndWatch() error {
+	if err := q.load(); err != nil {
+		return err
+	}
+	q.removeListener = config.AddPrefixListener(constant.ConfigInterceptorServerQuotaPrefix, q.watch)
+	return nil
+}
+
+// load parses the configuration and resets the local fallback limiters.
+func (q *Quota) load() error {
+	conf := defaultQuotaConfig
+	if err := config.GetWithUnmarshal(constant.ConfigInterceptorServerQuotaPrefix, &conf); err != nil {
+		return err
+	}
+	// A store which is not registered would silently limit every instance locally.
+	if conf.Enabled && !quota.HasStore(conf.Store) {
+		return fmt.Errorf("quota store '%s' not registered, import its store package, e.g. pkg/stores/xredis for redis", conf.Store)
+	}
+	q.cm.Lock()
+	q.conf = &conf
+	q.cm.Unlock()
+
+	q.lm.Lock()
+	q.locals = make(map[string]*quotaLocal)
+	q.lm.Unlock()
+	return nil
+}
+
+func (q *Quota) watch(event *config.Event) {
+	if err := q.load(); err != nil {
+		logger.Error("quota load config fail", "err", err)
+	}
+}
+
+// match reports whether the rule applies to the request.
+func (r *QuotaRuleConfig) match(protocol, method, callerApp string) bool {
+	if r.App != "" && r.App != quotaAnyApp && r.App != callerApp {
+		return false
+	}
+	return r.Name == AllMethods ||
+		r.Name == protocol ||
+		r.Name == method ||
+		r.Name == protocol+"://"+method
+}
+
+// bucket returns the counter name of the request within the rule.
+func (r *QuotaRuleConfig) bucket(ctx context.Context, callerApp string) string {
+	var builder strings.Builder
+	builder.WriteString(r.Name)
+	if r.App != "" {
+		builder.WriteString(":app=")
+		if r.App == quotaAnyApp {
+			builder.WriteString(callerApp)
+		} else {
+			builder.WriteString(r.App)
+		}
+	}
+	if r.Key != "" {
+		builder.WriteString(":" + r.Key + "=")
+		builder.WriteString(quotaMetadata(ctx, r.Key))
+	}
+	return builder.String()
+}
+
+// limits returns the rule limits with the defaults applied.
+func (r *QuotaRuleConfig) limits() (limit, burst int, period time.Duration) {
+	limit, burst, period = r.Limit, r.Burst, r.Period.Duration
+	if limit <= 0 {
+		limit = 1
+	}
+	if burst <= 0 {
+		burst = limit
+	}
+	if period <= 0 {
+		period = time.Second
+	}
+	return
+}
+
+// quotaMetadata reads a header of a rest request or the incoming metadata of other protocols.
+func quotaMetadata(ctx context.Context, key string) string {
+	if rtx, ok := ctx.(*rest.Context); ok {
+		if values := rtx.GetHeaderParam(key); len(values) != 0 {
+			return values[0]
+		}
+		return ""
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) != 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
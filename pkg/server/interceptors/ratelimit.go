@@ -27,6 +27,8 @@ type RateLimiter struct {
 	lm       sync.RWMutex
 
 	conf *RateLimiterConfig
+	// removeListener stops watching the configuration.
+	removeListener func()
 }
 
 // RateLimiterConfig defines the thresholds and scope for rate limiting.
@@ -96,17 +98,40 @@ func (rl *RateLimiter) Interceptor() server.UnaryServerInterceptor {
 	}
 }
 
+// StreamInterceptor returns the middleware that enforces rate limits when a stream is opened.
+// Messages within an accepted stream are not limited.
+func (rl *RateLimiter) StreamInterceptor() server.StreamServerInterceptor {
+	return func(srv any, ss server.ServerStream, info *server.StreamServerInfo, handler server.StreamHandler) error {
+		logger.L(ss.Context()).Debug("start server stream interceptor", "interceptor", rl.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+		if !rl.conf.Enabled {
+			return handler(srv, ss)
+		}
+		if rl.getLimiter(info.Protocol, info.FullMethod).Allow() {
+			return handler(srv, ss)
+		}
+		return status.TooManyRequest()
+	}
+}
+
 // Name returns the interceptor's unique name.
 func (*RateLimiter) Name() string {
 	return RateLimiterInterceptorName
 }
 
+// Close stops watching the configuration once the interceptor is removed from the chain.
+func (rl *RateLimiter) Close() error {
+	if rl.removeListener != nil {
+		rl.removeListener()
+	}
+	return nil
+}
+
 // loadAndWatch handles initial loading and dynamic hot-reloads of configuration.
 func (rl *RateLimiter) loadAndWatch() error {
 	if err := rl.load(); err != nil {
 		return err
 	}
-	config.AddPrefixListener("asjard.interceptors.server.rateLimiter", rl.watch)
+	rl.removeListener = config.AddPrefixListener("asjard.interceptors.server.rateLimiter", rl.watch)
 	return nil
 }
 
@@ -27,6 +27,8 @@ func init() {
 type ReadEntity struct {
 	cm   sync.RWMutex
 	conf ReadEntityConfig
+	// removeListener stops watching the configuration.
+	removeListener func()
 }
 
 // ReadEntityConfig defines which methods should bypass automatic parameter parsing.
@@ -56,6 +58,14 @@ func (r *ReadEntity) Name() string {
 	return RestReadEntityInterceptorName
 }
 
+// Close stops watching the configuration once the interceptor is removed from the chain.
+func (r *ReadEntity) Close() error {
+	if r.removeListener != nil {
+		r.removeListener()
+	}
+	return nil
+}
+
 // NewReadEntityInterceptor initializes the interceptor and sets up dynamic configuration watching.
 func NewReadEntityInterceptor() (server.ServerInterceptor, error) {
 	readEntity := &ReadEntity{}
@@ -97,7 +107,7 @@ func (r *ReadEntity) loadAndWatch() error {
 	if err := r.load(); err != nil {
 		return err
 	}
-	config.AddPrefixListener("asjard.interceptors.server.restReadEntity", r.watch)
+	r.removeListener = config.AddPrefixListener("asjard.interceptors.server.restReadEntity", r.watch)
 	return nil
 }
 
@@ -10,6 +10,7 @@ import (
 	mtrace "github.com/asjard/asjard/core/trace"
 	"github.com/asjard/asjard/pkg/server/rest"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
@@ -52,6 +53,31 @@ func (*Trace) Name() string {
 	return TraceInterceptorName
 }
 
+// StreamInterceptor implements the tracing logic for streaming requests.
+// A single span covers the whole lifetime of the stream.
+func (t *Trace) StreamInterceptor() server.StreamServerInterceptor {
+	return func(srv any, ss server.ServerStream, info *server.StreamServerInfo, handler server.StreamHandler) error {
+		ctx := ss.Context()
+		logger.L(ctx).Debug("start server stream interceptor", "interceptor", t.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+		if !t.conf.Enabled {
+			return handler(srv, ss)
+		}
+
+		carrier := mtrace.NewTraceCarrier(ctx)
+		tx, span := t.tracer.Start(t.propagator.Extract(ctx, carrier),
+			info.Protocol+"://"+info.FullMethod,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.ServiceName(t.app.Instance.Name), semconv.ServiceNamespace(t.app.Instance.Group)))
+		defer span.End()
+
+		t.propagator.Inject(tx, carrier)
+
+		err := handler(srv, server.WrapServerStream(ss, trace.ContextWithRemoteSpanContext(tx, trace.SpanContextFromContext(tx))))
+		t.recordError(span, err)
+		return err
+	}
+}
+
 // Interceptor implements the tracing logic for every unary request.
 func (t *Trace) Interceptor() server.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *server.UnaryServerInfo, handler server.UnaryHandler) (resp any, err error) {
@@ -81,10 +107,24 @@ func (t *Trace) Interceptor() server.UnaryServerInterceptor {
 			// Attach TraceID to REST user values so it can be sent back in HTTP headers.
 			rtx.SetUserValue(rest.HeaderResponseRequestID, span.SpanContext().TraceID().String())
 			rtx.SetUserValue(rest.HeaderResponseRequestMethod, info.FullMethod)
-			return handler(rtx, req)
+			resp, err = handler(rtx, req)
+			t.recordError(span, err)
+			return resp, err
 		}
 
 		// 6. Pass the enriched context containing the span to the next handler.
-		return handler(trace.ContextWithRemoteSpanContext(tx, trace.SpanContextFromContext(tx)), req)
+		resp, err = handler(trace.ContextWithRemoteSpanContext(tx, trace.SpanContextFromContext(tx)), req)
+		t.recordError(span, err)
+		return resp, err
+	}
+}
+
+// recordError marks the span as failed, failed spans are always exported
+// if asjard.trace.sampler.alwaysOnError is enabled.
+func (t *Trace) recordError(span trace.Span, err error) {
+	if err == nil {
+		return
 	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
 }
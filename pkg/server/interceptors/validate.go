@@ -54,3 +54,28 @@ func (r *Validate) Interceptor() server.UnaryServerInterceptor {
 		return handler(ctx, req)
 	}
 }
+
+// StreamInterceptor returns the middleware function that validates every message received on a stream.
+func (r *Validate) StreamInterceptor() server.StreamServerInterceptor {
+	return func(srv any, ss server.ServerStream, info *server.StreamServerInfo, handler server.StreamHandler) error {
+		logger.L(ss.Context()).Debug("start server stream interceptor", "interceptor", r.Name(), "full_method", info.FullMethod, "protocol", info.Protocol)
+		return handler(srv, &validateServerStream{ServerStream: ss, fullMethod: info.FullMethod})
+	}
+}
+
+// validateServerStream validates incoming messages as soon as they are received.
+type validateServerStream struct {
+	server.ServerStream
+	fullMethod string
+}
+
+// RecvMsg receives the next message and validates it.
+func (s *validateServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if v, ok := m.(validatepb.Validater); ok {
+		return v.IsValid("", s.fullMethod)
+	}
+	return nil
+}
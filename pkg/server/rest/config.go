@@ -21,6 +21,7 @@ type Config struct {
 	Doc           DocConfig     `json:"doc"`     // Documentation and error page settings
 	Openapi       OpenapiConfig `json:"openapi"` // OpenAPI/Swagger generation and UI settings
 	Cors          CorsConfig    `json:"cors"`    // Cross-Origin Resource Sharing settings
+	Upload        UploadConfig  `json:"upload"`  // multipart/form-data upload settings
 	Options       OptionsConfig `json:"options"` // Low-level fasthttp server tuning options
 }
 
@@ -74,6 +75,13 @@ type CorsConfig struct {
 	MaxAge           utils.JSONDuration `json:"maxAge"` // How long the browser caches the preflight response
 }
 
+// UploadConfig defines the limits of the files uploaded with multipart/form-data.
+type UploadConfig struct {
+	// MaxSize is the size limit in bytes of each file, overridden by the max_upload_size http option.
+	// Unlimited if it is 0, the whole body is still limited by options.maxRequestBodySize.
+	MaxSize int64 `json:"maxSize"`
+}
+
 // OptionsConfig contains low-level performance and timeout settings for the fasthttp server.
 type OptionsConfig struct {
 	Concurrency                        int                `json:"concurrency"` // Max simultaneous requests
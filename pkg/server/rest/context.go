@@ -5,7 +5,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/asjard/asjard/core/logger"
 	"github.com/asjard/asjard/core/status"
@@ -13,15 +15,26 @@ import (
 	"github.com/valyala/fasthttp"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
 
 const (
-	MIME_XML   = "application/xml"
-	MIME_JSON  = "application/json"
-	MIME_ZIP   = "application/zip"
-	MIME_OCTET = "application/octet-stream"
+	// HeaderRequestTimeout is the header of the remaining time of a request,
+	// in milliseconds or a duration such as "1.5s".
+	HeaderRequestTimeout = "x-request-timeout"
+)
+
+const (
+	MIME_XML       = "application/xml"
+	MIME_JSON      = "application/json"
+	MIME_ZIP       = "application/zip"
+	MIME_OCTET     = "application/octet-stream"
+	MIME_PROTOBUF  = "application/x-protobuf"
+	MIME_MSGPACK   = "application/msgpack"
+	MIME_X_MSGPACK = "application/x-msgpack"
+
+	MIME_MULTIPART_FORM = "multipart/form-data"
+	MIME_POST_FORM      = "application/x-www-form-urlencoded"
 )
 
 var (
@@ -36,6 +49,8 @@ type Context struct {
 	*fasthttp.RequestCtx
 	errPage string
 	write   Writer
+	// maxUploadSize is the size limit of the multipart files, unlimited if it is 0.
+	maxUploadSize int64
 }
 
 type ctxKey int32
@@ -82,6 +97,38 @@ func (c *Context) SetContext(ctx context.Context) {
 	c.SetUserValue(userContext, ctx)
 }
 
+// withRequestTimeout sets the deadline of Context() from the x-request-timeout header.
+// The returned function releases the resources of the deadline.
+func (c *Context) withRequestTimeout() context.CancelFunc {
+	value := c.Request.Header.Peek(HeaderRequestTimeout)
+	if len(value) == 0 {
+		return func() {}
+	}
+	timeout, err := ParseRequestTimeout(string(value))
+	if err != nil {
+		logger.L(c).Warn("invalid request timeout", "timeout", string(value), "err", err)
+		return func() {}
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), timeout)
+	c.SetContext(ctx)
+	return cancel
+}
+
+// ParseRequestTimeout parses the value of the x-request-timeout header,
+// a number of milliseconds or a duration such as "1.5s".
+func ParseRequestTimeout(value string) (time.Duration, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// FormatRequestTimeout formats the remaining time of a request as the x-request-timeout header,
+// in milliseconds.
+func FormatRequestTimeout(timeout time.Duration) string {
+	return strconv.FormatInt(max(timeout.Milliseconds(), 0), 10)
+}
+
 // ReadEntity parses request parameters and serializes them into a Protobuf message.
 // The default order is: Query -> Header -> Body -> Path.
 // Later parameters override earlier ones if keys collide.
@@ -186,6 +233,7 @@ func (c *Context) SetWriter(writer Writer) {
 // Close cleans up the context and returns it to the pool for reuse.
 func (c *Context) Close() {
 	c.write = nil
+	c.maxUploadSize = 0
 	c.RequestCtx = nil
 	contextPool.Put(c)
 }
@@ -290,15 +338,18 @@ func (c *Context) ReadPathParamsToEntity(entity proto.Message) error {
 	return nil
 }
 
-// ReadBodyParamsToEntity unmarshals the JSON body into the Protobuf struct.
+// ReadBodyParamsToEntity reads the body into the Protobuf struct with the reader of its Content-Type,
+// see AddReader, a body without Content-Type or with a Content-Type without reader is ignored.
 func (c *Context) ReadBodyParamsToEntity(entity proto.Message) error {
-	body := c.JSONBodyParams()
-	if len(body) > 0 {
-		if err := protojson.Unmarshal(body, entity); err != nil {
-			return status.Errorf(codes.InvalidArgument, "read body params to entity fail: %v", err)
-		}
-	} else {
+	if len(c.Request.Body()) == 0 {
 		logger.L(c).Warn("read body params is empty", "request_method", utils.SafeByte2String(c.Method()))
+		return nil
 	}
-	return nil
+	contentType := utils.SafeByte2String(c.Request.Header.ContentType())
+	reader, ok := GetReader(contentType)
+	if !ok {
+		logger.L(c).Warn("request contentType not supported", "content_type", contentType)
+		return nil
+	}
+	return reader(c, entity)
 }
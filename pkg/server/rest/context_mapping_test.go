@@ -1,14 +1,47 @@
 package rest
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/asjard/asjard/core/config"
+	"github.com/asjard/asjard/core/server"
+	"github.com/asjard/asjard/core/status"
+	_ "github.com/asjard/asjard/pkg/config/mem"
+	"github.com/asjard/asjard/pkg/protobuf/filepb"
+	"github.com/asjard/asjard/pkg/protobuf/mqpb"
+	"github.com/asjard/asjard/pkg/protobuf/statuspb"
+	"github.com/fasthttp/websocket"
 	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
+func TestMain(m *testing.M) {
+	if err := config.Load(-1); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
 func TestContextParametersAndLifecycle(t *testing.T) {
 	raw := &fasthttp.RequestCtx{}
 	raw.Request.SetRequestURI("/users?id=1&id=2")
@@ -34,6 +67,39 @@ func TestContextParametersAndLifecycle(t *testing.T) {
 	c.Close()
 }
 
+func TestRequestTimeout(t *testing.T) {
+	for value, want := range map[string]time.Duration{
+		"1500": 1500 * time.Millisecond,
+		"2s":   2 * time.Second,
+	} {
+		timeout, err := ParseRequestTimeout(value)
+		require.NoError(t, err)
+		require.Equal(t, want, timeout)
+	}
+	_, err := ParseRequestTimeout("soon")
+	require.Error(t, err)
+	require.Equal(t, "1500", FormatRequestTimeout(1500*time.Millisecond))
+	require.Equal(t, "0", FormatRequestTimeout(-time.Second))
+
+	raw := &fasthttp.RequestCtx{}
+	raw.Request.Header.Set(HeaderRequestTimeout, "1500")
+	c := NewContext(raw)
+	cancel := c.withRequestTimeout()
+	deadline, ok := c.Context().Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(1500*time.Millisecond), deadline, 100*time.Millisecond)
+	cancel()
+	require.Error(t, c.Context().Err())
+	c.Close()
+
+	// No header, no deadline.
+	c = NewContext(&fasthttp.RequestCtx{})
+	c.withRequestTimeout()()
+	_, ok = c.Context().Deadline()
+	require.False(t, ok)
+	c.Close()
+}
+
 func TestMapForm(t *testing.T) {
 	type form struct {
 		Name    string
@@ -66,3 +132,398 @@ func TestCorsOriginValidation(t *testing.T) {
 	require.False(t, corsIsOriginValid(conf, "https://invalid.example"))
 	require.True(t, corsIsOriginValid(CorsConfig{AllowOrigins: []string{"*"}, allowAllOrigins: true}, "https://anything.example"))
 }
+
+func TestReadMultipartParamsToEntity(t *testing.T) {
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("upload_test.proto"),
+		Package:    proto.String("api.v1.uploadtest"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"file.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Upload"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("name"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				{Name: proto.String("avatar"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".asjard.api.File")},
+				{Name: proto.String("attachments"), Number: proto.Int32(3), Type: descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()},
+			},
+		}},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+	md := fd.Messages().ByName("Upload")
+
+	newRequest := func(maxUploadSize int64) *Context {
+		body := &bytes.Buffer{}
+		w := multipart.NewWriter(body)
+		require.NoError(t, w.WriteField("name", "codex"))
+		part, err := w.CreateFormFile("avatar", "avatar.png")
+		require.NoError(t, err)
+		part.Write([]byte("png"))
+		for _, content := range []string{"a", "bb"} {
+			part, err := w.CreateFormFile("attachments", "attachment.txt")
+			require.NoError(t, err)
+			part.Write([]byte(content))
+		}
+		require.NoError(t, w.Close())
+		raw := &fasthttp.RequestCtx{}
+		raw.Request.Header.SetMethod("POST")
+		raw.Request.Header.SetContentType(w.FormDataContentType())
+		raw.Request.SetBody(body.Bytes())
+		return NewContext(raw, WithMaxUploadSize(maxUploadSize))
+	}
+
+	c := newRequest(0)
+	entity := dynamicpb.NewMessage(md)
+	require.NoError(t, c.ReadEntity(entity))
+	require.Equal(t, "codex", entity.Get(md.Fields().ByName("name")).String())
+	avatar := entity.Get(md.Fields().ByName("avatar")).Message().Interface().(*filepb.File)
+	require.Equal(t, "avatar.png", avatar.Filename)
+	require.Equal(t, int64(3), avatar.Size)
+	require.Equal(t, []byte("png"), avatar.Content)
+	attachments := entity.Get(md.Fields().ByName("attachments")).List()
+	require.Equal(t, 2, attachments.Len())
+	c.Close()
+
+	c = newRequest(2)
+	require.Error(t, c.ReadEntity(dynamicpb.NewMessage(md)))
+	c.Close()
+}
+
+func TestStreamWriter(t *testing.T) {
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	write := func(byteRange string, stream *Stream, data any) *fasthttp.Response {
+		raw := &fasthttp.RequestCtx{}
+		if byteRange != "" {
+			raw.Request.Header.Set(fasthttp.HeaderRange, byteRange)
+		}
+		c := NewContext(raw)
+		if stream != nil {
+			c.SetStream(stream)
+		}
+		GetWriter(StreamWriterName)(c, data, nil)
+		c.Close()
+		return &raw.Response
+	}
+	newStream := func() *Stream {
+		return &Stream{Filename: "report 2026.txt", Size: 10, ModTime: modTime, Reader: strings.NewReader("0123456789")}
+	}
+
+	resp := write("", newStream(), nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, "0123456789", string(resp.Body()))
+	require.Equal(t, MIME_OCTET, string(resp.Header.ContentType()))
+	require.Equal(t, `attachment; filename="report 2026.txt"`, string(resp.Header.Peek(fasthttp.HeaderContentDisposition)))
+	require.Equal(t, "bytes", string(resp.Header.Peek(fasthttp.HeaderAcceptRanges)))
+
+	resp = write("bytes=2-5", newStream(), nil)
+	require.Equal(t, http.StatusPartialContent, resp.StatusCode())
+	require.Equal(t, "2345", string(resp.Body()))
+	require.Equal(t, "bytes 2-5/10", string(resp.Header.Peek(fasthttp.HeaderContentRange)))
+
+	resp = write("bytes=20-", newStream(), nil)
+	require.Equal(t, http.StatusRequestedRangeNotSatisfiable, resp.StatusCode())
+
+	resp = write("", &Stream{Size: -1, Reader: io.NopCloser(strings.NewReader("chunked"))}, nil)
+	require.Equal(t, "chunked", string(resp.Body()))
+	require.Empty(t, resp.Header.Peek(fasthttp.HeaderAcceptRanges))
+
+	resp = write("", nil, &filepb.File{Filename: "a.txt", ContentType: "text/plain", Content: []byte("file")})
+	require.Equal(t, "file", string(resp.Body()))
+	require.Equal(t, "text/plain", string(resp.Header.ContentType()))
+}
+
+type testStreamService struct {
+	streams []StreamDesc
+}
+
+func (s *testStreamService) RestServiceDesc() *ServiceDesc {
+	return &ServiceDesc{
+		ServiceName: "api.v1.test.Stream",
+		HandlerType: (*Handler)(nil),
+		Streams:     s.streams,
+	}
+}
+
+// serveTestStreams serves the streams with an in memory listener.
+func serveTestStreams(t *testing.T, interceptor server.StreamServerInterceptor, streams ...StreamDesc) *fasthttputil.InmemoryListener {
+	created, err := MustNew(Config{}, &server.ServerOptions{StreamInterceptor: interceptor})
+	require.NoError(t, err)
+	s := created.(*RestServer)
+	require.NoError(t, s.AddHandler(&testStreamService{streams: streams}))
+	ln := fasthttputil.NewInmemoryListener()
+	go (&fasthttp.Server{Handler: s.router.Handler}).Serve(ln)
+	t.Cleanup(func() { ln.Close() })
+	return ln
+}
+
+func TestSSEStream(t *testing.T) {
+	var gotInfo *server.StreamServerInfo
+	ln := serveTestStreams(t, func(srv any, ss server.ServerStream, info *server.StreamServerInfo, handler server.StreamHandler) error {
+		gotInfo = info
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		require.Equal(t, []string{"t1"}, md.Get("x-tenant-id"))
+		return handler(srv, ss)
+	}, StreamDesc{
+		MethodName:    "Watch",
+		Method:        http.MethodGet,
+		Path:          "/watch/{filename}",
+		ServerStreams: true,
+		Handler: func(srv any, stream grpc.ServerStream) error {
+			in := &filepb.File{}
+			if err := stream.RecvMsg(in); err != nil {
+				return err
+			}
+			for i := range 2 {
+				if err := stream.SendMsg(&filepb.File{Filename: in.Filename, Size: int64(i)}); err != nil {
+					return err
+				}
+			}
+			return status.Error(codes.PermissionDenied, "stop")
+		},
+	})
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI("http://test/watch/a.txt")
+	req.Header.Set("X-Tenant-Id", "t1")
+	client := &fasthttp.Client{Dial: func(string) (net.Conn, error) { return ln.Dial() }}
+	require.NoError(t, client.Do(req, resp))
+
+	require.Equal(t, MIME_EVENT_STREAM, string(resp.Header.ContentType()))
+	events := strings.Split(strings.TrimSuffix(string(resp.Body()), "\n\n"), "\n\n")
+	require.Len(t, events, 3)
+	require.True(t, strings.HasPrefix(events[0], "data: "))
+	require.JSONEq(t, `{"filename":"a.txt","content_type":"","size":"0","content":""}`, strings.TrimPrefix(events[0], "data: "))
+	require.True(t, strings.HasPrefix(events[2], "event: error\ndata: "))
+	require.Contains(t, events[2], `"message":"stop"`)
+	require.NotNil(t, gotInfo)
+	require.Equal(t, Protocol, gotInfo.Protocol)
+	require.Equal(t, "/api.v1.test.Stream/Watch", gotInfo.FullMethod)
+	require.True(t, gotInfo.IsServerStream)
+}
+
+func TestSSEStreamRejected(t *testing.T) {
+	called := false
+	ln := serveTestStreams(t, func(srv any, ss server.ServerStream, info *server.StreamServerInfo, handler server.StreamHandler) error {
+		if md, _ := metadata.FromIncomingContext(ss.Context()); len(md.Get("authorization")) == 0 {
+			return status.Error(codes.Unauthenticated, "missing credentials")
+		}
+		return handler(srv, ss)
+	}, StreamDesc{
+		MethodName:    "Watch",
+		Method:        http.MethodGet,
+		Path:          "/watch",
+		ServerStreams: true,
+		Handler: func(srv any, stream grpc.ServerStream) error {
+			called = true
+			return nil
+		},
+	})
+	client := &fasthttp.Client{Dial: func(string) (net.Conn, error) { return ln.Dial() }}
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	// A stream rejected before its first message gets the status of the error.
+	req.SetRequestURI("http://test/watch")
+	require.NoError(t, client.Do(req, resp))
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	require.NotEqual(t, MIME_EVENT_STREAM, string(resp.Header.ContentType()))
+	require.Contains(t, string(resp.Body()), "missing credentials")
+	require.False(t, called)
+
+	// A stream ending without message is an empty event stream.
+	req.Header.Set("Authorization", "Bearer t1")
+	require.NoError(t, client.Do(req, resp))
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, MIME_EVENT_STREAM, string(resp.Header.ContentType()))
+	require.Empty(t, resp.Body())
+	require.True(t, called)
+}
+
+func TestWebSocketStream(t *testing.T) {
+	ln := serveTestStreams(t, nil, StreamDesc{
+		MethodName:    "Chat",
+		Method:        http.MethodGet,
+		Path:          "/chat",
+		ServerStreams: true,
+		ClientStreams: true,
+		Handler: func(srv any, stream grpc.ServerStream) error {
+			for {
+				in := &filepb.File{}
+				if err := stream.RecvMsg(in); err != nil {
+					if err == io.EOF {
+						return nil
+					}
+					return err
+				}
+				if in.Filename == "" {
+					return status.Error(codes.Unauthenticated, "missing filename")
+				}
+				if err := stream.SendMsg(&filepb.File{Filename: in.Filename, Size: int64(len(in.Content))}); err != nil {
+					return err
+				}
+			}
+		},
+	})
+	dialer := &websocket.Dialer{NetDialContext: func(context.Context, string, string) (net.Conn, error) {
+		return ln.Dial()
+	}}
+
+	conn, _, err := dialer.Dial("ws://test/chat", nil)
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"filename":"a.txt","content":"aGk="}`)))
+	_, b, err := conn.ReadMessage()
+	require.NoError(t, err)
+	out := &filepb.File{}
+	require.NoError(t, protojson.Unmarshal(b, out))
+	require.Equal(t, "a.txt", out.Filename)
+	require.Equal(t, int64(2), out.Size)
+	require.NoError(t, conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+	_, _, err = conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+	conn.Close()
+
+	conn, _, err = dialer.Dial("ws://test/chat", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{}`)))
+	_, _, err = conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, WebSocketCloseStatusBase+http.StatusUnauthorized))
+}
+
+func TestContentNegotiation(t *testing.T) {
+	file := &filepb.File{Filename: "a.txt", Size: 3, Content: []byte("abc")}
+	for accept, c := range map[string]struct {
+		contentType string
+		unmarshal   func([]byte, proto.Message) error
+	}{
+		"":                                   {MIME_JSON, protojson.Unmarshal},
+		"*/*":                                {MIME_JSON, protojson.Unmarshal},
+		"text/html,application/xml;q=0.9":    {MIME_XML, unmarshalXML},
+		"text/html,*/*;q=0.8":                {MIME_JSON, protojson.Unmarshal},
+		"application/*":                      {MIME_JSON, protojson.Unmarshal},
+		"application/xml, application/json":  {MIME_XML, unmarshalXML},
+		"application/xml;q=0, application/*": {MIME_JSON, protojson.Unmarshal},
+		"text/plain, application/x-protobuf": {MIME_PROTOBUF, proto.Unmarshal},
+		"application/xml;q=0.9, */*;q=0.8":   {MIME_XML, unmarshalXML},
+		"application/msgpack":                {MIME_MSGPACK, unmarshalMsgpack},
+	} {
+		raw := &fasthttp.RequestCtx{}
+		raw.Request.Header.Set(fasthttp.HeaderAccept, accept)
+		cc := NewContext(raw)
+		DefaultWriter(cc, file, nil)
+		cc.Close()
+		require.Equal(t, c.contentType, string(raw.Response.Header.ContentType()), accept)
+
+		st := &statuspb.Status{}
+		require.NoError(t, c.unmarshal(raw.Response.Body(), st), accept)
+		require.True(t, st.Success, accept)
+		data := &filepb.File{}
+		require.NoError(t, st.Data.UnmarshalTo(data), accept)
+		require.True(t, proto.Equal(file, data), accept)
+	}
+
+	// JSON if no media type with a writer matches.
+	for _, accept := range []string{"text/plain", "application/json;q=0, text/*"} {
+		raw := &fasthttp.RequestCtx{}
+		raw.Request.Header.Set(fasthttp.HeaderAccept, accept)
+		cc := NewContext(raw)
+		DefaultWriter(cc, file, nil)
+		cc.Close()
+		require.Equal(t, http.StatusOK, raw.Response.StatusCode(), accept)
+		require.Equal(t, MIME_JSON, string(raw.Response.Header.ContentType()), accept)
+	}
+
+	// The response written by the handler is kept.
+	raw := &fasthttp.RequestCtx{}
+	raw.Request.Header.Set(fasthttp.HeaderAccept, "text/html")
+	raw.Response.Header.SetContentType("text/csv")
+	raw.Response.SetBodyString("a,b")
+	cc := NewContext(raw)
+	DefaultWriter(cc, nil, nil)
+	cc.Close()
+	require.Equal(t, "text/csv", string(raw.Response.Header.ContentType()))
+	require.Equal(t, "a,b", string(raw.Response.Body()))
+}
+
+func TestXMLMap(t *testing.T) {
+	mq := &mqpb.MQ{Table: map[string]string{"a b": "1", "1x": "<2>", "": "3"}}
+	b, err := marshalXML(mq)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `<entry key="a b">1</entry>`)
+	out := &mqpb.MQ{}
+	require.NoError(t, unmarshalXML(b, out))
+	require.True(t, proto.Equal(mq, out))
+
+	// The map entries of the messages in an Any.
+	data, err := anypb.New(mq)
+	require.NoError(t, err)
+	st := &statuspb.Status{Success: true, Data: data}
+	b, err = marshalXML(st)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `<entry key="1x">&lt;2&gt;</entry>`)
+	outSt := &statuspb.Status{}
+	require.NoError(t, unmarshalXML(b, outSt))
+	require.True(t, outSt.Success)
+	outMQ := &mqpb.MQ{}
+	require.NoError(t, outSt.Data.UnmarshalTo(outMQ))
+	require.True(t, proto.Equal(mq, outMQ))
+}
+
+func TestReadBodyParamsToEntity(t *testing.T) {
+	file := &filepb.File{Filename: "a.txt", ContentType: "text/plain", Size: 3, Content: []byte("abc")}
+	jsonBody, err := protojson.Marshal(file)
+	require.NoError(t, err)
+	protoBody, err := proto.Marshal(file)
+	require.NoError(t, err)
+	xmlBody, err := marshalXML(file)
+	require.NoError(t, err)
+	msgpackBody, err := marshalMsgpack(file)
+	require.NoError(t, err)
+	require.Error(t, unmarshalMsgpack(msgpackBody[:len(msgpackBody)-1], &filepb.File{}))
+
+	for contentType, body := range map[string][]byte{
+		MIME_JSON + "; charset=utf-8":    jsonBody,
+		MIME_PROTOBUF:                    protoBody,
+		MIME_XML:                         xmlBody,
+		MIME_MSGPACK:                     msgpackBody,
+		MIME_POST_FORM:                   []byte("filename=a.txt&content_type=text%2Fplain&size=3&content=YWJj"),
+		MIME_JSON + "; x=":               nil,
+		MIME_XML + "; charset=utf-8 ; x": []byte("<File><filename>a.txt</filename><contentType>text/plain</contentType><size>3</size><content>YWJj</content></File>"),
+	} {
+		raw := &fasthttp.RequestCtx{}
+		raw.Request.Header.SetMethod(http.MethodPost)
+		raw.Request.Header.SetContentType(contentType)
+		if body == nil {
+			raw.Request.SetBodyString("abc")
+		} else {
+			raw.Request.SetBody(body)
+		}
+		cc := NewContext(raw)
+		in := &filepb.File{}
+		err := cc.ReadBodyParamsToEntity(in)
+		cc.Close()
+		if body == nil {
+			require.Equal(t, uint32(http.StatusBadRequest), status.FromError(err).Status, contentType)
+			continue
+		}
+		require.NoError(t, err, contentType)
+		require.True(t, proto.Equal(file, in), contentType)
+	}
+
+	// The bodies without Content-Type or without reader are ignored.
+	for _, contentType := range []string{"", "text/plain", "application/vnd.api+json"} {
+		raw := &fasthttp.RequestCtx{}
+		raw.Request.Header.SetMethod(http.MethodPost)
+		raw.Request.Header.SetContentType(contentType)
+		raw.Request.SetBodyString("abc")
+		cc := NewContext(raw)
+		in := &filepb.File{}
+		require.NoError(t, cc.ReadBodyParamsToEntity(in), contentType)
+		cc.Close()
+		require.True(t, proto.Equal(&filepb.File{}, in), contentType)
+	}
+}
@@ -0,0 +1,327 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// The messages are encoded in MessagePack following their JSON mapping,
+// the JSON numbers are encoded as integers or float64 and the binary values
+// are decoded as the base64 strings of the bytes fields.
+//
+// The MessagePack codecs in Go encode structs and maps, not protobuf messages,
+// so the messages go through their JSON mapping and only the small subset
+// of MessagePack needed by it is implemented here without a new dependency.
+
+// msgpackMaxDepth is the nesting limit of the MessagePack bodies.
+const msgpackMaxDepth = 10000
+
+var errMsgpackShort = errors.New("msgpack: unexpected end of data")
+
+// marshalMsgpack encodes the message in MessagePack.
+func marshalMsgpack(m proto.Message) ([]byte, error) {
+	b, err := jsonMarshalOptions.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := encodeMsgpack(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalMsgpack decodes the MessagePack body into the message.
+func unmarshalMsgpack(b []byte, m proto.Message) error {
+	d := &msgpackDecoder{b: b}
+	value, err := d.decode(0)
+	if err != nil {
+		return err
+	}
+	if d.off != len(d.b) {
+		return errors.New("msgpack: unexpected data after the value")
+	}
+	jb, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return protojson.Unmarshal(jb, m)
+}
+
+// encodeMsgpack encodes a value decoded from JSON.
+func encodeMsgpack(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			encodeMsgpackInt(buf, n)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(0xcb)
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+	case string:
+		encodeMsgpackHeader(buf, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		buf.WriteString(v)
+	case []any:
+		encodeMsgpackHeader(buf, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := encodeMsgpack(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		encodeMsgpackHeader(buf, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			if err := encodeMsgpack(buf, key); err != nil {
+				return err
+			}
+			if err := encodeMsgpack(buf, v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", value)
+	}
+	return nil
+}
+
+// encodeMsgpackInt encodes an integer in its smallest format.
+func encodeMsgpackInt(buf *bytes.Buffer, n int64) {
+	switch {
+	case n >= 0 && n <= math.MaxInt8:
+		buf.WriteByte(byte(n))
+	case n < 0 && n >= -32:
+		buf.WriteByte(byte(n))
+	case n >= math.MinInt8 && n <= math.MaxInt8:
+		buf.Write([]byte{0xd0, byte(n)})
+	case n >= math.MinInt16 && n <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n >= math.MinInt32 && n <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		buf.WriteByte(0xd3)
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(n)))
+	}
+}
+
+// encodeMsgpackHeader encodes the length of a string, an array or a map.
+// fix is the format of the lengths under fixMax, format8 is 0 if there is no 8 bits format.
+func encodeMsgpackHeader(buf *bytes.Buffer, n int, fix byte, fixMax int, format8, format16, format32 byte) {
+	switch {
+	case n < fixMax:
+		buf.WriteByte(fix | byte(n))
+	case format8 != 0 && n <= math.MaxUint8:
+		buf.Write([]byte{format8, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(format16)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(format32)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+// msgpackDecoder decodes MessagePack into values which can be encoded in JSON.
+type msgpackDecoder struct {
+	b   []byte
+	off int
+}
+
+func (d *msgpackDecoder) decode(depth int) (any, error) {
+	if depth > msgpackMaxDepth {
+		return nil, errors.New("msgpack: exceeds the nesting limit")
+	}
+	c, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	format := c[0]
+	switch {
+	case format <= 0x7f:
+		return int64(format), nil
+	case format >= 0xe0:
+		return int64(int8(format)), nil
+	case format&0xf0 == 0x80:
+		return d.decodeMap(int(format&0x0f), depth)
+	case format&0xf0 == 0x90:
+		return d.decodeArray(int(format&0x0f), depth)
+	case format&0xe0 == 0xa0:
+		return d.decodeString(int(format & 0x1f))
+	}
+	switch format {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readLength(format - 0xc4)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	case 0xca:
+		b, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 0xcb:
+		b, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		b, err := d.read(1 << (format - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpackUint(b), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		b, err := d.read(1 << (format - 0xd0))
+		if err != nil {
+			return nil, err
+		}
+		n := decodeMsgpackUint(b)
+		shift := 64 - 8*len(b)
+		return int64(n<<shift) >> shift, nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readLength(format - 0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(n)
+	case 0xdc, 0xdd:
+		n, err := d.readLength(format - 0xdc + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.readLength(format - 0xde + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(n, depth)
+	}
+	return nil, fmt.Errorf("msgpack: unsupported format 0x%x", format)
+}
+
+// read returns the next n bytes.
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || n > len(d.b)-d.off {
+		return nil, errMsgpackShort
+	}
+	b := d.b[d.off : d.off+n]
+	d.off += n
+	return b, nil
+}
+
+// readLength reads a length of 1, 2 or 4 bytes for the size 0, 1 or 2.
+func (d *msgpackDecoder) readLength(size byte) (int, error) {
+	b, err := d.read(1 << size)
+	if err != nil {
+		return 0, err
+	}
+	return int(decodeMsgpackUint(b)), nil
+}
+
+func (d *msgpackDecoder) decodeString(n int) (any, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) decodeArray(n int, depth int) (any, error) {
+	// Every item is at least one byte.
+	if n > len(d.b)-d.off {
+		return nil, errMsgpackShort
+	}
+	out := make([]any, 0, n)
+	for range n {
+		item, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n int, depth int) (any, error) {
+	// Every entry is at least two bytes.
+	if n > (len(d.b)-d.off)/2 {
+		return nil, errMsgpackShort
+	}
+	out := make(map[string]any, n)
+	for range n {
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		switch k := key.(type) {
+		case string:
+			out[k] = value
+		case nil, []any, map[string]any:
+			return nil, fmt.Errorf("msgpack: unsupported map key %T", key)
+		default:
+			// The JSON keys of the integer and bool map fields.
+			out[fmt.Sprint(k)] = value
+		}
+	}
+	return out, nil
+}
+
+// decodeMsgpackUint decodes a big endian unsigned integer of 1, 2, 4 or 8 bytes.
+func decodeMsgpackUint(b []byte) uint64 {
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n
+}
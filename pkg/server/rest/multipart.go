@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"io"
+	"mime/multipart"
+
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/pkg/protobuf/filepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// fileMessageName is the full name of the well-known asjard.api.File message.
+var fileMessageName = (&filepb.File{}).ProtoReflect().Descriptor().FullName()
+
+// ReadMultipartParamsToEntity parses a multipart/form-data body into the Protobuf struct.
+// The values are mapped like the query params, the files are mapped into
+// the bytes and asjard.api.File fields named after their part.
+// Files larger than the upload size limit of the method are rejected.
+func (c *Context) ReadMultipartParamsToEntity(entity proto.Message) error {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "read multipart form fail: %v", err)
+	}
+	if err := protoForm(entity, form.Value); err != nil {
+		return status.Errorf(codes.InvalidArgument, "read multipart params to entity fail: %v", err)
+	}
+	msg := entity.ProtoReflect()
+	fields := msg.Descriptor().Fields()
+	for name, files := range form.File {
+		field := fields.ByTextName(name)
+		if field == nil || len(files) == 0 {
+			continue
+		}
+		isFile := field.Kind() == protoreflect.MessageKind && field.Message().FullName() == fileMessageName
+		if !isFile && field.Kind() != protoreflect.BytesKind {
+			continue
+		}
+		if !field.IsList() {
+			files = files[:1]
+		}
+		for _, fh := range files {
+			value, err := c.readMultipartFile(fh, isFile)
+			if err != nil {
+				return err
+			}
+			if field.IsList() {
+				msg.Mutable(field).List().Append(value)
+			} else {
+				msg.Set(field, value)
+			}
+		}
+	}
+	return nil
+}
+
+// readMultipartFile reads a file part as bytes or as an asjard.api.File message.
+func (c *Context) readMultipartFile(fh *multipart.FileHeader, isFile bool) (protoreflect.Value, error) {
+	if c.maxUploadSize > 0 && fh.Size > c.maxUploadSize {
+		return protoreflect.Value{}, status.Errorf(codes.InvalidArgument, "file %s exceeds the upload size limit of %d bytes", fh.Filename, c.maxUploadSize)
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return protoreflect.Value{}, status.Errorf(codes.InvalidArgument, "open multipart file %s fail: %v", fh.Filename, err)
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return protoreflect.Value{}, status.Errorf(codes.InvalidArgument, "read multipart file %s fail: %v", fh.Filename, err)
+	}
+	if !isFile {
+		return protoreflect.ValueOfBytes(content), nil
+	}
+	return protoreflect.ValueOfMessage((&filepb.File{
+		Filename:    fh.Filename,
+		ContentType: fh.Header.Get("Content-Type"),
+		Size:        fh.Size,
+		Content:     content,
+	}).ProtoReflect()), nil
+}
@@ -25,3 +25,13 @@ func WithErrPage(errPage string) func(ctx *Context) {
 		}
 	}
 }
+
+// WithMaxUploadSize sets the size limit of the files of a multipart/form-data request.
+// Larger files are rejected when the request is read into its Protobuf struct.
+func WithMaxUploadSize(size int64) func(ctx *Context) {
+	return func(ctx *Context) {
+		if size > 0 {
+			ctx.maxUploadSize = size
+		}
+	}
+}
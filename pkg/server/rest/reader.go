@@ -0,0 +1,102 @@
+package rest
+
+import (
+	"errors"
+	"mime"
+	"sync"
+
+	"github.com/asjard/asjard/core/status"
+	"github.com/asjard/asjard/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Reader defines the function signature for reading a request body
+// of a media type into the Protobuf message.
+type Reader func(ctx *Context, entity proto.Message) error
+
+var (
+	// Registry of the request bodies by media type.
+	readers = map[string]Reader{}
+	rm      sync.RWMutex
+)
+
+func init() {
+	AddReader(MIME_JSON, ReadJSONBody)
+	AddReader(MIME_PROTOBUF, ReadProtobufBody)
+	AddReader(MIME_XML, ReadXMLBody)
+	AddReader(MIME_MSGPACK, ReadMsgpackBody)
+	AddReader(MIME_X_MSGPACK, ReadMsgpackBody)
+	AddReader(MIME_POST_FORM, ReadPostFormBody)
+	AddReader(MIME_MULTIPART_FORM, (*Context).ReadMultipartParamsToEntity)
+}
+
+// AddReader registers the reader of the request bodies of a media type, e.g. application/xml.
+func AddReader(contentType string, reader Reader) {
+	rm.Lock()
+	readers[contentType] = reader
+	rm.Unlock()
+}
+
+// GetReader retrieves the reader of a Content-Type, the parameters such as charset are ignored.
+func GetReader(contentType string) (Reader, bool) {
+	// The media type is returned with the invalid parameters error.
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil && !errors.Is(err, mime.ErrInvalidMediaParameter) {
+		return nil, false
+	}
+	rm.RLock()
+	defer rm.RUnlock()
+	reader, ok := readers[mediaType]
+	return reader, ok
+}
+
+// ReadJSONBody unmarshals the JSON body into the Protobuf struct.
+func ReadJSONBody(c *Context, entity proto.Message) error {
+	if err := protojson.Unmarshal(c.Request.Body(), entity); err != nil {
+		return status.Errorf(codes.InvalidArgument, "read body params to entity fail: %v", err)
+	}
+	return nil
+}
+
+// ReadProtobufBody unmarshals the binary Protobuf body into the Protobuf struct.
+func ReadProtobufBody(c *Context, entity proto.Message) error {
+	if err := proto.Unmarshal(c.Request.Body(), entity); err != nil {
+		return status.Errorf(codes.InvalidArgument, "read protobuf body to entity fail: %v", err)
+	}
+	return nil
+}
+
+// ReadXMLBody unmarshals the XML body into the Protobuf struct,
+// the elements are mapped like the fields of the JSON body.
+func ReadXMLBody(c *Context, entity proto.Message) error {
+	if err := unmarshalXML(c.Request.Body(), entity); err != nil {
+		return status.Errorf(codes.InvalidArgument, "read xml body to entity fail: %v", err)
+	}
+	return nil
+}
+
+// ReadMsgpackBody unmarshals the MessagePack body into the Protobuf struct,
+// the values are mapped like the fields of the JSON body.
+func ReadMsgpackBody(c *Context, entity proto.Message) error {
+	if err := unmarshalMsgpack(c.Request.Body(), entity); err != nil {
+		return status.Errorf(codes.InvalidArgument, "read msgpack body to entity fail: %v", err)
+	}
+	return nil
+}
+
+// ReadPostFormBody parses the application/x-www-form-urlencoded body into the Protobuf struct,
+// the values are mapped like the query params.
+func ReadPostFormBody(c *Context, entity proto.Message) error {
+	form := make(map[string][]string)
+	c.PostArgs().All()(func(key, value []byte) bool {
+		k := utils.SafeByte2String(key)
+		form[k] = append(form[k], utils.SafeByte2String(value))
+		return true
+	})
+	if err := protoForm(entity, form); err != nil {
+		return status.Errorf(codes.InvalidArgument, "read form body to entity fail: %v", err)
+	}
+	return nil
+}
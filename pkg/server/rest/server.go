@@ -15,6 +15,7 @@ import (
 	"github.com/asjard/asjard/core/logger"
 	"github.com/asjard/asjard/core/runtime"
 	"github.com/asjard/asjard/core/server"
+	"github.com/asjard/asjard/pkg/protobuf/authzpb"
 	"github.com/asjard/asjard/utils"
 	"github.com/fasthttp/router"
 	openapi_v3 "github.com/google/gnostic/openapiv3"
@@ -37,14 +38,15 @@ type MiddlewareFunc func(next fasthttp.RequestHandler) fasthttp.RequestHandler
 
 // RestServer manages the fasthttp instance, routing, and server lifecycle.
 type RestServer struct {
-	router       *router.Router                // Handles request multiplexing.
-	server       fasthttp.Server               // The high-performance HTTP engine.
-	openapi      *openapi_v3.Document          // Aggregated API documentation.
-	interceptor  server.UnaryServerInterceptor // Global interceptor for processing logic.
-	conf         Config                        // Server-specific configurations.
-	middlewares  []MiddlewareFunc              // Chain of global middlewares.
-	errorHandler *ErrorHandlerAPI              // Standardized error response handler.
-	handlers     []Handler                     // List of registered service handlers.
+	router            *router.Router                 // Handles request multiplexing.
+	server            fasthttp.Server                // The high-performance HTTP engine.
+	openapi           *openapi_v3.Document           // Aggregated API documentation.
+	interceptor       server.UnaryServerInterceptor  // Global interceptor for processing logic.
+	streamInterceptor server.StreamServerInterceptor // Global interceptor for the streaming endpoints.
+	conf              Config                         // Server-specific configurations.
+	middlewares       []MiddlewareFunc               // Chain of global middlewares.
+	errorHandler      *ErrorHandlerAPI               // Standardized error response handler.
+	handlers          []Handler                      // List of registered service handlers.
 }
 
 // Ensure RestServer satisfies the core server interface.
@@ -82,12 +84,13 @@ func MustNew(conf Config, options *server.ServerOptions) (server.Server, error)
 	r.GlobalOPTIONS = corsMiddleware(func(ctx *fasthttp.RequestCtx) {})
 
 	return &RestServer{
-		router:       r,
-		openapi:      &openapi_v3.Document{},
-		interceptor:  options.Interceptor,
-		conf:         conf,
-		middlewares:  []MiddlewareFunc{corsMiddleware},
-		errorHandler: &ErrorHandlerAPI{},
+		router:            r,
+		openapi:           &openapi_v3.Document{},
+		interceptor:       options.Interceptor,
+		streamInterceptor: options.StreamInterceptor,
+		conf:              conf,
+		middlewares:       []MiddlewareFunc{corsMiddleware},
+		errorHandler:      &ErrorHandlerAPI{},
 		server: fasthttp.Server{
 			// Extensive performance tuning parameters mapped from configuration.
 			Name:                               runtime.GetAPP().App,
@@ -230,22 +233,42 @@ func (s *RestServer) addRouter(handler Handler) error {
 		if method.Method != "" && method.Path != "" && method.Handler != nil {
 			s.addRouterHandler(method.Method, method, handler, method.WriterName)
 		}
+		if method.Authz != nil {
+			authzpb.AddMethodAuthz("/"+desc.ServiceName+"/"+method.MethodName, method.Authz)
+		}
+	}
+	// Register each streaming method path to the router.
+	for _, stream := range desc.Streams {
+		fullMethod := "/" + desc.ServiceName + "/" + stream.MethodName
+		if stream.Method != "" && stream.Path != "" && stream.Handler != nil {
+			s.router.Handle(stream.Method, stream.Path,
+				s.applyMiddleware(s.newStreamHandler(fullMethod, stream, handler), s.middlewares...))
+		}
+		if stream.Authz != nil {
+			authzpb.AddMethodAuthz(fullMethod, stream.Authz)
+		}
 	}
 	return nil
 }
 
 // addRouterHandler applies middleware and registers a handler to a specific route.
 func (s *RestServer) addRouterHandler(method string, methodDesc MethodDesc, svc Handler, writerName string) {
+	maxUploadSize := methodDesc.MaxUploadSize
+	if maxUploadSize == 0 {
+		maxUploadSize = s.conf.Upload.MaxSize
+	}
 	s.router.Handle(method, methodDesc.Path,
-		s.applyMiddleware(s.newHandler(methodDesc.Handler, svc, writerName),
+		s.applyMiddleware(s.newHandler(methodDesc.Handler, svc, writerName, maxUploadSize),
 			s.middlewares...))
 }
 
 // newHandler wraps the business logic in a REST context and response writer.
-func (s *RestServer) newHandler(methodHandler methodHandler, svc Handler, writerName string) fasthttp.RequestHandler {
+func (s *RestServer) newHandler(methodHandler methodHandler, svc Handler, writerName string, maxUploadSize int64) fasthttp.RequestHandler {
 	writer := GetWriter(writerName)
 	return func(ctx *fasthttp.RequestCtx) {
-		cc := NewContext(ctx, WithErrPage(s.conf.Doc.ErrPage), WithWriter(writer))
+		cc := NewContext(ctx, WithErrPage(s.conf.Doc.ErrPage), WithWriter(writer), WithMaxUploadSize(maxUploadSize))
+		cancel := cc.withRequestTimeout()
+		defer cancel()
 		reply, err := methodHandler(cc, svc, s.interceptor)
 		cc.WriteData(reply, err)
 	}
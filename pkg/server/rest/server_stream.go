@@ -0,0 +1,137 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/runtime"
+	"github.com/asjard/asjard/core/server"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// newStreamHandler serves a streaming method as Server-Sent Events,
+// or over WebSocket if the client sends a stream of messages.
+func (s *RestServer) newStreamHandler(fullMethod string, desc StreamDesc, svc Handler) fasthttp.RequestHandler {
+	info := &server.StreamServerInfo{
+		Server:         svc,
+		FullMethod:     fullMethod,
+		Protocol:       Protocol,
+		IsClientStream: desc.ClientStreams,
+		IsServerStream: desc.ServerStreams,
+	}
+	if desc.ClientStreams {
+		return s.newWebSocketHandler(info, desc.Handler)
+	}
+	return s.newSSEHandler(info, desc.Handler)
+}
+
+// serveStream executes the stream handler through the stream interceptors.
+func (s *RestServer) serveStream(ss grpc.ServerStream, info *server.StreamServerInfo, handler grpc.StreamHandler) error {
+	if s.streamInterceptor == nil {
+		return handler(info.Server, ss)
+	}
+	return s.streamInterceptor(info.Server, ss, info, func(srv any, stream server.ServerStream) error {
+		return handler(srv, &serverStream{ServerStream: ss, stream: stream})
+	})
+}
+
+// serverStream adapts a framework ServerStream, which may have been wrapped
+// by interceptors, back to a grpc.ServerStream for the generated handlers.
+type serverStream struct {
+	grpc.ServerStream
+	stream server.ServerStream
+}
+
+// Context returns the context of the (possibly wrapped) framework stream.
+func (s *serverStream) Context() context.Context {
+	return s.stream.Context()
+}
+
+// SendMsg sends the message through the (possibly wrapped) framework stream.
+func (s *serverStream) SendMsg(m any) error {
+	return s.stream.SendMsg(m)
+}
+
+// RecvMsg receives the message through the (possibly wrapped) framework stream.
+func (s *serverStream) RecvMsg(m any) error {
+	return s.stream.RecvMsg(m)
+}
+
+// restStream holds the request of a streaming endpoint.
+// The fasthttp.RequestCtx can not be used once the stream is running,
+// the stream reads a copy of the request and its context carries
+// the request headers as incoming metadata, like a gRPC stream.
+type restStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	// request is the copy of the request, with its path params.
+	request *Context
+}
+
+// newRestStream copies the request and creates the context of the stream,
+// canceled when the stream ends, the request times out or the service exits once the stream is started.
+func newRestStream(ctx *fasthttp.RequestCtx) *restStream {
+	rc := &fasthttp.RequestCtx{}
+	rc.Init(&ctx.Request, ctx.RemoteAddr(), nil)
+	ctx.VisitUserValues(func(key []byte, value any) {
+		rc.SetUserValueBytes(key, value)
+	})
+	request := &Context{RequestCtx: rc}
+
+	md := metadata.MD{}
+	for key, values := range request.ReadHeaderParams() {
+		md.Append(key, values...)
+	}
+	streamCtx := peer.NewContext(metadata.NewIncomingContext(context.Background(), md), &peer.Peer{Addr: ctx.RemoteAddr()})
+	var cancel context.CancelFunc
+	if value := rc.Request.Header.Peek(HeaderRequestTimeout); len(value) != 0 {
+		if timeout, err := ParseRequestTimeout(string(value)); err == nil {
+			streamCtx, cancel = context.WithTimeout(streamCtx, timeout)
+		} else {
+			logger.L(ctx).Warn("invalid request timeout", "timeout", string(value), "err", err)
+		}
+	}
+	if cancel == nil {
+		streamCtx, cancel = context.WithCancel(streamCtx)
+	}
+	return &restStream{
+		ctx:     streamCtx,
+		cancel:  cancel,
+		request: request,
+	}
+}
+
+// serve runs fn, the handler of the stream, and cancels the stream once it returns.
+// The stream is canceled meanwhile if the service exits.
+func (s *restStream) serve(fn func() error) error {
+	defer s.cancel()
+	go func() {
+		select {
+		case <-runtime.Exit:
+			s.cancel()
+		case <-s.ctx.Done():
+		}
+	}()
+	return fn()
+}
+
+// Context returns the context of the stream.
+func (s *restStream) Context() context.Context {
+	return s.ctx
+}
+
+// SetHeader is a no-op, the response headers are sent when the stream starts.
+func (s *restStream) SetHeader(metadata.MD) error {
+	return nil
+}
+
+// SendHeader is a no-op, the response headers are sent when the stream starts.
+func (s *restStream) SendHeader(metadata.MD) error {
+	return nil
+}
+
+// SetTrailer is a no-op, there is no trailer over Server-Sent Events and WebSocket.
+func (s *restStream) SetTrailer(metadata.MD) {}
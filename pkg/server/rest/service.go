@@ -2,6 +2,8 @@ package rest
 
 import (
 	"github.com/asjard/asjard/core/server"
+	"github.com/asjard/asjard/pkg/protobuf/authzpb"
+	"google.golang.org/grpc"
 )
 
 // methodHandler defines the standard signature for a REST request handler.
@@ -20,6 +22,7 @@ type ServiceDesc struct {
 	Writer      Writer       // Custom response writer (e.g., for specialized XML or binary outputs).
 	OpenAPI     []byte       // Marshaled OpenAPI v3 specification data for this service.
 	Methods     []MethodDesc // The list of individual endpoints (methods) within this service.
+	Streams     []StreamDesc // The streaming endpoints, served over Server-Sent Events or WebSocket.
 }
 
 // MethodDesc represents the detailed specification for a single RPC method.
@@ -39,4 +42,33 @@ type MethodDesc struct {
 	Desc string
 	// WriterName specifies a registered response writer to use (e.g., "json", "proto").
 	WriterName string
+	// MaxUploadSize is the size limit in bytes of the files uploaded with multipart/form-data,
+	// the server's upload.maxSize is used if it is 0.
+	MaxUploadSize int64
+	// Authz is the authorization option (asjard.api.authz) of the method, checked by the authz interceptor.
+	Authz *authzpb.Authz
+}
+
+// StreamDesc represents the specification of a streaming RPC method.
+// Server streaming methods are served as Server-Sent Events,
+// client and bidirectional streaming methods over WebSocket.
+type StreamDesc struct {
+	// MethodName is the internal name of the function (e.g., "Watch").
+	MethodName string
+	// Method is the HTTP Verb, WebSocket endpoints only accept GET.
+	Method string
+	// Path is the URL pattern for the endpoint (e.g., "/api/v1/user/watch").
+	Path string
+	// Handler is the gRPC stream handler of the method.
+	Handler grpc.StreamHandler
+	// Name is a human-friendly name for this specific endpoint.
+	Name string
+	// Desc provides a detailed description of what this endpoint does.
+	Desc string
+	// ServerStreams indicates whether the server sends a stream of messages.
+	ServerStreams bool
+	// ClientStreams indicates whether the client sends a stream of messages.
+	ClientStreams bool
+	// Authz is the authorization option (asjard.api.authz) of the method, checked by the authz interceptor.
+	Authz *authzpb.Authz
 }
@@ -0,0 +1,148 @@
+package rest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/server"
+	"github.com/asjard/asjard/core/status"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// MIME_EVENT_STREAM is the content type of Server-Sent Events.
+	MIME_EVENT_STREAM = "text/event-stream"
+
+	// SSEErrorEvent is the event sent with the status of the error ending a stream.
+	SSEErrorEvent = "error"
+)
+
+// newSSEHandler serves a server streaming method as Server-Sent Events.
+// The request is read like the unary methods, each message sent by the
+// server is a data event in JSON, an error ends the stream with an error event.
+// The response is committed by the first message, a stream failing before it,
+// e.g., rejected by the auth interceptor, is answered like a unary method with the HTTP status of its error.
+func (s *RestServer) newSSEHandler(info *server.StreamServerInfo, handler grpc.StreamHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		stream := &sseStream{
+			restStream: newRestStream(ctx),
+			started:    make(chan struct{}),
+			writers:    make(chan *bufio.Writer),
+			done:       make(chan error, 1),
+		}
+		go func() {
+			stream.done <- stream.serve(func() error {
+				return s.serveStream(stream, info, handler)
+			})
+		}()
+
+		var (
+			err      error
+			finished bool
+		)
+		select {
+		case err = <-stream.done:
+			finished = true
+			if err != nil {
+				logger.L(stream.ctx).Debug("sse stream fail", "full_method", info.FullMethod, "err", err)
+				NewContext(ctx, WithErrPage(s.conf.Doc.ErrPage)).WriteData(nil, err)
+				// EventSource clients only see the status code of a failed request,
+				// it is set even if the nsc query param is missing.
+				ctx.SetStatusCode(int(status.FromError(err).Status))
+				return
+			}
+		case <-stream.started:
+		}
+
+		ctx.SetContentType(MIME_EVENT_STREAM)
+		ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "no-cache")
+		// Disable the response buffering of nginx.
+		ctx.Response.Header.Set("X-Accel-Buffering", "no")
+		if finished {
+			return
+		}
+		ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+			// Hand the writer to the first message, and wait for the end of the stream.
+			stream.writers <- w
+			if err := <-stream.done; err != nil {
+				logger.L(stream.ctx).Debug("sse stream fail", "full_method", info.FullMethod, "err", err)
+				b, err := jsonMarshalOptions.Marshal(status.FromError(err))
+				if err != nil {
+					logger.L(stream.ctx).Error("marshal sse error fail", "err", err)
+					return
+				}
+				stream.writeEvent(SSEErrorEvent, b)
+			}
+		})
+	}
+}
+
+// sseStream sends the messages of the server as Server-Sent Events.
+type sseStream struct {
+	*restStream
+	// started is closed by the first message, which waits for the writer of the response from writers.
+	started chan struct{}
+	writers chan *bufio.Writer
+	// done receives the result of the stream handler.
+	done     chan error
+	w        *bufio.Writer
+	received bool
+}
+
+// SendMsg sends the message as a data event.
+// The stream is canceled if the client is gone.
+func (s *sseStream) SendMsg(m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("message %T is not a proto.Message", m)
+	}
+	b, err := jsonMarshalOptions.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if s.w == nil {
+		close(s.started)
+		s.w = <-s.writers
+	}
+	if err := s.writeEvent("", b); err != nil {
+		s.cancel()
+		return err
+	}
+	return nil
+}
+
+// RecvMsg reads the request into the message once, like the unary methods.
+func (s *sseStream) RecvMsg(m any) error {
+	if s.received {
+		return io.EOF
+	}
+	s.received = true
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("message %T is not a proto.Message", m)
+	}
+	return s.request.ReadEntity(msg)
+}
+
+// writeEvent writes and flushes an event.
+func (s *sseStream) writeEvent(event string, data []byte) error {
+	if event != "" {
+		if _, err := s.w.WriteString("event: " + event + "\n"); err != nil {
+			return err
+		}
+	}
+	if _, err := s.w.WriteString("data: "); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(data); err != nil {
+		return err
+	}
+	if _, err := s.w.WriteString("\n\n"); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
@@ -0,0 +1,163 @@
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/pkg/protobuf/filepb"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// StreamWriterName is the identifier for the streaming download handler.
+	StreamWriterName = "stream"
+)
+
+// Stream is a response body streamed to the client by the stream writer.
+type Stream struct {
+	// Filename is sent in the Content-Disposition header if it is not empty.
+	Filename string
+	// Inline lets the browser display the body instead of downloading it.
+	Inline bool
+	// ContentType defaults to application/octet-stream.
+	ContentType string
+	// Size is the size of the body, -1 if it is unknown,
+	// the body is then sent with the chunked transfer encoding.
+	Size int64
+	// ModTime is sent in the Last-Modified header if it is not zero.
+	ModTime time.Time
+	// Reader is the body, it is read as fast as the client receives it
+	// and closed once sent if it is an io.Closer.
+	// Range requests are served if it is an io.ReadSeeker of a known Size.
+	Reader io.Reader
+}
+
+type streamKey struct{}
+
+func init() {
+	AddWriter(StreamWriterName, StreamWriter)
+}
+
+// SetStream sets the body sent by the stream writer, the handler then returns a nil reply.
+func (c *Context) SetStream(stream *Stream) {
+	c.SetUserValue(streamKey{}, stream)
+}
+
+// StreamWriter sends the stream set by the handler with SetStream,
+// or the content of an asjard.api.File reply.
+// Errors and the other replies are written by the DefaultWriter.
+func StreamWriter(c *Context, data any, err error) {
+	stream, _ := c.UserValue(streamKey{}).(*Stream)
+	if err != nil {
+		if stream != nil {
+			closeStream(stream)
+		}
+		DefaultWriter(c, data, err)
+		return
+	}
+	if stream == nil {
+		file, ok := data.(*filepb.File)
+		if !ok || file == nil {
+			DefaultWriter(c, data, err)
+			return
+		}
+		stream = &Stream{
+			Filename:    file.Filename,
+			ContentType: file.ContentType,
+			Size:        int64(len(file.Content)),
+			Reader:      bytes.NewReader(file.Content),
+		}
+	}
+	writeStream(c, stream)
+}
+
+// writeStream sets the headers and the body stream of the response.
+func writeStream(c *Context, stream *Stream) {
+	header := &c.Response.Header
+	if requestId, ok := c.Value(HeaderResponseRequestID).(string); ok {
+		header.Set(HeaderResponseRequestID, requestId)
+	}
+	if requestMethod, ok := c.Value(HeaderResponseRequestMethod).(string); ok {
+		header.Set(HeaderResponseRequestMethod, requestMethod)
+	}
+	contentType := stream.ContentType
+	if contentType == "" {
+		contentType = MIME_OCTET
+	}
+	header.SetContentType(contentType)
+	if stream.Filename != "" {
+		disposition := "attachment"
+		if stream.Inline {
+			disposition = "inline"
+		}
+		header.Set(fasthttp.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": stream.Filename}))
+	}
+	if !stream.ModTime.IsZero() {
+		header.Set(fasthttp.HeaderLastModified, stream.ModTime.UTC().Format(http.TimeFormat))
+	}
+
+	seeker, ok := stream.Reader.(io.ReadSeeker)
+	if !ok || stream.Size < 0 {
+		c.SetBodyStream(stream.Reader, -1)
+		return
+	}
+	header.Set(fasthttp.HeaderAcceptRanges, "bytes")
+	byteRange := c.Request.Header.Peek(fasthttp.HeaderRange)
+	// Multiple ranges are not supported, the whole body is sent instead.
+	if len(byteRange) == 0 || bytes.IndexByte(byteRange, ',') >= 0 || !matchIfRange(c, stream) {
+		c.SetBodyStream(stream.Reader, int(stream.Size))
+		return
+	}
+	start, end, err := fasthttp.ParseByteRange(byteRange, int(stream.Size))
+	if err != nil {
+		closeStream(stream)
+		header.Set(fasthttp.HeaderContentRange, fmt.Sprintf("bytes */%d", stream.Size))
+		c.SetStatusCode(http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	if _, err := seeker.Seek(int64(start), io.SeekStart); err != nil {
+		logger.L(c).Error("seek stream fail", "filename", stream.Filename, "err", err)
+		closeStream(stream)
+		c.SetStatusCode(http.StatusInternalServerError)
+		return
+	}
+	header.Set(fasthttp.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, stream.Size))
+	c.SetStatusCode(http.StatusPartialContent)
+	c.SetBodyStream(&rangeReader{
+		Reader: io.LimitReader(seeker, int64(end-start+1)),
+		stream: stream.Reader,
+	}, end-start+1)
+}
+
+// matchIfRange reports whether the range is served, the If-Range date must match the modification time.
+func matchIfRange(c *Context, stream *Stream) bool {
+	ifRange := c.Request.Header.Peek(fasthttp.HeaderIfRange)
+	if len(ifRange) == 0 {
+		return true
+	}
+	return !stream.ModTime.IsZero() && string(ifRange) == stream.ModTime.UTC().Format(http.TimeFormat)
+}
+
+func closeStream(stream *Stream) {
+	if closer, ok := stream.Reader.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// rangeReader reads a range of a stream and closes the stream.
+type rangeReader struct {
+	io.Reader
+	stream io.Reader
+}
+
+func (r *rangeReader) Close() error {
+	if closer, ok := r.stream.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/core/server"
+	"github.com/asjard/asjard/core/status"
+	"github.com/fasthttp/websocket"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// WebSocketCloseStatusBase is added to the HTTP status of the error ending a stream
+	// to get the code of the close frame, e.g. 4401 if the client is unauthenticated.
+	WebSocketCloseStatusBase = 4000
+
+	// maxCloseReasonSize is the size limit of the reason of a close frame.
+	maxCloseReasonSize = 123
+	closeWriteTimeout  = time.Second
+)
+
+// newWebSocketHandler serves a client or bidirectional streaming method over WebSocket.
+// Each message is a JSON text frame, the stream ends with a close frame
+// whose code is WebSocketCloseStatusBase plus the HTTP status of the error, if any.
+func (s *RestServer) newWebSocketHandler(info *server.StreamServerInfo, handler grpc.StreamHandler) fasthttp.RequestHandler {
+	upgrader := websocket.FastHTTPUpgrader{
+		ReadBufferSize:  s.conf.Options.ReadBufferSize,
+		WriteBufferSize: s.conf.Options.WriteBufferSize,
+		// The origins are checked by the cors middleware.
+		CheckOrigin: func(*fasthttp.RequestCtx) bool { return true },
+	}
+	return func(ctx *fasthttp.RequestCtx) {
+		rs := newRestStream(ctx)
+		if err := upgrader.Upgrade(ctx, func(conn *websocket.Conn) {
+			defer conn.Close()
+			if s.conf.Options.MaxRequestBodySize > 0 {
+				conn.SetReadLimit(int64(s.conf.Options.MaxRequestBodySize))
+			}
+			err := rs.serve(func() error {
+				return s.serveStream(&webSocketStream{restStream: rs, conn: conn}, info, handler)
+			})
+			if err != nil {
+				logger.L(rs.ctx).Debug("websocket stream fail", "full_method", info.FullMethod, "err", err)
+			}
+			conn.WriteControl(websocket.CloseMessage, closeMessage(err), time.Now().Add(closeWriteTimeout))
+		}); err != nil {
+			rs.cancel()
+			logger.L(ctx).Debug("websocket upgrade fail", "full_method", info.FullMethod, "err", err)
+		}
+	}
+}
+
+// closeMessage returns the close frame ending a stream with the error.
+func closeMessage(err error) []byte {
+	if err == nil {
+		return websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	}
+	st := status.FromError(err)
+	code := websocket.CloseInternalServerErr
+	if st.Status >= http.StatusContinue && st.Status < 1000 {
+		code = WebSocketCloseStatusBase + int(st.Status)
+	}
+	reason := st.Message
+	if len(reason) > maxCloseReasonSize {
+		reason = reason[:maxCloseReasonSize]
+	}
+	return websocket.FormatCloseMessage(code, reason)
+}
+
+// webSocketStream exchanges the messages as JSON text frames.
+type webSocketStream struct {
+	*restStream
+	conn *websocket.Conn
+}
+
+// SendMsg sends the message as a text frame.
+// The stream is canceled if the client is gone.
+func (s *webSocketStream) SendMsg(m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("message %T is not a proto.Message", m)
+	}
+	b, err := jsonMarshalOptions.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if err := s.conn.WriteMessage(websocket.TextMessage, b); err != nil {
+		s.cancel()
+		return err
+	}
+	return nil
+}
+
+// RecvMsg reads a frame into the message.
+// It returns io.EOF once the client closed the stream.
+func (s *webSocketStream) RecvMsg(m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("message %T is not a proto.Message", m)
+	}
+	_, b, err := s.conn.ReadMessage()
+	if err != nil {
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+			return io.EOF
+		}
+		s.cancel()
+		return err
+	}
+	if err := protojson.Unmarshal(b, msg); err != nil {
+		return status.Errorf(codes.InvalidArgument, "read websocket message to entity fail: %v", err)
+	}
+	return nil
+}
@@ -1,8 +1,12 @@
 package rest
 
 import (
+	"mime"
 	"net/http"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/asjard/asjard/core/logger"
@@ -29,15 +33,38 @@ const (
 // It takes the request context, the successful data, and any potential error.
 type Writer func(ctx *Context, data any, err error)
 
+// Marshaler encodes a message in the wire format of a media type.
+type Marshaler func(m proto.Message) ([]byte, error)
+
 var (
-	// Registry for different output formats.
-	writers = map[string]Writer{
-		DefaultWriterName: DefaultWriter,
+	// jsonMarshalOptions marshals the responses using protojson to ensure Protobuf field names
+	// and empty values are handled according to .proto definitions.
+	jsonMarshalOptions = protojson.MarshalOptions{
+		UseProtoNames:   true, // Use snake_case from .proto files.
+		EmitUnpopulated: true, // Include fields with default values (0, "", false).
 	}
-	wm sync.RWMutex
+
+	// jsonWriter is the output of the DefaultWriter if no other media type is negotiated.
+	jsonWriter = NewMarshalerWriter(MIME_JSON, jsonMarshalOptions.Marshal)
+
+	// Registry for different output formats.
+	// The writers registered with a media type name, e.g. application/xml,
+	// are negotiated by the DefaultWriter with the Accept header.
+	writers = map[string]Writer{}
+	wm      sync.RWMutex
 )
 
+func init() {
+	AddWriter(DefaultWriterName, DefaultWriter)
+	AddWriter(MIME_JSON, jsonWriter)
+	AddWriter(MIME_PROTOBUF, NewMarshalerWriter(MIME_PROTOBUF, proto.Marshal))
+	AddWriter(MIME_XML, NewMarshalerWriter(MIME_XML, marshalXML))
+	AddWriter(MIME_MSGPACK, NewMarshalerWriter(MIME_MSGPACK, marshalMsgpack))
+	AddWriter(MIME_X_MSGPACK, NewMarshalerWriter(MIME_X_MSGPACK, marshalMsgpack))
+}
+
 // AddWriter registers a new custom output implementation (e.g., an XMLWriter).
+// A writer registered with a media type name is negotiated by the DefaultWriter.
 func AddWriter(name string, writer Writer) {
 	wm.Lock()
 	writers[name] = writer
@@ -59,65 +86,162 @@ func GetWriter(name string) Writer {
 }
 
 // DefaultWriter handles the standard output logic.
-// It wraps responses in a standardized 'status' object containing metadata,
-// errors, and the business data payload.
+// The response is written by the writer of the media type accepted by the client,
+// see negotiateWriter, or in JSON if the client accepts none of them.
 func DefaultWriter(c *Context, data any, err error) {
-	// If both data and error are nil, we assume the handler has already
-	// manually written the response (e.g., file downloads or streaming).
+	// The handler has already written the response, e.g. file downloads or streaming.
 	if err == nil && (data == nil || reflect.ValueOf(data).IsNil()) {
 		return
 	}
+	negotiateWriter(c)(c, data, err)
+}
 
-	// Convert the error into a standardized status object.
-	st := status.FromError(err)
-	var statusCode uint32 = http.StatusOK
-
-	// Check if the client requested the actual HTTP status code via query params.
-	if c.URI().QueryArgs().Has(QueryParamNeedStatusCode) {
-		statusCode = st.Status
+// negotiateWriter returns the writer of the media type preferred by the Accept header, see RFC 9110 section 12.5.1.
+// The quality of a media type is the one of the most specific media range matching it,
+// type/subtype, then type/* and */*, the media type with the highest quality is selected.
+// Equal qualities select the media types named in the Accept header in their order, then JSON.
+// JSON is returned without Accept header or if no media type with a writer is acceptable.
+func negotiateWriter(c *Context) Writer {
+	accept := c.Request.Header.Peek(fasthttp.HeaderAccept)
+	if len(accept) == 0 {
+		return jsonWriter
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(string(accept), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		mainType, subType, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mainType: mainType, subType: subType, quality: q})
 	}
 
-	// Inject tracing metadata (Request ID and Method) into the response.
-	if requestId := c.Value(HeaderResponseRequestID); requestId != nil {
-		st.RequestId = requestId.(string)
+	wm.RLock()
+	defer wm.RUnlock()
+	candidates := make([]string, 0, len(ranges)+len(writers))
+	for _, r := range ranges {
+		candidates = append(candidates, r.mainType+"/"+r.subType)
+	}
+	candidates = append(candidates, MIME_JSON)
+	others := make([]string, 0, len(writers))
+	for name := range writers {
+		if strings.Contains(name, "/") {
+			others = append(others, name)
+		}
 	}
-	if requestMethod := c.Value(HeaderResponseRequestMethod); requestMethod != nil {
-		st.RequestMethod = requestMethod.(string)
+	slices.Sort(others)
+	candidates = append(candidates, others...)
+
+	var (
+		selected = jsonWriter
+		quality  float64
+	)
+	for _, mediaType := range candidates {
+		writer, ok := writers[mediaType]
+		if !ok {
+			continue
+		}
+		if q := acceptQuality(ranges, mediaType); q > quality {
+			selected, quality = writer, q
+		}
 	}
+	return selected
+}
 
-	c.Response.Header.Set(HeaderResponseRequestID, st.RequestId)
-	c.Response.Header.Set(HeaderResponseRequestMethod, st.RequestMethod)
+// mediaRange is a media range of the Accept header.
+type mediaRange struct {
+	mainType string
+	subType  string
+	quality  float64
+}
 
-	// If successful, wrap the business data in a Protobuf 'Any' type.
-	if err == nil {
-		if d, err := anypb.New(data.(proto.Message)); err == nil {
-			st.Data = d
-		} else {
-			logger.Error("can not create anypb.Any", "data", data, "err", err)
+// acceptQuality returns the quality of the most specific media range matching mediaType, 0 if none matches.
+func acceptQuality(ranges []mediaRange, mediaType string) float64 {
+	mainType, subType, _ := strings.Cut(mediaType, "/")
+	quality, specificity := 0.0, 0
+	for _, r := range ranges {
+		var matched int
+		switch {
+		case r.mainType == mainType && r.subType == subType:
+			matched = 3
+		case r.mainType == mainType && r.subType == "*":
+			matched = 2
+		case r.mainType == "*" && r.subType == "*":
+			matched = 1
+		default:
+			continue
+		}
+		if matched > specificity {
+			quality, specificity = r.quality, matched
 		}
 	}
+	return quality
+}
+
+// NewMarshalerWriter returns a writer encoding the responses with the marshaler.
+// It wraps responses in a standardized 'status' object containing metadata,
+// errors, and the business data payload.
+func NewMarshalerWriter(contentType string, marshal Marshaler) Writer {
+	return func(c *Context, data any, err error) {
+		// If both data and error are nil, we assume the handler has already
+		// manually written the response (e.g., file downloads or streaming).
+		if err == nil && (data == nil || reflect.ValueOf(data).IsNil()) {
+			return
+		}
+
+		// Convert the error into a standardized status object.
+		st := status.FromError(err)
+		var statusCode uint32 = http.StatusOK
+
+		// Check if the client requested the actual HTTP status code via query params.
+		if c.URI().QueryArgs().Has(QueryParamNeedStatusCode) {
+			statusCode = st.Status
+		}
 
-	// Finalize by writing the status object as JSON.
-	if err := writeJSON(c, int(statusCode), st); err != nil {
-		logger.Error("write json fail", "err", err)
+		// Inject tracing metadata (Request ID and Method) into the response.
+		if requestId := c.Value(HeaderResponseRequestID); requestId != nil {
+			st.RequestId = requestId.(string)
+		}
+		if requestMethod := c.Value(HeaderResponseRequestMethod); requestMethod != nil {
+			st.RequestMethod = requestMethod.(string)
+		}
+
+		c.Response.Header.Set(HeaderResponseRequestID, st.RequestId)
+		c.Response.Header.Set(HeaderResponseRequestMethod, st.RequestMethod)
+
+		// If successful, wrap the business data in a Protobuf 'Any' type.
+		if err == nil {
+			if d, err := anypb.New(data.(proto.Message)); err == nil {
+				st.Data = d
+			} else {
+				logger.Error("can not create anypb.Any", "data", data, "err", err)
+			}
+		}
+
+		// Finalize by writing the encoded status object.
+		if err := writeMessage(c, int(statusCode), contentType, st, marshal); err != nil {
+			logger.Error("write message fail", "content_type", contentType, "err", err)
+		}
 	}
 }
 
-// writeJSON handles the physical serialization and writing of bytes to the fasthttp response.
-func writeJSON(c *Context, statusCode int, body proto.Message) error {
-	c.Response.Header.Set(fasthttp.HeaderContentType, MIME_JSON)
-	c.Response.SetStatusCode(statusCode)
-
-	// Marshal using protojson to ensure Protobuf field names and empty values
-	// are handled according to .proto definitions.
-	b, err := protojson.MarshalOptions{
-		UseProtoNames:   true, // Use snake_case from .proto files.
-		EmitUnpopulated: true, // Include fields with default values (0, "", false).
-	}.Marshal(body)
+// writeMessage handles the physical serialization and writing of bytes to the fasthttp response.
+func writeMessage(c *Context, statusCode int, contentType string, body proto.Message, marshal Marshaler) error {
+	b, err := marshal(body)
 	if err != nil {
 		return err
 	}
-
+	c.Response.Header.Set(fasthttp.HeaderContentType, contentType)
+	c.Response.SetStatusCode(statusCode)
 	if _, err := c.Write(b); err != nil {
 		return err
 	}
@@ -0,0 +1,388 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// The messages are encoded in XML following their JSON mapping:
+// the root element is named after the message, the fields are child elements
+// named like the JSON fields, the repeated fields are repeated elements,
+// the map entries are entry elements with a key attribute
+// and the @type of the google.protobuf.Any messages is the type attribute.
+//
+// No XML codec of protobuf messages is available, encoding/xml only handles structs,
+// so the messages go through their JSON mapping which keeps the well-known types
+// and the field names consistent with the JSON bodies.
+
+const (
+	xmlTypeAttr = "type"
+	// xmlEntry is the element of a map entry, its key is the xmlKeyAttr attribute.
+	xmlEntry   = "entry"
+	xmlKeyAttr = "key"
+	// xmlMaxDepth is the nesting limit of the XML bodies.
+	xmlMaxDepth = 10000
+)
+
+// marshalXML encodes the message in XML.
+func marshalXML(m proto.Message) ([]byte, error) {
+	b, err := jsonMarshalOptions.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	md := m.ProtoReflect().Descriptor()
+	if err := jsonToXML(dec, enc, xml.StartElement{Name: xml.Name{Local: string(md.Name())}}, md); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonToXML encodes the next JSON value as an element.
+// The elements of an array are encoded as elements of the same name.
+// md is the descriptor of the message value, nil if the value is not a message.
+func jsonToXML(dec *json.Decoder, enc *xml.Encoder, start xml.StartElement, md protoreflect.MessageDescriptor) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch v := token.(type) {
+	case json.Delim:
+		if v == '[' {
+			for dec.More() {
+				if err := jsonToXML(dec, enc, start, md); err != nil {
+					return err
+				}
+			}
+			_, err := dec.Token()
+			return err
+		}
+		started := false
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := keyToken.(string)
+			// The @type of the Any messages is their first key.
+			if !started && strings.HasPrefix(key, "@") {
+				var value string
+				if err := dec.Decode(&value); err != nil {
+					return err
+				}
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: key[1:]}, Value: value})
+				if mt, err := protoregistry.GlobalTypes.FindMessageByURL(value); err == nil {
+					md = mt.Descriptor()
+				} else {
+					md = nil
+				}
+				continue
+			}
+			if !started {
+				if err := enc.EncodeToken(start); err != nil {
+					return err
+				}
+				started = true
+			}
+			child := xml.StartElement{Name: xml.Name{Local: key}}
+			fd := xmlField(md, key)
+			switch {
+			case fd == nil:
+				err = jsonToXML(dec, enc, child, nil)
+			case fd.IsMap():
+				err = jsonMapToXML(dec, enc, child, fieldMessage(fd.MapValue()))
+			default:
+				err = jsonToXML(dec, enc, child, fieldMessage(fd))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		if !started {
+			if err := enc.EncodeToken(start); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	case nil:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		return enc.EncodeToken(start.End())
+	default:
+		return enc.EncodeElement(fmt.Sprint(v), start)
+	}
+}
+
+// jsonMapToXML encodes the next JSON object as the element of a map field,
+// its entries are entry elements with a key attribute
+// since the map keys are not always valid element names.
+func jsonMapToXML(dec *json.Decoder, enc *xml.Encoder, start xml.StartElement, md protoreflect.MessageDescriptor) error {
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	for dec.More() {
+		keyToken, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := keyToken.(string)
+		entry := xml.StartElement{
+			Name: xml.Name{Local: xmlEntry},
+			Attr: []xml.Attr{{Name: xml.Name{Local: xmlKeyAttr}, Value: key}},
+		}
+		if err := jsonToXML(dec, enc, entry, md); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlField returns the field of the message named like the JSON key, nil if not found.
+// The well-known types have their own JSON mapping and are encoded like plain values.
+func xmlField(md protoreflect.MessageDescriptor, key string) protoreflect.FieldDescriptor {
+	if md == nil || md.FullName().Parent() == "google.protobuf" {
+		return nil
+	}
+	if fd := md.Fields().ByJSONName(key); fd != nil {
+		return fd
+	}
+	return md.Fields().ByTextName(key)
+}
+
+// fieldMessage returns the message descriptor of the field, nil if it is not a message.
+func fieldMessage(fd protoreflect.FieldDescriptor) protoreflect.MessageDescriptor {
+	if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		return fd.Message()
+	}
+	return nil
+}
+
+// xmlNode is an element of an XML body.
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	text     string
+	children []*xmlNode
+}
+
+// unmarshalXML decodes the XML body into the message.
+func unmarshalXML(b []byte, m proto.Message) error {
+	root, err := parseXML(b)
+	if err != nil {
+		return err
+	}
+	value, err := xmlMessageToJSON(root, m.ProtoReflect().Descriptor())
+	if err != nil {
+		return err
+	}
+	jb, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return protojson.Unmarshal(jb, m)
+}
+
+// parseXML parses the root element of the XML body.
+func parseXML(b []byte) (*xmlNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	var stack []*xmlNode
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return nil, errors.New("xml root element not found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(stack) >= xmlMaxDepth {
+				return nil, errors.New("xml exceeds the nesting limit")
+			}
+			node := &xmlNode{name: t.Name.Local, attrs: t.Copy().Attr}
+			if len(stack) != 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			}
+			stack = append(stack, node)
+		case xml.CharData:
+			if len(stack) != 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		case xml.EndElement:
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return node, nil
+			}
+		}
+	}
+}
+
+// xmlMessageToJSON converts an element into the JSON value of a message.
+func xmlMessageToJSON(node *xmlNode, md protoreflect.MessageDescriptor) (any, error) {
+	switch md.FullName() {
+	case "google.protobuf.Any":
+		return xmlAnyToJSON(node)
+	case "google.protobuf.Struct", "google.protobuf.Value", "google.protobuf.ListValue":
+		return xmlValueToJSON(node), nil
+	}
+	if md.FullName().Parent() == "google.protobuf" && len(node.children) == 0 {
+		// The well-known types mapped to JSON scalars, e.g. Timestamp or wrappers.
+		if value := md.Fields().ByName("value"); value != nil && md.Fields().Len() == 1 {
+			return xmlScalarToJSON(node.text, value), nil
+		}
+		if md.Fields().Len() != 0 {
+			return strings.TrimSpace(node.text), nil
+		}
+	}
+	out := make(map[string]any)
+	fields := md.Fields()
+	for _, child := range node.children {
+		fd := fields.ByJSONName(child.name)
+		if fd == nil {
+			fd = fields.ByTextName(child.name)
+		}
+		if fd == nil {
+			continue
+		}
+		name := fd.JSONName()
+		switch {
+		case fd.IsMap():
+			entries, _ := out[name].(map[string]any)
+			if entries == nil {
+				entries = make(map[string]any)
+				out[name] = entries
+			}
+			for _, entry := range child.children {
+				value, err := xmlFieldToJSON(entry, fd.MapValue())
+				if err != nil {
+					return nil, err
+				}
+				entries[xmlEntryKey(entry)] = value
+			}
+		case fd.IsList():
+			value, err := xmlFieldToJSON(child, fd)
+			if err != nil {
+				return nil, err
+			}
+			list, _ := out[name].([]any)
+			out[name] = append(list, value)
+		default:
+			value, err := xmlFieldToJSON(child, fd)
+			if err != nil {
+				return nil, err
+			}
+			out[name] = value
+		}
+	}
+	return out, nil
+}
+
+// xmlEntryKey returns the key of a map entry element,
+// the elements named after their key are accepted too.
+func xmlEntryKey(node *xmlNode) string {
+	if node.name == xmlEntry {
+		for _, attr := range node.attrs {
+			if attr.Name.Local == xmlKeyAttr {
+				return attr.Value
+			}
+		}
+	}
+	return node.name
+}
+
+// xmlAnyToJSON converts an element into the JSON value of a google.protobuf.Any message,
+// its type attribute is the type URL of the message.
+func xmlAnyToJSON(node *xmlNode) (any, error) {
+	var typeURL string
+	for _, attr := range node.attrs {
+		if attr.Name.Local == xmlTypeAttr {
+			typeURL = attr.Value
+		}
+	}
+	if typeURL == "" {
+		return map[string]any{}, nil
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByURL(typeURL)
+	if err != nil {
+		return nil, err
+	}
+	value, err := xmlMessageToJSON(node, mt.Descriptor())
+	if err != nil {
+		return nil, err
+	}
+	out, ok := value.(map[string]any)
+	if !ok {
+		// The well-known types mapped to JSON scalars are in the value field.
+		out = map[string]any{"value": value}
+	}
+	out["@type"] = typeURL
+	return out, nil
+}
+
+// xmlValueToJSON converts an element into a JSON object, or a string if it has no child.
+func xmlValueToJSON(node *xmlNode) any {
+	if len(node.children) == 0 {
+		return node.text
+	}
+	out := make(map[string]any)
+	for _, child := range node.children {
+		out[child.name] = xmlValueToJSON(child)
+	}
+	return out
+}
+
+// xmlFieldToJSON converts an element into the JSON value of a field.
+func xmlFieldToJSON(node *xmlNode, fd protoreflect.FieldDescriptor) (any, error) {
+	if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		return xmlMessageToJSON(node, fd.Message())
+	}
+	return xmlScalarToJSON(node.text, fd), nil
+}
+
+// xmlScalarToJSON converts a text into the JSON value of a scalar field.
+// The numbers are JSON strings which are accepted by protojson.
+func xmlScalarToJSON(text string, fd protoreflect.FieldDescriptor) any {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return text
+	case protoreflect.BoolKind:
+		if b, err := strconv.ParseBool(strings.TrimSpace(text)); err == nil {
+			return b
+		}
+	case protoreflect.EnumKind:
+		if n, err := strconv.ParseInt(strings.TrimSpace(text), 10, 32); err == nil {
+			return n
+		}
+	}
+	return strings.TrimSpace(text)
+}
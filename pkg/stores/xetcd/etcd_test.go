@@ -92,3 +92,48 @@ func TestEtcdMissingTLSFiles(t *testing.T) {
 	_, err := (&ClientManager{}).newClientConfig(conf)
 	require.Error(t, err)
 }
+
+func TestLock(t *testing.T) {
+	lock, err := NewLock()
+	require.Nil(t, err)
+	locker := lock.(*Lock)
+	ctx := context.Background()
+	expiresIn := 5 * time.Second
+
+	t.Run("normal", func(t *testing.T) {
+		key := "test_lock_normal"
+		require.True(t, lock.Lock(ctx, key, "one", expiresIn))
+		require.False(t, lock.Lock(ctx, key, "one", expiresIn), "not reentrant")
+		require.False(t, lock.Lock(ctx, key, "another", expiresIn))
+		require.True(t, lock.KeepAlive(ctx, key, "one", expiresIn))
+
+		token, ok := locker.FencingToken(ctx, key, "one")
+		require.True(t, ok)
+		_, ok = locker.FencingToken(ctx, key, "another")
+		require.False(t, ok)
+
+		require.True(t, lock.Unlock(ctx, key, "one"))
+		require.False(t, lock.Unlock(ctx, key, "one"))
+		require.True(t, lock.Lock(ctx, key, "another", expiresIn))
+		next, ok := locker.FencingToken(ctx, key, "another")
+		require.True(t, ok)
+		require.Greater(t, next, token, "fencing token increases")
+		require.True(t, lock.Unlock(ctx, key, "another"))
+	})
+	t.Run("nested keys", func(t *testing.T) {
+		key := "test_lock_nested"
+		require.True(t, lock.Lock(ctx, key+"/sub", "one", expiresIn))
+		require.True(t, lock.Lock(ctx, key, "another", expiresIn), "a nested lock is not a candidate")
+		_, ok := locker.FencingToken(ctx, key, "another")
+		require.True(t, ok)
+		require.True(t, lock.Unlock(ctx, key, "another"))
+		require.True(t, lock.Unlock(ctx, key+"/sub", "one"))
+	})
+	t.Run("expired", func(t *testing.T) {
+		key := "test_lock_expired"
+		require.True(t, lock.Lock(ctx, key, "one", time.Second))
+		time.Sleep(3 * time.Second)
+		require.True(t, lock.Lock(ctx, key, "another", expiresIn))
+		require.True(t, lock.Unlock(ctx, key, "another"))
+	})
+}
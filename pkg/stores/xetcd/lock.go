@@ -0,0 +1,171 @@
+package xetcd
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/pkg/mutex"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Lock represents a distributed lock implementation using ETCD as the backend.
+//
+// Every candidate writes the key {key}/__lock/{threadId} attached to its own lease,
+// the candidate with the lowest create revision owns the lock.
+// The create revision of the owner is the fencing token of the lock,
+// it increases on every acquisition across the whole cluster.
+type Lock struct {
+	client *clientv3.Client
+
+	// leases stores the lease of every lock held by this process.
+	// key: {key}/__lock/{threadId}
+	leases map[string]clientv3.LeaseID
+	lm     sync.Mutex
+}
+
+// lockCandidates is the suffix of the lock key below which the candidates are written,
+// so the locks of the keys nested in the key, e.g. {key}/sub, are not candidates of the lock.
+const lockCandidates = "/__lock/"
+
+var (
+	// Ensure Lock satisfies the mutex.FencingLocker interface.
+	_ mutex.FencingLocker = &Lock{}
+)
+
+// NewLock initializes a new ETCD distributed lock instance.
+// It retrieves the ETCD client using the provided functional options.
+func NewLock(opts ...Option) (mutex.Locker, error) {
+	client, err := Client(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Lock{
+		client: client,
+		leases: make(map[string]clientv3.LeaseID),
+	}, nil
+}
+
+// Lock attempts to acquire the lock.
+// The candidate key is written if absent, the lock is acquired if it is the oldest key of the lock,
+// otherwise the candidate key and its lease are removed.
+func (l *Lock) Lock(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	ownerKey := l.ownerKey(key, threadId)
+	lease, err := l.client.Grant(ctx, l.ttl(expiresIn))
+	if err != nil {
+		logger.Error("etcd lock grant lease fail", "key", key, "thread_id", threadId, "err", err)
+		return false
+	}
+	resp, err := l.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(ownerKey), "=", 0)).
+		Then(clientv3.OpPut(ownerKey, threadId, clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil {
+		logger.Error("etcd lock put fail", "key", key, "thread_id", threadId, "err", err)
+		l.revoke(lease.ID)
+		return false
+	}
+	if !resp.Succeeded {
+		// The same thread already holds or waits for the lock, the lock is not reentrant.
+		l.revoke(lease.ID)
+		return false
+	}
+
+	owner, err := l.owner(ctx, key)
+	if err != nil || owner == nil || string(owner.Key) != ownerKey {
+		if err != nil {
+			logger.Error("etcd lock get owner fail", "key", key, "thread_id", threadId, "err", err)
+		}
+		// Revoking the lease removes the candidate key.
+		l.revoke(lease.ID)
+		return false
+	}
+
+	l.lm.Lock()
+	l.leases[ownerKey] = lease.ID
+	l.lm.Unlock()
+	return true
+}
+
+// Unlock releases the lock held by threadId by revoking its lease.
+func (l *Lock) Unlock(ctx context.Context, key, threadId string) bool {
+	ownerKey := l.ownerKey(key, threadId)
+	l.lm.Lock()
+	leaseID, ok := l.leases[ownerKey]
+	delete(l.leases, ownerKey)
+	l.lm.Unlock()
+	if !ok {
+		logger.Error("etcd unlock fail, lock not held", "key", key, "thread_id", threadId)
+		return false
+	}
+	if _, err := l.client.Revoke(ctx, leaseID); err != nil {
+		logger.Error("etcd unlock revoke lease fail", "key", key, "thread_id", threadId, "err", err)
+		return false
+	}
+	return true
+}
+
+// KeepAlive renews the lease of the lock held by threadId.
+// The lease TTL is fixed when the lock is acquired, expiresIn is ignored.
+func (l *Lock) KeepAlive(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	ownerKey := l.ownerKey(key, threadId)
+	l.lm.Lock()
+	leaseID, ok := l.leases[ownerKey]
+	l.lm.Unlock()
+	if !ok {
+		logger.Error("etcd lock keepalive fail, lock not held", "key", key, "thread_id", threadId)
+		return false
+	}
+	if _, err := l.client.KeepAliveOnce(ctx, leaseID); err != nil {
+		logger.Error("etcd lock keepalive fail", "key", key, "thread_id", threadId, "err", err)
+		return false
+	}
+	return true
+}
+
+// FencingToken returns the create revision of the lock held by threadId.
+func (l *Lock) FencingToken(ctx context.Context, key, threadId string) (int64, bool) {
+	owner, err := l.owner(ctx, key)
+	if err != nil {
+		logger.Error("etcd lock get fencing token fail", "key", key, "thread_id", threadId, "err", err)
+		return 0, false
+	}
+	if owner == nil || string(owner.Key) != l.ownerKey(key, threadId) {
+		return 0, false
+	}
+	return owner.CreateRevision, true
+}
+
+// owner returns the oldest candidate key of the lock.
+func (l *Lock) owner(ctx context.Context, key string) (*mvccpb.KeyValue, error) {
+	resp, err := l.client.Get(ctx, key+lockCandidates, append(clientv3.WithFirstCreate(), clientv3.WithPrefix())...)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+	return resp.Kvs[0], nil
+}
+
+// revoke removes a lease and its keys, it must not be canceled with the request.
+func (l *Lock) revoke(leaseID clientv3.LeaseID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := l.client.Revoke(ctx, leaseID); err != nil {
+		logger.Error("etcd lock revoke lease fail", "lease", leaseID, "err", err)
+	}
+}
+
+// ownerKey returns the candidate key of threadId.
+func (l *Lock) ownerKey(key, threadId string) string {
+	return key + lockCandidates + threadId
+}
+
+// ttl converts expiresIn to the lease TTL in seconds, at least one second.
+func (l *Lock) ttl(expiresIn time.Duration) int64 {
+	return int64(math.Max(1, math.Ceil(expiresIn.Seconds())))
+}
@@ -3,10 +3,12 @@ package xgorm
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
 )
 
 //gocyclo:ignore
@@ -122,3 +124,78 @@ func TestLock(t *testing.T) {
 		}
 	})
 }
+
+func TestRWLock(t *testing.T) {
+	lock, err := NewRWLock(WithConnName("lock"))
+	require.NoError(t, err)
+	db, err := DB(context.Background(), WithConnName("lock"))
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&RWLock{}))
+	ctx := context.Background()
+	expiresIn := 5 * time.Second
+
+	t.Run("shared", func(t *testing.T) {
+		key := "test_rwlock_shared"
+		reader, another, writer := uuid.NewString(), uuid.NewString(), uuid.NewString()
+		require.True(t, lock.RLock(ctx, key, reader, expiresIn))
+		require.True(t, lock.RLock(ctx, key, another, expiresIn))
+		require.True(t, lock.RKeepAlive(ctx, key, reader, expiresIn))
+		require.False(t, lock.Lock(ctx, key, writer, expiresIn))
+		require.True(t, lock.RUnlock(ctx, key, reader))
+		require.True(t, lock.RUnlock(ctx, key, another))
+		require.False(t, lock.RKeepAlive(ctx, key, reader, expiresIn))
+
+		require.True(t, lock.Lock(ctx, key, writer, expiresIn))
+		require.False(t, lock.RLock(ctx, key, reader, expiresIn))
+		require.True(t, lock.KeepAlive(ctx, key, writer, expiresIn))
+		require.True(t, lock.Unlock(ctx, key, writer))
+		require.True(t, lock.RLock(ctx, key, reader, expiresIn))
+		require.True(t, lock.RUnlock(ctx, key, reader))
+	})
+
+	t.Run("writer preference", func(t *testing.T) {
+		key := "test_rwlock_writer_preference"
+		reader, another, writer := uuid.NewString(), uuid.NewString(), uuid.NewString()
+		require.True(t, lock.RLock(ctx, key, reader, expiresIn))
+		// The waiting writer keeps new readers out, the current readers can renew.
+		require.False(t, lock.Lock(ctx, key, writer, expiresIn))
+		require.False(t, lock.RLock(ctx, key, another, expiresIn))
+		require.False(t, lock.Lock(ctx, key, another, expiresIn))
+		require.True(t, lock.RLock(ctx, key, reader, expiresIn))
+		require.True(t, lock.RUnlock(ctx, key, reader))
+		require.True(t, lock.Lock(ctx, key, writer, expiresIn))
+		require.True(t, lock.Unlock(ctx, key, writer))
+	})
+
+	t.Run("concurrent readers", func(t *testing.T) {
+		key := "test_rwlock_concurrent_readers"
+		readers := make([]string, 10)
+		var wg sync.WaitGroup
+		var locked atomic.Int64
+		for i := range readers {
+			readers[i] = uuid.NewString()
+			wg.Add(1)
+			go func(reader string) {
+				defer wg.Done()
+				if lock.RLock(ctx, key, reader, expiresIn) {
+					locked.Add(1)
+				}
+			}(readers[i])
+		}
+		wg.Wait()
+		// The readers wait for the guard instead of failing.
+		require.Equal(t, int64(len(readers)), locked.Load())
+		for _, reader := range readers {
+			require.True(t, lock.RUnlock(ctx, key, reader))
+		}
+	})
+
+	t.Run("expired reader", func(t *testing.T) {
+		key := "test_rwlock_expired_reader"
+		require.True(t, lock.RLock(ctx, key, uuid.NewString(), 100*time.Millisecond))
+		time.Sleep(200 * time.Millisecond)
+		writer := uuid.NewString()
+		require.True(t, lock.Lock(ctx, key, writer, expiresIn))
+		require.True(t, lock.Unlock(ctx, key, writer))
+	})
+}
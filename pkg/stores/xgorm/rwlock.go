@@ -0,0 +1,270 @@
+package xgorm
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/pkg/mutex"
+	"gorm.io/gorm"
+)
+
+const (
+	// RWLockModeRead marks a shared owner.
+	RWLockModeRead = "read"
+	// RWLockModeWrite marks the exclusive owner.
+	RWLockModeWrite = "write"
+	// RWLockModeWait marks a writer waiting for the readers to leave.
+	RWLockModeWait = "wait"
+	// RWLockModeGuard marks the short lived record serializing the changes of a lock.
+	RWLockModeGuard = "guard"
+
+	// rwLockGuardOwner is the owner of the guard record, unique per lock key.
+	rwLockGuardOwner = "guard"
+	// rwLockGuardExpiresIn bounds how long a crashed process can block a lock.
+	rwLockGuardExpiresIn = 5 * time.Second
+	// rwLockGuardWait bounds how long the guard held by another owner is waited for,
+	// the guard is held for a few queries only, e.g., by concurrent readers.
+	rwLockGuardWait = time.Second
+	// rwLockGuardMinBackoff and rwLockGuardMaxBackoff bound the wait between two attempts to take the guard.
+	rwLockGuardMinBackoff = 5 * time.Millisecond
+	rwLockGuardMaxBackoff = 100 * time.Millisecond
+)
+
+// RWLock represents the database schema for the distributed read-write lock.
+// Every owner of a lock is a record, the unique index on 'lock_key' and 'owner'
+// lets a guard record serialize the changes of a lock on any database.
+type RWLock struct {
+	Id int64 `gorm:"column:id;type:BIGINT(20);primaryKey;autoIncrement;comment:主键"`
+	// LockKey is the unique identifier for the resource being locked.
+	LockKey string `gorm:"column:lock_key;type:VARCHAR(255);uniqueIndex:idx_lock_key_owner;comment:锁名称"`
+	// Owner (threadId) identifies who holds or waits for the lock.
+	Owner string `gorm:"column:owner;type:VARCHAR(36);uniqueIndex:idx_lock_key_owner;comment:锁拥有者"`
+	// Mode is one of read, write, wait and guard.
+	Mode      string `gorm:"column:mode;type:VARCHAR(8);comment:锁类型"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// ExpiresAt handles deadlocks by allowing locks to be cleaned up if the owner crashes.
+	ExpiresAt time.Time
+
+	// options stores GORM connection configurations (e.g., which database instance to use).
+	options []Option `gorm:"-"`
+}
+
+// Verify that RWLock implements the mutex.RWLocker interface.
+var _ mutex.RWLocker = &RWLock{}
+
+// NewRWLock initializes a new GORM-based read-write locker.
+// Expired records are removed every time a lock changes.
+func NewRWLock(opts ...Option) (mutex.RWLocker, error) {
+	return &RWLock{
+		options: opts,
+	}, nil
+}
+
+// Lock attempts to acquire the exclusive lock.
+// If readers are present the intent of the writer is recorded to keep new readers out.
+func (l RWLock) Lock(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	return l.withGuard(ctx, key, threadId, func(db *gorm.DB, owners []RWLock) bool {
+		hasReader := false
+		for _, owner := range owners {
+			switch owner.Mode {
+			case RWLockModeWrite:
+				return false
+			case RWLockModeWait:
+				if owner.Owner != threadId {
+					return false
+				}
+			case RWLockModeRead:
+				hasReader = true
+			}
+		}
+		if hasReader {
+			l.save(db, key, threadId, RWLockModeWait, mutex.WriterIntentExpiresIn)
+			return false
+		}
+		return l.save(db, key, threadId, RWLockModeWrite, expiresIn)
+	})
+}
+
+// Unlock releases the exclusive lock held by threadId.
+func (l RWLock) Unlock(ctx context.Context, key, threadId string) bool {
+	return l.delete(ctx, key, threadId, RWLockModeWrite)
+}
+
+// KeepAlive extends the expiration time of the exclusive lock held by threadId.
+func (l RWLock) KeepAlive(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	return l.keepAlive(ctx, key, threadId, RWLockModeWrite, expiresIn)
+}
+
+// RLock attempts to acquire a shared lock if there is no writer, active or waiting.
+// An owner already holding a shared lock always gets it again.
+func (l RWLock) RLock(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	return l.withGuard(ctx, key, threadId, func(db *gorm.DB, owners []RWLock) bool {
+		holding, hasWriter := false, false
+		for _, owner := range owners {
+			switch owner.Mode {
+			case RWLockModeWrite, RWLockModeWait:
+				hasWriter = true
+			case RWLockModeRead:
+				holding = holding || owner.Owner == threadId
+			}
+		}
+		if hasWriter && !holding {
+			return false
+		}
+		return l.save(db, key, threadId, RWLockModeRead, expiresIn)
+	})
+}
+
+// RUnlock releases the shared lock held by threadId.
+func (l RWLock) RUnlock(ctx context.Context, key, threadId string) bool {
+	return l.delete(ctx, key, threadId, RWLockModeRead)
+}
+
+// RKeepAlive extends the expiration time of the shared lock held by threadId.
+func (l RWLock) RKeepAlive(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	return l.keepAlive(ctx, key, threadId, RWLockModeRead, expiresIn)
+}
+
+// withGuard runs fn with the unexpired owners of the lock,
+// while holding the guard record of the lock.
+func (l RWLock) withGuard(ctx context.Context, key, threadId string, fn func(db *gorm.DB, owners []RWLock) bool) bool {
+	db, err := DB(ctx, l.options...)
+	if err != nil {
+		logger.Error("gorm rwlock get db fail", "key", key, "thread_id", threadId, "err", err)
+		return false
+	}
+	guard, ok := l.takeGuard(ctx, db, key)
+	if !ok {
+		return false
+	}
+	defer func() {
+		// Only the record created above is released, never a guard taken over after it expired.
+		if err := db.Where("lock_key=?", key).
+			Where("owner=?", rwLockGuardOwner).
+			Where("expires_at=?", guard.ExpiresAt).
+			Delete(&RWLock{}).Error; err != nil {
+			logger.Error("gorm rwlock release guard fail", "key", key, "err", err)
+		}
+	}()
+
+	now := time.Now()
+	if err := db.Where("lock_key=?", key).
+		Where("mode<>?", RWLockModeGuard).
+		Where("expires_at<?", now).
+		Delete(&RWLock{}).Error; err != nil {
+		logger.Error("gorm rwlock clean up fail", "key", key, "err", err)
+		return false
+	}
+	var owners []RWLock
+	if err := db.Where("lock_key=?", key).
+		Where("mode<>?", RWLockModeGuard).
+		Find(&owners).Error; err != nil {
+		logger.Error("gorm rwlock get owners fail", "key", key, "err", err)
+		return false
+	}
+	return fn(db, owners)
+}
+
+// takeGuard creates the guard record of the lock, with a jittered exponential backoff
+// while another owner holds it, until rwLockGuardWait elapses or ctx is done.
+func (l RWLock) takeGuard(ctx context.Context, db *gorm.DB, key string) (*RWLock, bool) {
+	deadline := time.Now().Add(rwLockGuardWait)
+	backoff := rwLockGuardMinBackoff
+	for {
+		now := time.Now()
+		// Remove the guard of a crashed process, then take the guard.
+		if err := db.Where("lock_key=?", key).
+			Where("mode=?", RWLockModeGuard).
+			Where("expires_at<?", now).
+			Delete(&RWLock{}).Error; err != nil {
+			logger.Error("gorm rwlock clean guard fail", "key", key, "err", err)
+			return nil, false
+		}
+		// The expiration identifies the guard when it is released,
+		// truncated to the precision of every database.
+		guard := &RWLock{
+			LockKey:   key,
+			Owner:     rwLockGuardOwner,
+			Mode:      RWLockModeGuard,
+			ExpiresAt: now.Add(rwLockGuardExpiresIn).Truncate(time.Millisecond),
+		}
+		if err := db.Create(guard).Error; err == nil {
+			return guard, true
+		}
+		// Another owner is changing the lock.
+		wait := backoff/2 + rand.N(backoff/2+1)
+		if now.Add(wait).After(deadline) {
+			return nil, false
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, rwLockGuardMaxBackoff)
+	}
+}
+
+// save creates or updates the record of threadId.
+func (l RWLock) save(db *gorm.DB, key, threadId, mode string, expiresIn time.Duration) bool {
+	expiresAt := time.Now().Add(expiresIn)
+	result := db.Model(&RWLock{}).
+		Where("lock_key=?", key).
+		Where("owner=?", threadId).
+		Updates(map[string]any{"mode": mode, "expires_at": expiresAt})
+	if result.Error == nil && result.RowsAffected == 0 {
+		result = db.Create(&RWLock{
+			LockKey:   key,
+			Owner:     threadId,
+			Mode:      mode,
+			ExpiresAt: expiresAt,
+		})
+	}
+	if result.Error != nil {
+		logger.Error("gorm rwlock save fail", "key", key, "thread_id", threadId, "mode", mode, "err", result.Error)
+		return false
+	}
+	return true
+}
+
+// delete removes the record of threadId in mode.
+func (l RWLock) delete(ctx context.Context, key, threadId, mode string) bool {
+	db, err := DB(ctx, l.options...)
+	if err != nil {
+		logger.Error("gorm rwlock unlock get db fail", "key", key, "thread_id", threadId, "err", err)
+		return false
+	}
+	result := db.Where("lock_key=?", key).
+		Where("owner=?", threadId).
+		Where("mode=?", mode).
+		Delete(&RWLock{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		logger.Error("gorm rwlock unlock fail", "key", key, "thread_id", threadId, "mode", mode, "err", result.Error)
+		return false
+	}
+	return true
+}
+
+// keepAlive extends the unexpired record of threadId in mode.
+func (l RWLock) keepAlive(ctx context.Context, key, threadId, mode string, expiresIn time.Duration) bool {
+	db, err := DB(ctx, l.options...)
+	if err != nil {
+		logger.Error("gorm rwlock keepalive get db fail", "key", key, "thread_id", threadId, "err", err)
+		return false
+	}
+	now := time.Now()
+	result := db.Model(&RWLock{}).
+		Where("lock_key=?", key).
+		Where("owner=?", threadId).
+		Where("mode=?", mode).
+		Where("expires_at>?", now).
+		Update("expires_at", now.Add(expiresIn))
+	if result.Error != nil || result.RowsAffected == 0 {
+		logger.Error("gorm rwlock keepalive fail", "key", key, "thread_id", threadId, "mode", mode, "err", result.Error)
+		return false
+	}
+	return true
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
 )
 
 //gocyclo:ignore
@@ -104,3 +105,52 @@ func TestLock(t *testing.T) {
 		}
 	})
 }
+
+func TestRWLock(t *testing.T) {
+	lock, err := NewRWLock()
+	require.NoError(t, err)
+	ctx := context.Background()
+	expiresIn := 5 * time.Second
+
+	t.Run("shared", func(t *testing.T) {
+		key := "test_rwlock_shared"
+		reader, another, writer := uuid.NewString(), uuid.NewString(), uuid.NewString()
+		require.True(t, lock.RLock(ctx, key, reader, expiresIn))
+		require.True(t, lock.RLock(ctx, key, another, expiresIn))
+		require.True(t, lock.RKeepAlive(ctx, key, reader, expiresIn))
+		require.False(t, lock.Lock(ctx, key, writer, expiresIn))
+		require.True(t, lock.RUnlock(ctx, key, reader))
+		require.True(t, lock.RUnlock(ctx, key, another))
+		require.False(t, lock.RKeepAlive(ctx, key, reader, expiresIn))
+
+		require.True(t, lock.Lock(ctx, key, writer, expiresIn))
+		require.False(t, lock.RLock(ctx, key, reader, expiresIn))
+		require.True(t, lock.KeepAlive(ctx, key, writer, expiresIn))
+		require.True(t, lock.Unlock(ctx, key, writer))
+		require.True(t, lock.RLock(ctx, key, reader, expiresIn))
+		require.True(t, lock.RUnlock(ctx, key, reader))
+	})
+
+	t.Run("writer preference", func(t *testing.T) {
+		key := "test_rwlock_writer_preference"
+		reader, another, writer := uuid.NewString(), uuid.NewString(), uuid.NewString()
+		require.True(t, lock.RLock(ctx, key, reader, expiresIn))
+		// The waiting writer keeps new readers out, the current readers can renew.
+		require.False(t, lock.Lock(ctx, key, writer, expiresIn))
+		require.False(t, lock.RLock(ctx, key, another, expiresIn))
+		require.False(t, lock.Lock(ctx, key, another, expiresIn))
+		require.True(t, lock.RLock(ctx, key, reader, expiresIn))
+		require.True(t, lock.RUnlock(ctx, key, reader))
+		require.True(t, lock.Lock(ctx, key, writer, expiresIn))
+		require.True(t, lock.Unlock(ctx, key, writer))
+	})
+
+	t.Run("expired reader", func(t *testing.T) {
+		key := "test_rwlock_expired_reader"
+		require.True(t, lock.RLock(ctx, key, uuid.NewString(), 100*time.Millisecond))
+		time.Sleep(200 * time.Millisecond)
+		writer := uuid.NewString()
+		require.True(t, lock.Lock(ctx, key, writer, expiresIn))
+		require.True(t, lock.Unlock(ctx, key, writer))
+	})
+}
@@ -0,0 +1,70 @@
+package xredis
+
+import (
+	"context"
+	"time"
+
+	"github.com/asjard/asjard/pkg/quota"
+	"github.com/redis/go-redis/v9"
+)
+
+// Quota keeps the counters of the quota server interceptor in Redis.
+type Quota struct {
+	client *redis.Client
+}
+
+var (
+	// Ensure Quota satisfies the quota.Store interface.
+	_ quota.Store = &Quota{}
+
+	// quotaScript implements GCRA (generic cell rate algorithm) atomically.
+	// KEYS[1] stores the theoretical arrival time (TAT) in microseconds.
+	// ARGV[1] is the emission interval and ARGV[2] the burst tolerance, both in microseconds.
+	// The Redis clock is used so that all instances share the same time.
+	// Returns {allowed, remaining, retry_after_us}.
+	quotaScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local interval = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+local tat = tonumber(redis.call("GET", KEYS[1]))
+if not tat or tat < now then
+	tat = now
+end
+local new_tat = tat + interval
+local allow_at = new_tat - tolerance
+if allow_at > now then
+	return {0, 0, math.ceil(allow_at - now)}
+end
+redis.call("SET", KEYS[1], string.format("%d", new_tat), "PX", math.ceil((new_tat - now) / 1000))
+return {1, math.floor((now - allow_at) / interval), 0}`)
+)
+
+func init() {
+	// Register as the "redis" store of the quota interceptor.
+	quota.AddStore("redis", func(clientName string) (quota.Store, error) {
+		return NewQuota(WithClientName(clientName))
+	})
+}
+
+// NewQuota initializes a quota store with the Redis client selected by the functional options.
+func NewQuota(opts ...Option) (quota.Store, error) {
+	client, err := Client(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Quota{
+		client: client,
+	}, nil
+}
+
+// Take consumes a request from the bucket key.
+func (q Quota) Take(ctx context.Context, key string, interval time.Duration, burst int) (bool, int, time.Duration, error) {
+	values, err := quotaScript.Run(ctx, q.client, []string{key},
+		interval.Microseconds(),
+		interval.Microseconds()*int64(burst)).Int64Slice()
+	if err != nil {
+		return false, 0, 0, err
+	}
+	return values[0] == 1, int(values[1]), time.Duration(values[2]) * time.Microsecond, nil
+}
@@ -0,0 +1,37 @@
+package xredis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestQuota(t *testing.T) {
+	quota, err := NewQuota()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	key := "test_quota_" + uuid.NewString()
+	for i := 0; i < 3; i++ {
+		allowed, remaining, _, err := quota.Take(context.Background(), key, time.Second, 3)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if !allowed || remaining != 2-i {
+			t.Errorf("take %d fail, allowed: %v, remaining: %d", i, allowed, remaining)
+			t.FailNow()
+		}
+	}
+	allowed, _, retryAfter, err := quota.Take(context.Background(), key, time.Second, 3)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if allowed || retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("quota must be exceeded, allowed: %v, retry after: %s", allowed, retryAfter)
+	}
+}
@@ -0,0 +1,171 @@
+package xredis
+
+import (
+	"context"
+	"time"
+
+	"github.com/asjard/asjard/core/logger"
+	"github.com/asjard/asjard/pkg/mutex"
+	"github.com/redis/go-redis/v9"
+)
+
+// RWLock represents a distributed read-write lock implementation using Redis as the backend.
+//
+// Every lock uses three keys sharing the same hash tag:
+//   - {key}: the exclusive owner.
+//   - {key}:readers: a hash of the shared owners and their expiration time in milliseconds.
+//   - {key}:writer: the writer waiting for the readers to leave.
+type RWLock struct {
+	client *redis.Client
+}
+
+var (
+	// Ensure RWLock satisfies the mutex.RWLocker interface.
+	_ mutex.RWLocker = &RWLock{}
+
+	// rwLockScript acquires the exclusive lock if there is no other writer and no reader.
+	// If readers are present the intent of the writer is recorded to keep new readers out.
+	// KEYS: owner, readers, writer. ARGV: threadId, expiresIn(ms), writer intent expiresIn(ms).
+	rwLockScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local readers = redis.call("HGETALL", KEYS[2])
+for i = 1, #readers, 2 do
+	if tonumber(readers[i + 1]) <= now then
+		redis.call("HDEL", KEYS[2], readers[i])
+	end
+end
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+local writer = redis.call("GET", KEYS[3])
+if writer and writer ~= ARGV[1] then
+	return 0
+end
+if redis.call("HLEN", KEYS[2]) > 0 then
+	redis.call("SET", KEYS[3], ARGV[1], "PX", ARGV[3])
+	return 0
+end
+redis.call("DEL", KEYS[3])
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1`)
+
+	// rwRLockScript acquires a shared lock if there is no writer, active or waiting.
+	// An owner already holding a shared lock always gets it again.
+	// KEYS: owner, readers, writer. ARGV: threadId, expiresIn(ms).
+	rwRLockScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local expiresAt = redis.call("HGET", KEYS[2], ARGV[1])
+local holding = expiresAt and tonumber(expiresAt) > now
+if not holding and (redis.call("EXISTS", KEYS[1]) == 1 or redis.call("EXISTS", KEYS[3]) == 1) then
+	return 0
+end
+redis.call("HSET", KEYS[2], ARGV[1], now + tonumber(ARGV[2]))
+if redis.call("PTTL", KEYS[2]) < tonumber(ARGV[2]) then
+	redis.call("PEXPIRE", KEYS[2], ARGV[2])
+end
+return 1`)
+
+	// rwRKeepAliveScript extends a shared lock held by threadId.
+	// KEYS: readers. ARGV: threadId, expiresIn(ms).
+	rwRKeepAliveScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local expiresAt = redis.call("HGET", KEYS[1], ARGV[1])
+if not expiresAt or tonumber(expiresAt) <= now then
+	return 0
+end
+redis.call("HSET", KEYS[1], ARGV[1], now + tonumber(ARGV[2]))
+if redis.call("PTTL", KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 1`)
+
+	// rwKeepAliveScript extends the exclusive lock held by threadId.
+	rwKeepAliveScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+else
+	return 0
+end`)
+)
+
+// NewRWLock initializes a new Redis distributed read-write lock instance.
+// It retrieves the Redis client using the provided functional options.
+func NewRWLock(opts ...Option) (mutex.RWLocker, error) {
+	client, err := Client(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &RWLock{
+		client: client,
+	}, nil
+}
+
+// Lock attempts to acquire the exclusive lock.
+func (l RWLock) Lock(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	resp, err := rwLockScript.Run(ctx, l.client, l.keys(key), threadId,
+		expiresIn.Milliseconds(), mutex.WriterIntentExpiresIn.Milliseconds()).Int()
+	if err != nil {
+		logger.Error("redis rwlock lock fail", "key", key, "thread_id", threadId, "err", err)
+		return false
+	}
+	return resp == 1
+}
+
+// Unlock releases the exclusive lock held by threadId.
+func (l RWLock) Unlock(ctx context.Context, key, threadId string) bool {
+	resp, err := unlockScript.Run(ctx, l.client, l.keys(key)[:1], threadId).Int()
+	if err != nil || resp == 0 {
+		logger.Error("redis rwlock unlock fail", "key", key, "thread_id", threadId, "resp", resp, "err", err)
+		return false
+	}
+	return true
+}
+
+// KeepAlive extends the expiration time of the exclusive lock held by threadId.
+func (l RWLock) KeepAlive(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	resp, err := rwKeepAliveScript.Run(ctx, l.client, l.keys(key)[:1], threadId, expiresIn.Milliseconds()).Int()
+	if err != nil || resp == 0 {
+		logger.Error("redis rwlock keepalive fail", "key", key, "thread_id", threadId, "resp", resp, "err", err)
+		return false
+	}
+	return true
+}
+
+// RLock attempts to acquire a shared lock.
+func (l RWLock) RLock(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	resp, err := rwRLockScript.Run(ctx, l.client, l.keys(key), threadId, expiresIn.Milliseconds()).Int()
+	if err != nil {
+		logger.Error("redis rwlock rlock fail", "key", key, "thread_id", threadId, "err", err)
+		return false
+	}
+	return resp == 1
+}
+
+// RUnlock releases the shared lock held by threadId.
+func (l RWLock) RUnlock(ctx context.Context, key, threadId string) bool {
+	resp, err := l.client.HDel(ctx, l.keys(key)[1], threadId).Result()
+	if err != nil || resp == 0 {
+		logger.Error("redis rwlock runlock fail", "key", key, "thread_id", threadId, "resp", resp, "err", err)
+		return false
+	}
+	return true
+}
+
+// RKeepAlive extends the expiration time of the shared lock held by threadId.
+func (l RWLock) RKeepAlive(ctx context.Context, key, threadId string, expiresIn time.Duration) bool {
+	resp, err := rwRKeepAliveScript.Run(ctx, l.client, l.keys(key)[1:2], threadId, expiresIn.Milliseconds()).Int()
+	if err != nil || resp == 0 {
+		logger.Error("redis rwlock rkeepalive fail", "key", key, "thread_id", threadId, "resp", resp, "err", err)
+		return false
+	}
+	return true
+}
+
+// keys returns the owner, readers and writer keys, hash tagged to live in the same slot.
+func (l RWLock) keys(key string) []string {
+	key = "{" + key + "}"
+	return []string{key, key + ":readers", key + ":writer"}
+}